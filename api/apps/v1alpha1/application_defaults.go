@@ -119,10 +119,14 @@ func (in *EnvironmentVariable) Default() {
 func (in *Scenario) Default() {
 	if in.Name == "" {
 		switch {
-		case in.StormForge != nil:
-			in.Name = defaultScenarioName(in.StormForge.TestCase, in.StormForge.TestCaseFile)
+		case in.StormForger != nil:
+			in.Name = defaultScenarioName(in.StormForger.TestCase, in.StormForger.TestCaseFile)
 		case in.Locust != nil:
 			in.Name = defaultScenarioName(in.Locust.Locustfile)
+		case in.K6 != nil:
+			in.Name = defaultScenarioName(in.K6.Script)
+		case in.JMeter != nil:
+			in.Name = defaultScenarioName(in.JMeter.TestPlan)
 		case in.Custom != nil:
 			in.Name = defaultCustomScenarioName(in.Custom)
 		default:
@@ -51,6 +51,9 @@ type Application struct {
 
 	// StormForger allows you to configure StormForger to apply load on your application.
 	StormForger *StormForger `json:"stormForger,omitempty"`
+
+	// Build specifies how the application should be built prior to running a trial.
+	Build *Build `json:"build,omitempty"`
 }
 
 // Parameter describes the strategy for tuning the application.
@@ -106,6 +109,10 @@ type Scenario struct {
 	StormForger *StormForgerScenario `json:"stormforger,omitempty"`
 	// Locust configuration for the scenario.
 	Locust *LocustScenario `json:"locust,omitempty"`
+	// K6 configuration for the scenario.
+	K6 *K6Scenario `json:"k6,omitempty"`
+	// JMeter configuration for the scenario.
+	JMeter *JMeterScenario `json:"jmeter,omitempty"`
 	// Custom configuration for the scenario.
 	Custom *CustomScenario `json:"custom,omitempty"`
 }
@@ -132,6 +139,40 @@ type LocustScenario struct {
 	RunTime *metav1.Duration `json:"runTime,omitempty"`
 }
 
+// K6Scenario is used to generate load using k6.
+type K6Scenario struct {
+	// Path to a k6 script to run.
+	Script string `json:"script,omitempty"`
+	// Number of virtual users to run concurrently.
+	VUs *int `json:"vus,omitempty"`
+	// Stop after the specified amount of time.
+	Duration *metav1.Duration `json:"duration,omitempty"`
+	// Stages allows ramping VUs up or down over time instead of using a fixed VUs/Duration.
+	Stages []K6Stage `json:"stages,omitempty"`
+	// Thresholds are pass/fail criteria keyed by a k6 metric name, e.g. "http_req_duration".
+	Thresholds map[string][]string `json:"thresholds,omitempty"`
+}
+
+// JMeterScenario is used to generate load using Apache JMeter.
+type JMeterScenario struct {
+	// Path to a JMeter test plan (.jmx) file to run.
+	TestPlan string `json:"testPlan,omitempty"`
+	// Number of concurrent threads (virtual users).
+	Users *int `json:"users,omitempty"`
+	// Time over which the configured number of threads are started.
+	RampTime *metav1.Duration `json:"rampTime,omitempty"`
+	// Stop after the specified amount of time.
+	Duration *metav1.Duration `json:"duration,omitempty"`
+}
+
+// K6Stage describes a step in a k6 ramping VUs executor.
+type K6Stage struct {
+	// Duration of the stage.
+	Duration metav1.Duration `json:"duration"`
+	// Number of virtual users to ramp to by the end of the stage.
+	Target int `json:"target"`
+}
+
 // CustomScenario is used for advanced cases where more flexibility is required.
 type CustomScenario struct {
 	// Enables Prometheus Push Gateway support for objectives that require it.
@@ -299,6 +340,22 @@ type StormForgerAccessToken struct {
 	SecretKeyRef *corev1.SecretKeySelector `json:"secretKeyRef,omitempty"`
 }
 
+// Build describes how to produce an on-cluster build of the application for a trial.
+type Build struct {
+	// Tekton configures an on-cluster Tekton Pipeline to build and deploy the application
+	// instead of relying on the default setup tasks.
+	Tekton *Tekton `json:"tekton,omitempty"`
+}
+
+// Tekton is used to build and deploy the application using a generated Tekton Pipeline.
+type Tekton struct {
+	// The builder task used to produce the trial image, one of `buildpacks` or `kaniko`.
+	// Defaults to `buildpacks`.
+	Builder string `json:"builder,omitempty"`
+	// Path to the Dockerfile used by the `kaniko` builder, relative to the application source.
+	Dockerfile string `json:"dockerfile,omitempty"`
+}
+
 func init() {
 	SchemeBuilder.Register(&Application{})
 }
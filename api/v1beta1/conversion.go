@@ -0,0 +1,22 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+// Hub marks Experiment as a conversion hub, satisfying sigs.k8s.io/controller-runtime/pkg/conversion.Hub so
+// api/v1alpha1.Experiment's existing ConvertTo/ConvertFrom can target it through the conversion webhook
+// instead of the client side negotiated serializer hack.
+func (*Experiment) Hub() {}
@@ -145,11 +145,31 @@ const (
 	PatchJSON PatchType = "json"
 )
 
+// TemplateEngine represents the allowable engines for rendering a patch template
+type TemplateEngine string
+
+const (
+	// EngineGoTemplate renders Patch as a Go template, the default
+	EngineGoTemplate TemplateEngine = "gotemplate"
+	// EngineJsonnet renders Patch as a Jsonnet program, with the trial assignments and
+	// experiment metadata available via std.extVar("trial")
+	EngineJsonnet TemplateEngine = "jsonnet"
+	// EngineHelm renders Patch as Helm chart values against the chart referenced by Chart
+	EngineHelm TemplateEngine = "helm"
+	// EngineCue renders Patch as a CUE file, with the trial's parameter assignments bound
+	// as top-level fields before the result is exported to JSON
+	EngineCue TemplateEngine = "cue"
+)
+
 // PatchTemplate defines a target resource and a patch template to apply
 type PatchTemplate struct {
 	// The patch type, one of: strategic|merge|json, default: strategic
 	Type PatchType `json:"type,omitempty"`
-	// A Go Template that evaluates to valid patch
+	// The template engine used to render Patch, one of: gotemplate|jsonnet|helm, default: gotemplate
+	Engine TemplateEngine `json:"engine,omitempty"`
+	// The Helm chart reference to render when Engine is "helm"
+	Chart string `json:"chart,omitempty"`
+	// A Go Template (or Jsonnet program, or Helm values template, depending on Engine) that evaluates to valid patch
 	Patch string `json:"patch"`
 	// Direct reference to the object the patch should be applied to
 	TargetRef *corev1.ObjectReference `json:"targetRef,omitempty"`
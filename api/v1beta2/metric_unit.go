@@ -0,0 +1,52 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import "k8s.io/apimachinery/pkg/api/resource"
+
+// Unit identifies the canonical unit a metric's observed value is expressed in, used to
+// normalize the value before comparing it against the metric's Min/Max.
+type Unit string
+
+const (
+	// UnitSeconds indicates the metric is a duration expressed in decimal seconds.
+	UnitSeconds Unit = "seconds"
+	// UnitBytes indicates the metric is a size expressed in decimal bytes.
+	UnitBytes Unit = "bytes"
+	// UnitRatio indicates the metric is a fraction in the range [-1, 1] (e.g. an error rate).
+	// An observed value outside that range is assumed to already be expressed as a
+	// percentage and is divided by 100 before comparison.
+	UnitRatio Unit = "ratio"
+	// UnitCount indicates the metric is a dimensionless count.
+	UnitCount Unit = "count"
+	// UnitCores indicates the metric is a CPU quantity expressed in cores.
+	UnitCores Unit = "cores"
+	// UnitCustom indicates the metric does not fit one of the other well known units; no
+	// additional scaling beyond the quantity's own suffix (e.g. "500m" == 0.5) is applied.
+	UnitCustom Unit = "custom"
+)
+
+// MetricBoundsOverride allows a specific scenario to enforce a different Min/Max than the
+// Experiment-wide default configured on the Metric itself.
+type MetricBoundsOverride struct {
+	// Scenario is the name of the scenario this override applies to.
+	Scenario string `json:"scenario"`
+	// Min overrides the metric's minimum allowed value for this scenario.
+	Min *resource.Quantity `json:"min,omitempty"`
+	// Max overrides the metric's maximum allowed value for this scenario.
+	Max *resource.Quantity `json:"max,omitempty"`
+}
@@ -34,6 +34,35 @@ type Assignment struct {
 	Value intstr.IntOrString `json:"value"`
 }
 
+// ReadinessExpressionOperator is a comparison applied between the value found at a ReadinessExpression's
+// Path and its Value
+type ReadinessExpressionOperator string
+
+const (
+	// ReadinessExpressionEqual requires the evaluated path to equal Value
+	ReadinessExpressionEqual ReadinessExpressionOperator = "Equal"
+	// ReadinessExpressionNotEqual requires the evaluated path to not equal Value
+	ReadinessExpressionNotEqual ReadinessExpressionOperator = "NotEqual"
+	// ReadinessExpressionLessThan requires the evaluated path, parsed as a number, to be less than Value
+	ReadinessExpressionLessThan ReadinessExpressionOperator = "LessThan"
+	// ReadinessExpressionGreaterThan requires the evaluated path, parsed as a number, to be greater than Value
+	ReadinessExpressionGreaterThan ReadinessExpressionOperator = "GreaterThan"
+)
+
+// ReadinessExpression asserts a relationship between a JSONPath expression evaluated against the
+// readiness target's object and a literal value, for targets whose readiness can't be expressed using
+// named status conditions (e.g. an HPA's replica count stabilizing, or a custom resource's status fields)
+type ReadinessExpression struct {
+	// Path is a JSONPath expression (e.g. "{.status.readyReplicas}") evaluated against the target object;
+	// a path that does not match is treated as "not ready yet" rather than as an error
+	Path string `json:"path"`
+	// Operator is the comparison applied between the value found at Path and Value
+	// +kubebuilder:validation:Enum=Equal;NotEqual;LessThan;GreaterThan
+	Operator ReadinessExpressionOperator `json:"operator"`
+	// Value is the literal compared against the result of evaluating Path
+	Value string `json:"value"`
+}
+
 // TrialReadinessGate represents a readiness check on one or more objects that must pass after patches
 // have been applied, but before the trial run job can start
 type TrialReadinessGate struct {
@@ -47,6 +76,8 @@ type TrialReadinessGate struct {
 	Selector *metav1.LabelSelector `json:"selector,omitempty"`
 	// ConditionTypes are the status conditions that must be "True"
 	ConditionTypes []string `json:"conditionTypes,omitempty"`
+	// Expressions are additional JSONPath-based checks that must hold, evaluated after ConditionTypes
+	Expressions []ReadinessExpression `json:"expressions,omitempty"`
 	// InitialDelaySeconds is the approximate number of seconds after all of the patches have been applied to start
 	// evaluating this check
 	InitialDelaySeconds int32 `json:"initialDelaySeconds,omitempty"`
@@ -74,7 +105,10 @@ type HelmValue struct {
 type HelmValueSource struct {
 	// Selects a trial parameter assignment as a Helm value
 	ParameterRef *ParameterSelector `json:"parameterRef,omitempty"`
-	// TODO Also support the corev1.EnvVarSource selectors?
+	// Selects a key of a ConfigMap as a Helm value
+	ConfigMapKeyRef *corev1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+	// Selects a key of a Secret as a Helm value
+	SecretKeyRef *corev1.SecretKeySelector `json:"secretKeyRef,omitempty"`
 }
 
 // ParameterSelector selects a trial parameter assignment. Note that parameters values are used as is (i.e. in
@@ -89,7 +123,8 @@ type ParameterSelector struct {
 type HelmValuesFromSource struct {
 	// The ConfigMap to select from
 	ConfigMap *ConfigMapHelmValuesFromSource `json:"configMap,omitempty"`
-	// TODO Secret support?
+	// The Secret to select from
+	Secret *SecretHelmValuesFromSource `json:"secret,omitempty"`
 }
 
 // ConfigMapHelmValuesFromSource is a reference to a ConfigMap that contains "*values.yaml" keys
@@ -98,6 +133,63 @@ type ConfigMapHelmValuesFromSource struct {
 	corev1.LocalObjectReference `json:",inline"`
 }
 
+// SecretHelmValuesFromSource is a reference to a Secret that contains "*values.yaml" keys; values are
+// read from the Secret's (already base64-decoded) Data map, not StringData
+type SecretHelmValuesFromSource struct {
+	corev1.LocalObjectReference `json:",inline"`
+}
+
+// HelmChartRef identifies a chart stored in an OCI registry, as an alternative to the classic
+// "repo + chart name" addressing used with HelmRepository/HelmChart.
+type HelmChartRef struct {
+	// The OCI registry host, for example "registry.example.com"
+	Registry string `json:"registry"`
+	// The repository within the registry that the chart is published under
+	Repository string `json:"repository"`
+	// The digest of the chart to pull, pinning the install to an exact, reproducible chart version;
+	// if omitted the tag from HelmChartVersion (defaulting to "latest") is resolved instead
+	Digest string `json:"digest,omitempty"`
+}
+
+// HelmChartVerification configures provenance verification for a Helm chart prior to installation.
+type HelmChartVerification struct {
+	// PublicKeyRef selects the keyring used to validate the chart's provenance file
+	PublicKeyRef *corev1.SecretKeySelector `json:"publicKeyRef,omitempty"`
+	// Keyless indicates the chart should be verified using keyless (sigstore/cosign) verification
+	// instead of a keyring
+	Keyless bool `json:"keyless,omitempty"`
+}
+
+// BundleRelease represents a single Helm release managed as part of a SetupBundle
+type BundleRelease struct {
+	// Name uniquely identifying this release within the bundle
+	Name string `json:"name"`
+	// The Helm chart reference to release
+	Chart string `json:"chart"`
+	// The Helm chart version, empty means use the latest
+	Version string `json:"version,omitempty"`
+	// The namespace to install the release into, defaults to the trial namespace
+	Namespace string `json:"namespace,omitempty"`
+	// Names of other releases in the bundle that must be installed (and healthy) before this one
+	Needs []string `json:"needs,omitempty"`
+	// The Helm values to set for this release
+	Values []HelmValue `json:"values,omitempty"`
+}
+
+// SetupBundle is a Helmfile-style declarative description of several Helm releases that must be
+// installed together, in dependency order, to stand up an application for a trial; releases are torn
+// down in the reverse of their install order once the trial completes
+type SetupBundle struct {
+	// Releases are the Helm releases that make up this bundle
+	Releases []BundleRelease `json:"releases,omitempty"`
+	// Environments are named sets of value overrides that can be layered on top of each release's
+	// Values, selected by name using Environment
+	Environments map[string][]HelmValue `json:"environments,omitempty"`
+	// Environment selects an entry from Environments to apply on top of the bundle's releases,
+	// typically set using a trial assignment
+	Environment string `json:"environment,omitempty"`
+}
+
 // SetupTask represents the configuration necessary to apply application state to the cluster
 // prior to each trial run and remove that state after the run concludes
 type SetupTask struct {
@@ -123,8 +215,14 @@ type SetupTask struct {
 	HelmValues []HelmValue `json:"helmValues,omitempty"`
 	// The Helm values, ignored unless helmChart is also set
 	HelmValuesFrom []HelmValuesFromSource `json:"helmValuesFrom,omitempty"`
-	// The Helm repository to fetch the chart from
+	// The Helm repository to fetch the chart from, for example "https://example.com/charts" or, for
+	// a chart distributed as an OCI artifact, "oci://registry.example.com/charts"
 	HelmRepository string `json:"helmRepository,omitempty"`
+	// The OCI registry reference to fetch the chart from, as an alternative to HelmRepository/HelmChart
+	HelmChartRef *HelmChartRef `json:"helmChartRef,omitempty"`
+	// Provenance verification to perform prior to installing the chart; if set, the setup task fails
+	// rather than installing a chart whose signature cannot be validated
+	HelmChartVerification *HelmChartVerification `json:"helmChartVerification,omitempty"`
 }
 
 // PatchOperation represents a patch used to prepare the cluster for a trial run, includes the evaluated
@@ -139,6 +237,48 @@ type PatchOperation struct {
 	// The number of remaining attempts to apply the patch, will be automatically set
 	// to zero if the patch is successfully applied
 	AttemptsRemaining int `json:"attemptsRemaining,omitempty"`
+	// Rollback determines whether TargetRef's pre-patch state is restored once the trial reaches a
+	// terminal state, defaults to "Never"
+	// +kubebuilder:validation:Enum=Always;OnFailure;Never
+	Rollback PatchRollbackPolicy `json:"rollback,omitempty"`
+	// OriginalData is a JSON patch that would restore the fields this patch touched back to the values
+	// they held on TargetRef immediately before this patch was applied, captured so Rollback can be
+	// honored; empty if Rollback is "Never" or the snapshot was too large and was externalized to a
+	// ConfigMap instead (see Trial.Status.PatchSnapshots)
+	OriginalData []byte `json:"originalData,omitempty"`
+	// RolledBack is set once the original state has been restored (or a restore was attempted and
+	// permanently failed), so rollback is not repeated on subsequent reconciles
+	RolledBack bool `json:"rolledBack,omitempty"`
+	// PlacementRef, if set, is the name of an OCM Placement or PlacementRule in the trial's namespace
+	// whose decisions select which managed clusters TargetRef's patch is delivered to; when set, the
+	// patch is wrapped in a ManifestWork for each selected cluster instead of being applied directly
+	// against the local cluster
+	PlacementRef *corev1.LocalObjectReference `json:"placementRef,omitempty"`
+}
+
+// PatchRollbackPolicy determines when a patch target's pre-patch state is restored
+type PatchRollbackPolicy string
+
+const (
+	// RollbackAlways restores the patch target's original state whenever the trial finishes, regardless of outcome
+	RollbackAlways PatchRollbackPolicy = "Always"
+	// RollbackOnFailure restores the patch target's original state only if the trial fails
+	RollbackOnFailure PatchRollbackPolicy = "OnFailure"
+	// RollbackNever never restores the patch target's original state (the default)
+	RollbackNever PatchRollbackPolicy = "Never"
+)
+
+// PatchSnapshot references a ConfigMap holding the pre-patch snapshot for a patch operation whose
+// OriginalData was too large to store inline on the Trial object
+type PatchSnapshot struct {
+	// PatchIndex is the index into Trial.Status.PatchOperations of the patch operation this snapshot
+	// was captured for; needed because multiple patch operations may share the same TargetRef, and
+	// each captures only the paths it touches
+	PatchIndex int `json:"patchIndex"`
+	// TargetRef identifies the patch operation (by its target) this snapshot was captured for
+	TargetRef corev1.ObjectReference `json:"targetRef"`
+	// ConfigMapRef is the ConfigMap holding the snapshot data under the "originalData" key
+	ConfigMapRef corev1.LocalObjectReference `json:"configMapRef"`
 }
 
 // ReadinessCheck represents a check to determine when the patched application is "ready" and it is
@@ -152,6 +292,8 @@ type ReadinessCheck struct {
 	// ConditionTypes are the status conditions that must be "True"; in addition to conditions that appear in the
 	// status of the target object, additional special conditions starting with "stormforge.io/" can be tested
 	ConditionTypes []string `json:"conditionTypes,omitempty"`
+	// Expressions are additional JSONPath-based checks that must hold, evaluated after ConditionTypes
+	Expressions []ReadinessExpression `json:"expressions,omitempty"`
 	// InitialDelaySeconds is the approximate number of seconds after all of the patches have been applied to start
 	// evaluating this check
 	InitialDelaySeconds int32 `json:"initialDelaySeconds,omitempty"`
@@ -196,6 +338,26 @@ const (
 	TrialReady TrialConditionType = "stormforge.io/trial-ready"
 	// TrialObserved is a condition that indicates a trial has had metrics collected
 	TrialObserved TrialConditionType = "stormforge.io/trial-observed"
+	// TrialAssignmentsApplied is a condition that indicates the trial's assignments were found to still be in
+	// effect on the live patch targets the last time drift detection ran
+	TrialAssignmentsApplied TrialConditionType = "stormforge.io/trial-assignments-applied"
+	// TrialRolledBack is a condition that indicates patch targets with a Rollback policy have had their
+	// pre-patch state restored after the trial reached a terminal state
+	TrialRolledBack TrialConditionType = "stormforge.io/trial-rolled-back"
+)
+
+// TrialDriftPolicy determines how a trial reacts to detecting that a patch target no longer reflects the
+// assignments that were applied to it
+type TrialDriftPolicy string
+
+const (
+	// DriftPolicyFail fails the trial as soon as drift is detected (the default)
+	DriftPolicyFail TrialDriftPolicy = "fail"
+	// DriftPolicyWarn records the drift on the trial's assignments applied condition and annotations, but
+	// otherwise allows the trial to continue
+	DriftPolicyWarn TrialDriftPolicy = "warn"
+	// DriftPolicyIgnore disables drift detection entirely
+	DriftPolicyIgnore TrialDriftPolicy = "ignore"
 )
 
 // TrialCondition represents an observed condition of a trial
@@ -243,6 +405,10 @@ type TrialSpec struct {
 
 	// Setup tasks that must run before the trial starts (and possibly after it ends)
 	SetupTasks []SetupTask `json:"setupTasks,omitempty"`
+	// SetupBundle declares a set of Helm releases, installed in dependency order, that must run before
+	// the trial starts (and are uninstalled in reverse order after it ends); unlike SetupTasks, this
+	// supports releases that depend on one another
+	SetupBundle *SetupBundle `json:"setupBundle,omitempty"`
 	// Volumes to make available to setup tasks, typically ConfigMap backed volumes
 	SetupVolumes []corev1.Volume `json:"setupVolumes,omitempty"`
 	// Service account name for running setup tasks, needs enough permissions to add and remove software
@@ -251,6 +417,35 @@ type TrialSpec struct {
 	SetupDefaultClusterRole string `json:"setupDefaultClusterRole,omitempty"`
 	// Policy rules to be assigned to the setup service account when creating namespaces
 	SetupDefaultRules []rbacv1.PolicyRule `json:"setupDefaultRules,omitempty"`
+	// PodSecurityProfile overrides the Pod Security Admission profile used to harden the trial run and
+	// setup Jobs, defaults to the profile enforced on the namespace (if any) or "baseline"
+	// +kubebuilder:validation:Enum=privileged;baseline;restricted
+	PodSecurityProfile string `json:"podSecurityProfile,omitempty"`
+	// DriftPolicy determines what happens when a patch target no longer reflects the assigned value after
+	// the trial has become ready, defaults to "fail"
+	// +kubebuilder:validation:Enum=fail;warn;ignore
+	DriftPolicy TrialDriftPolicy `json:"driftPolicy,omitempty"`
+	// DriftCheckInterval causes drift to be rechecked on this interval for as long as the trial run is in
+	// progress, in addition to the checks always performed when the application becomes ready and again
+	// before the trial is allowed to complete; if unset only those two checks are performed
+	DriftCheckInterval *metav1.Duration `json:"driftCheckInterval,omitempty"`
+	// DriftAutoRemediate causes detected drift to be corrected by re-applying the trial's patches instead of
+	// being subject to DriftPolicy
+	DriftAutoRemediate bool `json:"driftAutoRemediate,omitempty"`
+	// RollbackTimeoutSeconds bounds how long rollback of patch targets with a Rollback policy is retried
+	// for once the trial reaches a terminal state before giving up and leaving the remaining targets
+	// patched, defaults to a built-in value
+	RollbackTimeoutSeconds *int32 `json:"rollbackTimeoutSeconds,omitempty"`
+}
+
+// BundleReleaseStatus reports the observed install state of a single release in a SetupBundle
+type BundleReleaseStatus struct {
+	// Name of the bundle release this status applies to
+	Name string `json:"name"`
+	// Phase is a brief human readable description of the release status, e.g. "Pending", "Installed", "Failed"
+	Phase string `json:"phase"`
+	// Message describes the reason for the current phase, typically populated on failure
+	Message string `json:"message,omitempty"`
 }
 
 // TrialStatus defines the observed state of Trial
@@ -271,6 +466,23 @@ type TrialStatus struct {
 	PatchOperations []PatchOperation `json:"patchOperations,omitempty"`
 	// ReadinessChecks are the all of the objects whose conditions need to be inspected for this trial
 	ReadinessChecks []ReadinessCheck `json:"readinessChecks,omitempty"`
+	// BundleReleases reports the per-release install state of the trial's SetupBundle, if any
+	BundleReleases []BundleReleaseStatus `json:"bundleReleases,omitempty"`
+	// PatchSnapshots references the ConfigMaps holding the pre-patch state for patch operations whose
+	// OriginalData was too large to store inline
+	PatchSnapshots []PatchSnapshot `json:"patchSnapshots,omitempty"`
+	// PatchPreviews holds the server-returned result of a dry run patch, recorded in place of actually
+	// applying the patch when the trial has the AnnotationDryRun annotation set
+	PatchPreviews []PatchPreview `json:"patchPreviews,omitempty"`
+}
+
+// PatchPreview is the server-side dry run result of a single patch operation
+type PatchPreview struct {
+	// TargetRef is the object the dry run patch was submitted for
+	TargetRef corev1.ObjectReference `json:"targetRef"`
+	// Rendered is the full object the API server returned for the dry run patch, reflecting what the
+	// object would look like if the patch were actually applied
+	Rendered []byte `json:"rendered"`
 }
 
 // +genclient
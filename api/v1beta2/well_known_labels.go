@@ -38,9 +38,22 @@ const (
 	// AnnotationInitializer is a comma-delimited list of initializing processes. Similar to a "finalizer", the trial
 	// will not start executing until the initializer is empty.
 	AnnotationInitializer = "stormforge.io/initializer"
+	// AnnotationDrift records a structured diff of the parameters whose patch targets no longer reflect the
+	// trial's assignments, as detected by the drift detector
+	AnnotationDrift = "stormforge.io/drift"
+	// AnnotationAlert records the Alertmanager annotations of the alert that caused a "prometheus-alert"
+	// metric to fail a trial early
+	AnnotationAlert = "stormforge.io/alert"
+	// AnnotationDryRun, when set to "true", causes the patch reconciler to submit every patch operation as
+	// a server-side dry run and record the result under Status.PatchPreviews instead of actually applying it
+	// or transitioning the trial to TrialPatched=True
+	AnnotationDryRun = "stormforge.io/dry-run"
 
 	// LabelTrial contains the name of the trial associated with an object
 	LabelTrial = "stormforge.io/trial"
 	// LabelTrialRole contains the role in trial execution
 	LabelTrialRole = "stormforge.io/trial-role"
+	// LabelScenario contains the name of the scenario associated with a trial, used to
+	// select a metric's per-scenario bounds override, if any
+	LabelScenario = "stormforge.io/scenario"
 )
@@ -30,6 +30,7 @@ import (
 	"github.com/thestormforge/optimize-controller/v2/cli/internal/commands/completion"
 	"github.com/thestormforge/optimize-controller/v2/cli/internal/commands/configure"
 	"github.com/thestormforge/optimize-controller/v2/cli/internal/commands/debug"
+	"github.com/thestormforge/optimize-controller/v2/cli/internal/commands/diff"
 	"github.com/thestormforge/optimize-controller/v2/cli/internal/commands/docs"
 	"github.com/thestormforge/optimize-controller/v2/cli/internal/commands/experiments"
 	"github.com/thestormforge/optimize-controller/v2/cli/internal/commands/export"
@@ -37,8 +38,11 @@ import (
 	"github.com/thestormforge/optimize-controller/v2/cli/internal/commands/generate"
 	"github.com/thestormforge/optimize-controller/v2/cli/internal/commands/grant_permissions"
 	"github.com/thestormforge/optimize-controller/v2/cli/internal/commands/initialize"
+	"github.com/thestormforge/optimize-controller/v2/cli/internal/commands/kustomize"
 	"github.com/thestormforge/optimize-controller/v2/cli/internal/commands/login"
 	"github.com/thestormforge/optimize-controller/v2/cli/internal/commands/ping"
+	"github.com/thestormforge/optimize-controller/v2/cli/internal/commands/push"
+	"github.com/thestormforge/optimize-controller/v2/cli/internal/commands/render"
 	"github.com/thestormforge/optimize-controller/v2/cli/internal/commands/reset"
 	"github.com/thestormforge/optimize-controller/v2/cli/internal/commands/revoke"
 	"github.com/thestormforge/optimize-controller/v2/cli/internal/commands/run"
@@ -73,8 +77,13 @@ func NewRootCommand() *cobra.Command {
 	rootCmd.AddCommand(authorize_cluster.NewCommand(&authorize_cluster.Options{GeneratorOptions: authorize_cluster.GeneratorOptions{Config: cfg}}))
 	rootCmd.AddCommand(generate.NewCommand(&generate.Options{Config: cfg}))
 	rootCmd.AddCommand(fix.NewCommand(&fix.Options{}))
+	rootCmd.AddCommand(kustomize.NewCommand())
+	rootCmd.AddCommand(push.NewPushCommand(&push.PushOptions{}))
+	rootCmd.AddCommand(push.NewPullCommand(&push.PullOptions{}))
 	rootCmd.AddCommand(export.NewCommand(&export.Options{Config: cfg}))
 	rootCmd.AddCommand(run.NewCommand(&run.Options{Config: cfg}))
+	rootCmd.AddCommand(diff.NewCommand(&diff.Options{Config: cfg}))
+	rootCmd.AddCommand(render.NewCommand(&render.Options{Config: cfg}))
 
 	// Remote Server Commands
 	rootCmd.AddCommand(experiments.NewDeleteCommand(&experiments.DeleteOptions{Options: experiments.Options{Config: cfg}}))
@@ -94,7 +103,6 @@ func NewRootCommand() *cobra.Command {
 	rootCmd.AddCommand(debug.NewCommand(&debug.Options{Config: cfg}))
 
 	// TODO Add 'backup' and 'restore' maintenance commands ('maint' subcommands?)
-	// TODO We need helpers for doing a "dry run" on patches to make configuration easier
 	// TODO Add a "trial cleanup" command to run setup tasks (perhaps remove labels from standard setupJob)
 	// TODO The "get" functionality needs to support templating so you can extract assignments for downstream use
 
@@ -0,0 +1,264 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package diff implements the "diff" command, which previews the patches a trial would apply without
+// actually applying them.
+package diff
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/thestormforge/optimize-controller/v2/cli/internal/commander"
+	optimizediff "github.com/thestormforge/optimize-controller/v2/internal/diff"
+	"github.com/thestormforge/optimize-go/pkg/config"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/yaml"
+)
+
+// trialGVK is the CRD Trial.Status.PatchPreviews is recorded on; there is no generated client for it (see
+// api/v1beta2), so it is read directly through a dynamic client like every other foreign/CRD type this
+// repository touches.
+var trialGVK = schema.GroupVersionKind{Group: "stormforge.io", Version: "v1beta2", Kind: "Trial"}
+
+// Options are the configuration for previewing a trial's dry run patches
+type Options struct {
+	// Config is the Optimize Configuration
+	Config *config.OptimizeConfig
+	// IOStreams are used to access the standard process streams
+	commander.IOStreams
+
+	// Namespace of the trial to diff, defaults to the currently configured cluster namespace
+	Namespace string
+	// Name of the trial to diff
+	Name string
+}
+
+// NewCommand creates a new command for previewing a trial's dry run patches
+func NewCommand(o *Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diff NAME",
+		Short: "Preview a trial's patches",
+		Long: "Print a unified diff between the live state of a trial's patch targets and the server-side dry run\n" +
+			"result recorded under status.patchPreviews.\n\n" +
+			"The named trial must have the `stormforge.io/dry-run` annotation set, otherwise the patch reconciler\n" +
+			"applies its patches directly instead of recording previews.",
+
+		Args: cobra.ExactArgs(1),
+
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			commander.SetStreams(&o.IOStreams, cmd)
+			o.Name = args[0]
+			return nil
+		},
+		RunE: commander.WithContextE(o.diff),
+	}
+
+	cmd.Flags().StringVarP(&o.Namespace, "namespace", "n", "", "trial `namespace`")
+
+	return cmd
+}
+
+func (o *Options) diff(ctx context.Context) error {
+	cfg, err := restConfig(o.Config)
+	if err != nil {
+		return err
+	}
+
+	namespace, err := o.namespace()
+	if err != nil {
+		return err
+	}
+
+	c, err := newClient(cfg)
+	if err != nil {
+		return err
+	}
+
+	trial := &unstructured.Unstructured{}
+	trial.SetGroupVersionKind(trialGVK)
+	if err := c.get(ctx, namespace, o.Name, trial); err != nil {
+		return err
+	}
+
+	previews, found, err := unstructured.NestedSlice(trial.Object, "status", "patchPreviews")
+	if err != nil {
+		return err
+	}
+	if !found || len(previews) == 0 {
+		_, _ = fmt.Fprintf(o.Out, "no patch previews recorded for trial %q\n", o.Name)
+		return nil
+	}
+
+	for _, v := range previews {
+		preview, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		d, err := o.diffPreview(ctx, c, preview)
+		if err != nil {
+			return err
+		}
+		if d != "" {
+			_, _ = fmt.Fprint(o.Out, d)
+		}
+	}
+
+	return nil
+}
+
+// diffPreview renders the unified diff for a single status.patchPreviews entry.
+func (o *Options) diffPreview(ctx context.Context, c *client, preview map[string]interface{}) (string, error) {
+	targetRef, _, err := unstructured.NestedMap(preview, "targetRef")
+	if err != nil {
+		return "", err
+	}
+	renderedJSON, _, err := unstructured.NestedString(preview, "rendered")
+	if err != nil {
+		return "", err
+	}
+
+	target := &unstructured.Unstructured{}
+	if err := unstructured.SetNestedMap(target.Object, targetRef, "metadata"); err != nil {
+		return "", err
+	}
+	target.SetAPIVersion(asString(targetRef["apiVersion"]))
+	target.SetKind(asString(targetRef["kind"]))
+	target.SetName(asString(targetRef["name"]))
+	target.SetNamespace(asString(targetRef["namespace"]))
+
+	live := &unstructured.Unstructured{}
+	live.SetGroupVersionKind(target.GroupVersionKind())
+	if err := c.get(ctx, target.GetNamespace(), target.GetName(), live); err != nil {
+		return "", err
+	}
+
+	rendered := &unstructured.Unstructured{}
+	if err := rendered.UnmarshalJSON([]byte(renderedJSON)); err != nil {
+		return "", err
+	}
+
+	liveYAML, err := yaml.Marshal(live.Object)
+	if err != nil {
+		return "", err
+	}
+	renderedYAML, err := yaml.Marshal(rendered.Object)
+	if err != nil {
+		return "", err
+	}
+
+	label := fmt.Sprintf("%s/%s", target.GetKind(), target.GetName())
+	return optimizediff.Unified(label, label, string(liveYAML), string(renderedYAML)), nil
+}
+
+// namespace resolves the namespace to look up the trial in, defaulting to the currently configured cluster.
+func (o *Options) namespace() (string, error) {
+	if o.Namespace != "" {
+		return o.Namespace, nil
+	}
+
+	cstr, err := config.CurrentCluster(o.Config.Reader())
+	if err != nil {
+		return "", err
+	}
+	return cstr.Namespace, nil
+}
+
+// restConfig resolves a REST configuration for the cluster currently configured for Optimize, mirroring the
+// kubeconfig/context/namespace resolution used by the "run" command's own restConfig helper.
+func restConfig(cfg *config.OptimizeConfig) (*rest.Config, error) {
+	cstr, err := config.CurrentCluster(cfg.Reader())
+	if err != nil {
+		return nil, err
+	}
+
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if cstr.KubeConfig != "" {
+		loadingRules.ExplicitPath = cstr.KubeConfig
+	}
+
+	overrides := &clientcmd.ConfigOverrides{}
+	if cstr.Context != "" {
+		overrides.CurrentContext = cstr.Context
+	}
+	if cstr.Namespace != "" {
+		overrides.Context.Namespace = cstr.Namespace
+	}
+
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides).ClientConfig()
+}
+
+func asString(v interface{}) string {
+	s, _ := v.(string)
+	return s
+}
+
+// client fetches arbitrary objects by GroupVersionKind, resolving the REST resource via discovery the same
+// way pkg/kubeclient.Client does for manifest-sourced objects.
+type client struct {
+	dynamic dynamic.Interface
+	mapper  meta.RESTMapper
+}
+
+func newClient(cfg *rest.Config) (*client, error) {
+	dc, err := discovery.NewDiscoveryClientForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	dyn, err := dynamic.NewForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &client{
+		dynamic: dyn,
+		mapper:  restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(dc)),
+	}, nil
+}
+
+// get fetches the object identified by name/namespace and obj's GroupVersionKind into obj.
+func (c *client) get(ctx context.Context, namespace, name string, obj *unstructured.Unstructured) error {
+	gvk := obj.GroupVersionKind()
+	mapping, err := c.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return err
+	}
+
+	var ri dynamic.ResourceInterface = c.dynamic.Resource(mapping.Resource)
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		ri = c.dynamic.Resource(mapping.Resource).Namespace(namespace)
+	}
+
+	u, err := ri.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	obj.Object = u.Object
+	return nil
+}
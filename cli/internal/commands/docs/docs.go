@@ -19,10 +19,12 @@ package docs
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/cobra/doc"
 	"github.com/thestormforge/optimize-controller/v2/cli/internal/commander"
+	apidocs "github.com/thestormforge/optimize-controller/v2/internal/docs"
 )
 
 // TODO Add support for fetching StormForge Optimize API OpenAPI specification
@@ -50,7 +52,7 @@ func NewCommand(o *Options) *cobra.Command {
 
 	cmd.Flags().StringVarP(&o.Directory, "directory", "d", "./", "directory where documentation is written")
 	cmd.Flags().StringVar(&o.DocType, "doc-type", "markdown", "documentation type to write")
-	cmd.Flags().StringVar(&o.SourcePath, "source", "", "source path used to find API types")
+	cmd.Flags().StringVar(&o.SourcePath, "source", "api/v1beta2", "source path used to find API types")
 
 	_ = cmd.MarkFlagDirname("directory")
 	_ = cmd.MarkFlagDirname("source")
@@ -79,6 +81,17 @@ func (o *Options) docs(cmd *cobra.Command) error {
 			return err
 		}
 
+	case "api":
+		f, err := os.Create(filepath.Join(o.Directory, "api.md"))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		if err := apidocs.GenMarkdown(o.SourcePath, f); err != nil {
+			return err
+		}
+
 	default:
 		return fmt.Errorf("unknown documentation type: %s", o.DocType)
 	}
@@ -74,6 +74,7 @@ type Options struct {
 	recommendationName string
 	patchOnly          bool
 	patchedTarget      bool
+	clusters           []string
 
 	// This is used for testing
 	Fs          filesys.FileSystem
@@ -96,6 +97,36 @@ type recommendationDetails struct {
 	Recommendation *applicationsv2.Recommendation
 }
 
+// clusterTarget identifies a single destination cluster for a multi-cluster export. An
+// empty Name indicates the (single) default cluster configured for the CLI.
+type clusterTarget struct {
+	Name    string
+	Context string
+}
+
+// parseClusterTargets splits the `--cluster name=kubeconfig-context` flag values into
+// cluster targets. When no clusters are given, a single unnamed target representing the
+// currently configured cluster is returned so the rest of the export pipeline can always
+// iterate over at least one target.
+func parseClusterTargets(clusters []string) ([]clusterTarget, error) {
+	if len(clusters) == 0 {
+		return []clusterTarget{{}}, nil
+	}
+
+	targets := make([]clusterTarget, 0, len(clusters))
+	for _, c := range clusters {
+		name, kubeContext := c, ""
+		if i := strings.Index(c, "="); i >= 0 {
+			name, kubeContext = c[:i], c[i+1:]
+		}
+		if name == "" || kubeContext == "" {
+			return nil, fmt.Errorf("invalid cluster %q, expected name=kubeconfig-context", c)
+		}
+		targets = append(targets, clusterTarget{Name: name, Context: kubeContext})
+	}
+	return targets, nil
+}
+
 // NewCommand creates a command for performing an export
 func NewCommand(o *Options) *cobra.Command {
 	cmd := &cobra.Command{
@@ -136,6 +167,7 @@ func NewCommand(o *Options) *cobra.Command {
 	cmd.Flags().StringSliceVarP(&o.inputFiles, "filename", "f", nil, "experiment and related manifest `files` to export, - for stdin")
 	cmd.Flags().BoolVarP(&o.patchOnly, "patch", "p", false, "export only the patch")
 	cmd.Flags().BoolVarP(&o.patchedTarget, "patched-target", "t", false, "export only the patched resource")
+	cmd.Flags().StringSliceVar(&o.clusters, "cluster", nil, "export for additional `name=kubeconfig-context` clusters (repeatable)")
 
 	_ = cmd.MarkFlagFilename("filename", "yml", "yaml")
 
@@ -331,65 +363,97 @@ func (o *Options) runner(ctx context.Context) error {
 		}
 	}
 
-	var patches []types.Patch
+	clusters, err := parseClusterTargets(o.clusters)
+	if err != nil {
+		return err
+	}
+
+	var trial *optimizev1beta2.Trial
 	if trialDetails != nil {
 		if o.experiment == nil {
 			return fmt.Errorf("unable to find an experiment %q", trialDetails.Experiment)
 		}
 
-		trial := &optimizev1beta2.Trial{}
+		trial = &optimizev1beta2.Trial{}
 		experiment.PopulateTrialFromTemplate(o.experiment, trial)
 		server.ToClusterTrial(trial, trialDetails.Assignments)
+	}
 
-		// render patches
-		if pp, err := createTrialKustomizePatches(o.experiment.Spec.Patches, trial); err != nil {
+	resourceNames := make([]string, 0, len(o.resources))
+	for name := range o.resources {
+		resourceNames = append(resourceNames, name)
+	}
+
+	for i := range clusters {
+		patches, err := o.clusterPatches(ctx, clusters[i], trial, recDetails)
+		if err != nil {
+			if clusters[i].Name != "" {
+				err = fmt.Errorf("cluster %q: %w", clusters[i].Name, err)
+			}
 			return err
-		} else {
-			patches = append(patches, pp...)
 		}
-	}
-	if recDetails != nil {
-		// render patches
-		mapper := o.mapper(ctx)
-		if pp, err := createRecommendationKustomizePatches(mapper, recDetails.Recommendation.Parameters); err != nil {
+
+		if len(clusters) > 1 {
+			fmt.Fprintf(o.Out, "# cluster: %s\n", clusters[i].Name)
+		}
+
+		if o.patchOnly {
+			for _, p := range patches {
+				fmt.Fprintln(o.Out, p.Patch)
+			}
+			continue
+		}
+
+		yamls, err := kustomize.Yamls(
+			kustomize.WithFS(o.Fs),
+			kustomize.WithResourceNames(resourceNames),
+			kustomize.WithPatches(patches),
+		)
+		if err != nil {
 			return err
-		} else {
-			patches = append(patches, pp...)
 		}
-	}
 
-	if o.patchOnly {
-		for _, p := range patches {
-			fmt.Fprintln(o.Out, p.Patch)
+		if !o.patchedTarget {
+			fmt.Fprintln(o.Out, string(yamls))
+			continue
 		}
 
-		return nil
+		if err := (kio.Pipeline{
+			Inputs:  []kio.Reader{&kio.ByteReader{Reader: bytes.NewReader(yamls)}},
+			Filters: []kio.Filter{filterPatch(patches)},
+			Outputs: []kio.Writer{o.YAMLWriter()},
+		}).Execute(); err != nil {
+			return err
+		}
 	}
 
-	resourceNames := make([]string, 0, len(o.resources))
-	for name := range o.resources {
-		resourceNames = append(resourceNames, name)
-	}
+	return nil
+}
 
-	yamls, err := kustomize.Yamls(
-		kustomize.WithFS(o.Fs),
-		kustomize.WithResourceNames(resourceNames),
-		kustomize.WithPatches(patches),
-	)
-	if err != nil {
-		return err
+// clusterPatches renders the trial and/or recommendation patches for a single cluster
+// target, re-resolving GVKs against that cluster's REST mapper since CRDs (and therefore
+// kind/resource mappings) can differ between clusters.
+func (o *Options) clusterPatches(ctx context.Context, cluster clusterTarget, trial *optimizev1beta2.Trial, recDetails *recommendationDetails) ([]types.Patch, error) {
+	var patches []types.Patch
+
+	if trial != nil {
+		pp, err := createTrialKustomizePatches(o.experiment.Spec.Patches, trial)
+		if err != nil {
+			return nil, err
+		}
+		patches = append(patches, pp...)
 	}
 
-	if !o.patchedTarget {
-		fmt.Fprintln(o.Out, string(yamls))
-		return nil
+	if recDetails != nil {
+		mapper := o.mapper(ctx, cluster.Context)
+		pp, err := createRecommendationKustomizePatches(mapper, recDetails.Recommendation.Parameters)
+		if err != nil {
+			return nil, err
+		}
+		patches = append(patches, pp...)
 	}
 
-	return kio.Pipeline{
-		Inputs:  []kio.Reader{&kio.ByteReader{Reader: bytes.NewReader(yamls)}},
-		Filters: []kio.Filter{filterPatch(patches)},
-		Outputs: []kio.Writer{o.YAMLWriter()},
-	}.Execute()
+	return patches, nil
 }
 
 func (o *Options) generateExperiment(trial *trialDetails) error {
@@ -635,15 +699,22 @@ func (o *Options) getRecommendationDetails(ctx context.Context) (*recommendation
 
 // mapper returns REST mapper using the scheme baked in to the code plus any CRDs
 // installed on the server. This code is borrowed from the RBAC generator which
-// also needs to map between GVRs and GVKs.
-func (o *Options) mapper(ctx context.Context) meta.RESTMapper {
+// also needs to map between GVRs and GVKs. When kubeContext is non-empty, the
+// lookup is performed against that kubeconfig context instead of the currently
+// configured cluster (used for multi-cluster export).
+func (o *Options) mapper(ctx context.Context, kubeContext string) meta.RESTMapper {
 	rm := meta.NewDefaultRESTMapper(sfio.Scheme.PrioritizedVersionsAllGroups())
 	for gvk := range sfio.Scheme.AllKnownTypes() {
 		rm.Add(gvk, meta.RESTScopeRoot)
 	}
 
-	cmd, err := o.Config.Kubectl(ctx, "get", "crds", "--output", "jsonpath", "--template",
-		`{range .items[*].spec}{.group}/{.version} {.names.kind} {.names.plural} {.names.singular}{"\n"}{end}`)
+	args := []string{"get", "crds", "--output", "jsonpath", "--template",
+		`{range .items[*].spec}{.group}/{.version} {.names.kind} {.names.plural} {.names.singular}{"\n"}{end}`}
+	if kubeContext != "" {
+		args = append(args, "--context", kubeContext)
+	}
+
+	cmd, err := o.Config.Kubectl(ctx, args...)
 	if err != nil {
 		return rm
 	}
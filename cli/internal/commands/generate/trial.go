@@ -151,11 +151,11 @@ func newJob(t *optimizev1beta2.Trial, mode string, trialNumber int) (*batchv1.Jo
 
 	// If the mode is "trial" generate the actual trial job instead of a setup job
 	if strings.EqualFold(mode, "trial") {
-		return trial.NewJob(t), nil
+		return trial.NewJob(t, nil)
 	}
 
 	// Create the setup job
-	job, err := setup.NewJob(t, mode)
+	job, err := setup.NewJob(t, mode, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,180 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kustomize
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/thestormforge/optimize-controller/v2/cli/internal/commander"
+	"github.com/thestormforge/optimize-controller/v2/cli/internal/commands/kustomize/consts"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// ConfigOptions are the options for configuring a Kustomization
+type ConfigOptions struct {
+	// IOStreams are used to access the standard process streams
+	commander.IOStreams
+
+	Kustomize string
+	Filename  string
+	List      string
+}
+
+// NewConfigCommand creates a new command for configuring a Kustomization
+func NewConfigCommand(o *ConfigOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Configure Kustomize transformers",
+		Long:  "Configure Kustomize transformers for StormForge types",
+
+		PreRun: commander.StreamsPreRun(&o.IOStreams),
+		RunE:   commander.WithoutArgsE(o.config),
+	}
+
+	cmd.Flags().StringVarP(&o.Kustomize, "kustomize", "k", o.Kustomize, "Kustomize `root` to update")
+	cmd.Flags().StringVarP(&o.Filename, "filename", "f", o.Filename, "`file` to write the configuration to (relative to the Kustomize root, if specified)")
+	cmd.Flags().StringVar(&o.List, "list", "transformers", "Kustomization `list` to register the configuration under (configurations, transformers, or generators)")
+
+	return cmd
+}
+
+func (o *ConfigOptions) config() error {
+	// If a Kustomization root is specified, normalize the file paths
+	if o.Kustomize != "" {
+		var err error
+		if o.Kustomize, err = kustomizationFilename(o.Kustomize); err != nil {
+			return err
+		}
+
+		// Adjust the filename to point to where our configuration should go
+		root := filepath.Dir(o.Kustomize)
+		if o.Filename == "" {
+			o.Filename = filepath.Join(root, "kustomizeconfig", "stormforge.yaml")
+		} else if filepath.IsAbs(o.Filename) {
+			if rel, err := filepath.Rel(root, o.Filename); err != nil || rel == o.Filename {
+				return fmt.Errorf("filename must be relative or inside the Kustomization root")
+			}
+		} else {
+			o.Filename = filepath.Join(root, o.Filename)
+		}
+
+		// Make sure the directory for the configuration file exists
+		if err := os.MkdirAll(filepath.Dir(o.Filename), 0755); err != nil {
+			return err
+		}
+	}
+
+	// If there is no file name, just dump to the output stream
+	if o.Filename == "" {
+		_, err := o.Out.Write(consts.GetFieldSpecs())
+		return err
+	}
+
+	// Write the field spec file
+	if err := ioutil.WriteFile(o.Filename, consts.GetFieldSpecs(), 0644); err != nil {
+		return err
+	}
+
+	// Register the field spec file with the kustomization
+	if o.Kustomize != "" {
+		path, err := filepath.Rel(filepath.Dir(o.Kustomize), o.Filename)
+		if err != nil {
+			return err
+		}
+
+		if err := addKustomizationEntry(o.Kustomize, o.List, path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// addKustomizationEntry registers path under the named list (one of "configurations", "transformers",
+// or "generators") of the kustomization file, creating the list if necessary and leaving the rest of
+// the document (including comments and formatting) untouched. Entries are not duplicated if path is
+// already present.
+func addKustomizationEntry(kustomizationFile, list, path string) error {
+	switch list {
+	case "configurations", "transformers", "generators":
+	default:
+		return fmt.Errorf("invalid kustomization list '%s': must be one of configurations, transformers, generators", list)
+	}
+
+	b, err := ioutil.ReadFile(kustomizationFile)
+	if err != nil {
+		return err
+	}
+
+	doc, err := yaml.Parse(string(b))
+	if err != nil {
+		return err
+	}
+
+	entries, err := doc.Pipe(yaml.LookupCreate(yaml.SequenceNode, list))
+	if err != nil {
+		return err
+	}
+
+	for _, item := range entries.Content() {
+		if item.Value == path {
+			// Already registered
+			return nil
+		}
+	}
+
+	if _, err := entries.Pipe(yaml.Append(&yaml.Node{Kind: yaml.ScalarNode, Value: path})); err != nil {
+		return err
+	}
+
+	out, err := doc.String()
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(kustomizationFile, []byte(out), 0644)
+}
+
+func isRecognizedKustomizationFilename(f string) bool {
+	return f == "kustomization.yaml" || f == "kustomization.yml" || f == "Kustomization"
+}
+
+func kustomizationFilename(k string) (string, error) {
+	if f, err := os.Stat(k); err != nil {
+		// Regardless of what got passed in, it needs to exist
+		return "", err
+	} else if f.IsDir() {
+		// Iterate over directory contents, take the first match (let Kustomize do the real validation)
+		dir, err := ioutil.ReadDir(k)
+		if err != nil {
+			return "", err
+		}
+		for _, ff := range dir {
+			if isRecognizedKustomizationFilename(ff.Name()) {
+				return filepath.Join(k, ff.Name()), nil
+			}
+		}
+	} else if isRecognizedKustomizationFilename(f.Name()) {
+		// We were given a valid kustomization filename to begin with
+		return k, nil
+	}
+	return "", fmt.Errorf("invalid kustomization: %s", k)
+}
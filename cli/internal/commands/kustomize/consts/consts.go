@@ -0,0 +1,25 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package consts contains the Kustomize field specs StormForge types need registered with a
+// Kustomization so that `kustomize edit` style tooling (name references, var substitution) understands
+// our custom resource fields.
+package consts
+
+// GetFieldSpecs returns the combined name reference and var reference field specs for StormForge types.
+func GetFieldSpecs() []byte {
+	return []byte(nameReferenceFieldSpecs + varReferenceFieldSpecs)
+}
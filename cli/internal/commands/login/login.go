@@ -18,6 +18,7 @@ package login
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -205,11 +206,37 @@ func (o *Options) runDeviceCodeFlow(ctx context.Context) error {
 
 	t, err := az.Token(ctx, o.generateValidatationRequest)
 	if err != nil {
-		return err
+		return translateDeviceFlowError(err)
 	}
 	return o.takeOffline(t)
 }
 
+// translateDeviceFlowError converts the raw OAuth error returned once the device flow gives up polling
+// (e.g. because the user declined the request or the device code expired) into a message that tells the
+// user what to do next instead of the raw RFC 6749 error code.
+func translateDeviceFlowError(err error) error {
+	rErr, ok := err.(*oauth2.RetrieveError)
+	if !ok {
+		return err
+	}
+
+	var errResp struct {
+		Error string `json:"error"`
+	}
+	if jsonErr := json.Unmarshal(rErr.Body, &errResp); jsonErr != nil {
+		return err
+	}
+
+	switch errResp.Error {
+	case "access_denied":
+		return fmt.Errorf("login request was denied")
+	case "expired_token":
+		return fmt.Errorf("login code expired, please try again")
+	default:
+		return err
+	}
+}
+
 func (o *Options) runAuthorizationCodeFlow(ctx context.Context) error {
 	// Create a new authorization code flow
 	c, err := o.Config.NewAuthorization()
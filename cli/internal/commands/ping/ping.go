@@ -18,16 +18,21 @@ package ping
 
 import (
 	"context"
+	"crypto/tls"
+	"encoding/json"
 	"fmt"
+	"io"
 	"net"
+	"net/http"
+	"net/http/httptrace"
 	"net/url"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/thestormforge/optimize-controller/v2/cli/internal/commander"
 	"github.com/thestormforge/optimize-controller/v2/internal/version"
-	experimentsv1alpha1 "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
 	"github.com/thestormforge/optimize-go/pkg/config"
 	"golang.org/x/oauth2"
 )
@@ -35,68 +40,318 @@ import (
 type Options struct {
 	// Config is the Optimize Configuration
 	Config *config.OptimizeConfig
-	// ExperimentsAPI is used to interact with the Optimize Experiments API
-	ExperimentsAPI experimentsv1alpha1.API
 	// IOStreams are used to access the standard process streams
 	commander.IOStreams
+
+	// Count is the number of probes to send to each resolved address
+	Count int
+	// Interval is the amount of time to wait between probes of the same address
+	Interval time.Duration
+	// Output is the rendering format for the probe results: text, json, or prom
+	Output string
 }
 
-// NewPingCommand creates a new command for pinging the Optimize API
+// NewCommand creates a new command for pinging the Optimize API
 func NewCommand(o *Options) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "ping",
 		Short: "Ping the StormForge Optimize API",
+		Long: "Probe the StormForge Optimize API (and its OAuth token endpoint) the way traditional `ping` probes a\n" +
+			"host: every resolved address is probed individually, and latency is broken down into TLS handshake\n" +
+			"time and time-to-first-byte so API latency can be told apart from network or auth latency.",
 
 		PreRunE: func(cmd *cobra.Command, args []string) error {
 			commander.SetStreams(&o.IOStreams, cmd)
-			return commander.SetExperimentsAPI(&o.ExperimentsAPI, o.Config, cmd)
+			return nil
 		},
 		RunE: commander.WithContextE(o.ping),
 	}
 
+	cmd.Flags().IntVarP(&o.Count, "count", "c", 1, "number of probes to send to each resolved address")
+	cmd.Flags().DurationVar(&o.Interval, "interval", time.Second, "`duration` to wait between probes of the same address")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", "text", "output `format`: text|json|prom")
+
 	return cmd
 }
 
 func (o *Options) ping(ctx context.Context) error {
-	r := o.Config.Reader()
-	host, addrs, err := hostAndAddrs(ctx, r)
+	srv, err := config.CurrentServer(o.Config.Reader())
 	if err != nil {
 		return err
 	}
 
 	updateUserAgent(ctx)
 
-	_, _ = fmt.Fprintf(o.Out, "PING %s (%s): HTTP/1.1 OPTIONS\n", host, strings.Join(addrs, ", "))
+	endpoints := []struct {
+		name      string
+		rawURL    string
+		authorize bool
+	}{
+		{"api", srv.API.ExperimentsEndpoint, true},
+	}
+	if srv.Authorization.TokenEndpoint != "" {
+		endpoints = append(endpoints, struct {
+			name      string
+			rawURL    string
+			authorize bool
+		}{"auth", srv.Authorization.TokenEndpoint, false})
+	}
 
-	start := time.Now()
-	_, err = o.ExperimentsAPI.Options(ctx)
-	dur := time.Since(start).Round(time.Microsecond)
-	if err != nil {
-		return err
+	reports := make([]endpointReport, 0, len(endpoints))
+	for _, e := range endpoints {
+		report, err := o.probeEndpoint(ctx, e.name, e.rawURL, e.authorize)
+		if err != nil {
+			return err
+		}
+		reports = append(reports, report)
 	}
 
-	_, _ = fmt.Fprintf(o.Out, "PONG time=%s\n", dur.String())
+	switch strings.ToLower(o.Output) {
+	case "json":
+		enc := json.NewEncoder(o.Out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(reports)
+	case "prom":
+		writeProm(o.Out, reports)
+	default:
+		for _, r := range reports {
+			writeText(o.Out, r)
+		}
+	}
 	return nil
 }
 
-// Returns the host name and resolved addresses of the experiments API.
-func hostAndAddrs(ctx context.Context, r config.Reader) (string, []string, error) {
-	srv, err := config.CurrentServer(r)
+// endpointReport is the result of probing every resolved address of a single endpoint.
+type endpointReport struct {
+	// Name distinguishes the Experiments API endpoint from the OAuth token endpoint
+	Name string `json:"name"`
+	// Host is the hostname that was resolved
+	Host string `json:"host"`
+	// Addresses are every A/AAAA record LookupHost returned for Host
+	Addresses []string `json:"addresses"`
+	// Probes holds the result of every individual probe, in the order they were sent
+	Probes []probeResult `json:"probes"`
+	// Stats summarizes Probes' latency distribution
+	Stats latencyStats `json:"stats"`
+}
+
+// probeResult is a single HTTP/1.1 OPTIONS round trip against one resolved address.
+type probeResult struct {
+	// Address is the resolved address the probe was sent to
+	Address string `json:"address"`
+	// Error is set instead of the latency fields if the probe failed
+	Error string `json:"error,omitempty"`
+	// Total is the full round trip time
+	Total time.Duration `json:"total"`
+	// TLSHandshake is the time spent establishing TLS, zero for plain HTTP endpoints
+	TLSHandshake time.Duration `json:"tlsHandshake,omitempty"`
+	// TTFB is the time to the first response byte, measured from when the request was sent
+	TTFB time.Duration `json:"ttfb"`
+}
+
+// latencyStats summarizes the Total latency of a set of successful probes.
+type latencyStats struct {
+	Sent   int           `json:"sent"`
+	Errors int           `json:"errors"`
+	Min    time.Duration `json:"min"`
+	Avg    time.Duration `json:"avg"`
+	P50    time.Duration `json:"p50"`
+	P95    time.Duration `json:"p95"`
+	P99    time.Duration `json:"p99"`
+	Max    time.Duration `json:"max"`
+}
+
+// probeEndpoint resolves rawURL's host and sends Count probes to every resolved address, Interval apart.
+// When authorize is set, probes are sent through the same OAuth2-authorized transport the Experiments API
+// client itself uses, so the measured latency includes whatever the configured auth flow adds.
+func (o *Options) probeEndpoint(ctx context.Context, name, rawURL string, authorize bool) (endpointReport, error) {
+	u, err := url.Parse(rawURL)
 	if err != nil {
-		return "", nil, err
+		return endpointReport{}, err
 	}
 
-	u, err := url.Parse(srv.API.ExperimentsEndpoint)
+	addrs, err := net.DefaultResolver.LookupHost(ctx, u.Hostname())
 	if err != nil {
-		return "", nil, err
+		return endpointReport{}, err
 	}
 
-	host := u.Hostname()
-	addrs, err := net.DefaultResolver.LookupHost(ctx, host)
+	report := endpointReport{Name: name, Host: u.Hostname(), Addresses: addrs}
+
+	if strings.ToLower(o.Output) == "text" {
+		_, _ = fmt.Fprintf(o.Out, "PING %s %s (%s): HTTP/1.1 OPTIONS\n", name, u.Hostname(), strings.Join(addrs, ", "))
+	}
+
+	var durations []time.Duration
+	for _, addr := range addrs {
+		for i := 0; i < o.Count; i++ {
+			result := o.probeOnce(ctx, u, addr, authorize)
+			report.Probes = append(report.Probes, result)
+			if result.Error == "" {
+				durations = append(durations, result.Total)
+			} else if strings.ToLower(o.Output) == "text" {
+				_, _ = fmt.Fprintf(o.Out, "from %s: error: %s\n", addr, result.Error)
+			}
+
+			if i < o.Count-1 {
+				select {
+				case <-ctx.Done():
+					return report, ctx.Err()
+				case <-time.After(o.Interval):
+				}
+			}
+		}
+	}
+
+	report.Stats = statsFor(len(report.Probes), durations)
+	return report, nil
+}
+
+// probeOnce sends a single HTTP/1.1 OPTIONS request for u, forcing the connection to dial addr instead of
+// letting the transport re-resolve u's hostname, so each resolved address can be timed individually.
+func (o *Options) probeOnce(ctx context.Context, u *url.URL, addr string, authorize bool) probeResult {
+	result := probeResult{Address: addr}
+
+	dialer := &net.Dialer{}
+	var transport http.RoundTripper = &http.Transport{
+		DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
+			_, port, err := net.SplitHostPort(address)
+			if err != nil {
+				port = defaultPort(u)
+			}
+			return dialer.DialContext(ctx, network, net.JoinHostPort(addr, port))
+		},
+		TLSClientConfig: &tls.Config{ServerName: u.Hostname()},
+	}
+
+	if authorize {
+		authorized, err := o.Config.Authorize(ctx, transport)
+		if err != nil {
+			result.Error = err.Error()
+			return result
+		}
+		transport = authorized
+	}
+
+	client := &http.Client{Transport: transport, Timeout: 10 * time.Second}
+
+	var tlsStart, start time.Time
+	var ttfb time.Duration
+	trace := &httptrace.ClientTrace{
+		TLSHandshakeStart: func() { tlsStart = time.Now() },
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			result.TLSHandshake = time.Since(tlsStart)
+		},
+		GotFirstResponseByte: func() { ttfb = time.Since(start) },
+	}
+
+	req, err := http.NewRequestWithContext(httptrace.WithClientTrace(ctx, trace), http.MethodOptions, u.String(), nil)
 	if err != nil {
-		return "", nil, err
+		result.Error = err.Error()
+		return result
+	}
+
+	start = time.Now()
+	resp, err := client.Do(req)
+	result.Total = time.Since(start)
+	result.TTFB = ttfb
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	return result
+}
+
+// defaultPort returns u's explicit port, or the scheme's well-known port if it did not specify one.
+func defaultPort(u *url.URL) string {
+	if p := u.Port(); p != "" {
+		return p
+	}
+	if u.Scheme == "https" {
+		return "443"
+	}
+	return "80"
+}
+
+// statsFor computes min/avg/percentile/max over durations, the latencies of the successful probes out of
+// the sent total.
+func statsFor(sent int, durations []time.Duration) latencyStats {
+	stats := latencyStats{Sent: sent, Errors: sent - len(durations)}
+	if len(durations) == 0 {
+		return stats
+	}
+
+	sorted := make([]time.Duration, len(durations))
+	copy(sorted, durations)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var sum time.Duration
+	for _, d := range sorted {
+		sum += d
+	}
+
+	stats.Min = sorted[0]
+	stats.Max = sorted[len(sorted)-1]
+	stats.Avg = sum / time.Duration(len(sorted))
+	stats.P50 = percentile(sorted, 0.50)
+	stats.P95 = percentile(sorted, 0.95)
+	stats.P99 = percentile(sorted, 0.99)
+	return stats
+}
+
+// percentile returns the p-th percentile (0 < p <= 1) of sorted, which must already be sorted ascending.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+	idx := int(p * float64(len(sorted)-1))
+	return sorted[idx]
+}
+
+// writeText renders a report the way traditional `ping` summarizes a run, one line per probe followed by
+// a statistics summary.
+func writeText(w io.Writer, r endpointReport) {
+	for _, p := range r.Probes {
+		if p.Error != "" {
+			continue
+		}
+		_, _ = fmt.Fprintf(w, "from %s: %s time=%s tls=%s ttfb=%s\n",
+			p.Address, r.Name, p.Total.Round(time.Microsecond), p.TLSHandshake.Round(time.Microsecond), p.TTFB.Round(time.Microsecond))
+	}
+
+	s := r.Stats
+	_, _ = fmt.Fprintf(w, "--- %s (%s) ping statistics ---\n", r.Name, r.Host)
+	_, _ = fmt.Fprintf(w, "%d probes sent, %d errors\n", s.Sent, s.Errors)
+	if s.Sent > s.Errors {
+		_, _ = fmt.Fprintf(w, "min/avg/p50/p95/p99/max = %s/%s/%s/%s/%s/%s\n",
+			s.Min.Round(time.Microsecond), s.Avg.Round(time.Microsecond), s.P50.Round(time.Microsecond),
+			s.P95.Round(time.Microsecond), s.P99.Round(time.Microsecond), s.Max.Round(time.Microsecond))
+	}
+}
+
+// writeProm renders every report's statistics as Prometheus text exposition format, so ping can be scraped
+// as a one-shot health check.
+func writeProm(w io.Writer, reports []endpointReport) {
+	_, _ = fmt.Fprintln(w, "# HELP stormforge_ping_latency_seconds Latency of a StormForge Optimize API probe.")
+	_, _ = fmt.Fprintln(w, "# TYPE stormforge_ping_latency_seconds summary")
+	for _, r := range reports {
+		quantiles := []struct {
+			name string
+			d    time.Duration
+		}{
+			{"0.5", r.Stats.P50},
+			{"0.95", r.Stats.P95},
+			{"0.99", r.Stats.P99},
+		}
+		for _, q := range quantiles {
+			_, _ = fmt.Fprintf(w, "stormforge_ping_latency_seconds{endpoint=%q,host=%q,quantile=%q} %f\n",
+				r.Name, r.Host, q.name, q.d.Seconds())
+		}
+		_, _ = fmt.Fprintf(w, "stormforge_ping_latency_seconds_sum{endpoint=%q,host=%q} %f\n", r.Name, r.Host, r.Stats.Avg.Seconds()*float64(r.Stats.Sent-r.Stats.Errors))
+		_, _ = fmt.Fprintf(w, "stormforge_ping_latency_seconds_count{endpoint=%q,host=%q} %d\n", r.Name, r.Host, r.Stats.Sent-r.Stats.Errors)
+		_, _ = fmt.Fprintf(w, "stormforge_ping_errors_total{endpoint=%q,host=%q} %d\n", r.Name, r.Host, r.Stats.Errors)
 	}
-	return host, addrs, nil
 }
 
 // Adds a comment to the UA string so we know the source of all these OPTIONS requests
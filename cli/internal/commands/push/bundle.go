@@ -0,0 +1,121 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package push
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const (
+	// ConfigMediaType is the media type of the artifact manifest's config blob; an experiment bundle
+	// has no meaningful configuration of its own so this is just an empty JSON object.
+	ConfigMediaType = "application/vnd.stormforge.experiment.v1+json"
+	// ManifestsLayerMediaType is the media type of the layer containing the experiment's YAML
+	// manifests: the Experiment itself, its trial job template, patches, and setup manifests.
+	ManifestsLayerMediaType = "application/vnd.stormforge.experiment.manifests.v1.tar+gzip"
+	// ValuesSchemaLayerMediaType is the media type of the optional layer containing a JSON Schema for
+	// the values a consumer of the bundle is expected to override.
+	ValuesSchemaLayerMediaType = "application/vnd.stormforge.experiment.values-schema.v1+json"
+)
+
+// packManifests tars and gzips the supplied resource nodes into a single layer, preserving each
+// node's recorded path annotation so pull can recreate the original file layout.
+func packManifests(nodes []*yaml.RNode) ([]byte, error) {
+	var paths []string
+	byPath := make(map[string][]*yaml.RNode)
+	for _, n := range nodes {
+		path := "manifests.yaml"
+		if p, err := n.Pipe(yaml.GetAnnotation(kioutil.PathAnnotation)); err == nil && p != nil && p.YNode().Value != "" {
+			path = p.YNode().Value
+		}
+
+		if _, ok := byPath[path]; !ok {
+			paths = append(paths, path)
+		}
+		byPath[path] = append(byPath[path], n)
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+
+	for _, path := range paths {
+		var b bytes.Buffer
+		w := kio.ByteWriter{Writer: &b, ClearAnnotations: []string{kioutil.PathAnnotation}}
+		if err := w.Write(byPath[path]); err != nil {
+			return nil, err
+		}
+
+		if err := tw.WriteHeader(&tar.Header{Name: path, Mode: 0600, Size: int64(b.Len())}); err != nil {
+			return nil, err
+		}
+		if _, err := tw.Write(b.Bytes()); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// unpackManifests reverses packManifests, returning a reader per original file so migration filters
+// can be applied the same way they are for files read directly off disk.
+func unpackManifests(data []byte) ([]kio.Reader, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	var readers []kio.Reader
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		b, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+
+		readers = append(readers, &kio.ByteReader{
+			Reader:         bytes.NewReader(b),
+			SetAnnotations: map[string]string{kioutil.PathAnnotation: hdr.Name},
+		})
+	}
+
+	return readers, nil
+}
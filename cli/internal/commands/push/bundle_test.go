@@ -0,0 +1,89 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package push
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// TestPackUnpackManifests_RoundTrip verifies that unpackManifests reverses packManifests: every node
+// is preserved, and nodes recorded under different path annotations come back as separate files
+// instead of being merged into one.
+func TestPackUnpackManifests_RoundTrip(t *testing.T) {
+	experiment := readNode(t, "experiment.yaml", "kind: Experiment\nmetadata:\n  name: sample\n")
+	patch := readNode(t, "patches/deployment.yaml", "kind: Patch\nmetadata:\n  name: deployment\n")
+
+	packed, err := packManifests([]*yaml.RNode{experiment, patch})
+	require.NoError(t, err)
+	assert.NotEmpty(t, packed)
+
+	readers, err := unpackManifests(packed)
+	require.NoError(t, err)
+	require.Len(t, readers, 2)
+
+	got := make(map[string]string, len(readers))
+	for _, r := range readers {
+		nodes, err := r.Read()
+		require.NoError(t, err)
+		require.Len(t, nodes, 1)
+
+		path, err := nodes[0].Pipe(yaml.GetAnnotation(kioutil.PathAnnotation))
+		require.NoError(t, err)
+		require.NotNil(t, path)
+
+		s, err := nodes[0].String()
+		require.NoError(t, err)
+		got[path.YNode().Value] = s
+	}
+
+	assert.Contains(t, got["experiment.yaml"], "name: sample")
+	assert.Contains(t, got["patches/deployment.yaml"], "name: deployment")
+}
+
+// TestPackManifests_DefaultPath verifies that a node with no recorded path annotation is packed
+// under the "manifests.yaml" fallback rather than being dropped.
+func TestPackManifests_DefaultPath(t *testing.T) {
+	node := yaml.MustParse("kind: Experiment\nmetadata:\n  name: sample\n")
+
+	packed, err := packManifests([]*yaml.RNode{node})
+	require.NoError(t, err)
+
+	readers, err := unpackManifests(packed)
+	require.NoError(t, err)
+	require.Len(t, readers, 1)
+
+	nodes, err := readers[0].Read()
+	require.NoError(t, err)
+	require.Len(t, nodes, 1)
+
+	path, err := nodes[0].Pipe(yaml.GetAnnotation(kioutil.PathAnnotation))
+	require.NoError(t, err)
+	require.Equal(t, "manifests.yaml", path.YNode().Value)
+}
+
+func readNode(t *testing.T, path, contents string) *yaml.RNode {
+	t.Helper()
+	n := yaml.MustParse(contents)
+	require.NoError(t, n.PipeE(yaml.SetAnnotation(kioutil.PathAnnotation, path)))
+	return n
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package push
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/thestormforge/optimize-controller/v2/cli/internal/commander"
+	"github.com/thestormforge/optimize-controller/v2/internal/sfio"
+	"oras.land/oras-go/pkg/content"
+	"oras.land/oras-go/pkg/oras"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/kio/filters"
+	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// PullOptions are the configuration options for pulling and unpacking an experiment bundle.
+type PullOptions struct {
+	Options
+
+	// InPlace writes the pulled manifests back to the paths they were pushed with instead of the
+	// standard output stream.
+	InPlace bool
+}
+
+// NewPullCommand creates a command for pulling an experiment bundle from an OCI registry.
+func NewPullCommand(o *PullOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pull REFERENCE",
+		Short: "Pull an experiment from an OCI registry",
+		Long:  "Pull an experiment bundle, migrating it forward through the same filters used by the `fix` command",
+
+		Args: cobra.ExactArgs(1),
+
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			o.Reference = args[0]
+			commander.SetStreams(&o.IOStreams, cmd)
+			return nil
+		},
+		RunE: commander.WithContextE(o.pull),
+	}
+
+	cmd.Flags().BoolVarP(&o.InPlace, "in-place", "i", false, "write manifests back to their original paths WITHOUT BACKUPS")
+	cmd.Flags().BoolVar(&o.PlainHTTP, "plain-http", false, "use plain HTTP instead of HTTPS when pulling")
+
+	return cmd
+}
+
+func (o *PullOptions) pull(ctx context.Context) error {
+	store := content.NewMemory()
+
+	_, descriptors, err := oras.Pull(ctx, o.resolver(), o.Reference, store)
+	if err != nil {
+		return fmt.Errorf("failed to pull %s: %w", o.Reference, err)
+	}
+
+	p := kio.Pipeline{
+		Filters: []kio.Filter{
+			kio.FilterAll(&sfio.ExperimentMigrationFilter{}),
+			kio.FilterAll(&sfio.MetadataMigrationFilter{}),
+			filters.FormatFilter{},
+		},
+	}
+
+	for _, desc := range descriptors {
+		if desc.MediaType != ManifestsLayerMediaType {
+			continue
+		}
+
+		_, manifests, ok := store.Get(desc)
+		if !ok {
+			return fmt.Errorf("missing manifests layer %s", desc.Digest)
+		}
+
+		readers, err := unpackManifests(manifests)
+		if err != nil {
+			return err
+		}
+		for _, r := range readers {
+			p.Inputs = append(p.Inputs, r)
+		}
+	}
+
+	if len(p.Inputs) == 0 {
+		return fmt.Errorf("%s does not contain a %s layer", o.Reference, ManifestsLayerMediaType)
+	}
+
+	if o.InPlace {
+		p.Outputs = append(p.Outputs, kio.WriterFunc(o.writeBackToPathAnnotation))
+	} else {
+		p.Outputs = append(p.Outputs, o.YAMLWriter())
+	}
+
+	return p.Execute()
+}
+
+func (o *PullOptions) writeBackToPathAnnotation(nodes []*yaml.RNode) error {
+	// Note: we cannot use the kio.LocalPackageWriter because it assumes a common base directory
+
+	if err := kioutil.DefaultPathAndIndexAnnotation("", nodes); err != nil {
+		return err
+	}
+
+	pathIndex := make(map[string][]*yaml.RNode, len(nodes))
+	for _, n := range nodes {
+		if path, err := n.Pipe(yaml.GetAnnotation(kioutil.PathAnnotation)); err == nil {
+			pathIndex[path.YNode().Value] = append(pathIndex[path.YNode().Value], n)
+		}
+	}
+	for k := range pathIndex {
+		_ = kioutil.SortNodes(pathIndex[k])
+	}
+
+	for k, v := range pathIndex {
+		if err := o.writeToPath(k, v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (o *PullOptions) writeToPath(path string, nodes []*yaml.RNode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := kio.ByteWriter{
+		Writer:           f,
+		ClearAnnotations: []string{kioutil.PathAnnotation},
+	}
+	return w.Write(nodes)
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package push packages an Experiment, its trial job template, patches, and setup manifests as an
+// OCI artifact so it can be shared and versioned through the same registries used for images and
+// Helm charts.
+package push
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/containerd/containerd/remotes/docker"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/spf13/cobra"
+	"github.com/thestormforge/optimize-controller/v2/cli/internal/commander"
+	"oras.land/oras-go/pkg/content"
+	"oras.land/oras-go/pkg/oras"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// Options are the configuration options shared by the push and pull commands.
+type Options struct {
+	commander.IOStreams
+
+	// Reference is the OCI reference to push to or pull from, e.g. "registry.example.com/experiments/my-app:latest".
+	Reference string
+	// PlainHTTP disables TLS when talking to the registry; intended for local/dev registries only.
+	PlainHTTP bool
+}
+
+func (o *Options) resolver() docker.Resolver {
+	return docker.NewResolver(docker.ResolverOptions{PlainHTTP: o.PlainHTTP})
+}
+
+// PushOptions are the configuration options for packaging and pushing an experiment bundle.
+type PushOptions struct {
+	Options
+
+	// Filenames are the manifest files to bundle: the Experiment, its trial job template, patches,
+	// and any setup manifests it references.
+	Filenames []string
+	// ValuesSchemaFile is an optional JSON Schema describing the values a consumer of the bundle may override.
+	ValuesSchemaFile string
+}
+
+// NewPushCommand creates a command for pushing an experiment bundle to an OCI registry.
+func NewPushCommand(o *PushOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "push REFERENCE",
+		Short: "Push an experiment to an OCI registry",
+		Long:  "Package an experiment, its trial job template, patches, and setup manifests as an OCI artifact and push it to a registry",
+
+		Args: cobra.ExactArgs(1),
+
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			o.Reference = args[0]
+			commander.SetStreams(&o.IOStreams, cmd)
+			return nil
+		},
+		RunE: commander.WithContextE(o.push),
+	}
+
+	cmd.Flags().StringArrayVarP(&o.Filenames, "filename", "f", nil, "manifest `file` to package")
+	cmd.Flags().StringVar(&o.ValuesSchemaFile, "values-schema", "", "optional JSON Schema `file` describing overridable values")
+	cmd.Flags().BoolVar(&o.PlainHTTP, "plain-http", false, "use plain HTTP instead of HTTPS when pushing")
+
+	_ = cmd.MarkFlagFilename("filename", "yml", "yaml")
+	_ = cmd.MarkFlagFilename("values-schema", "json")
+	_ = cmd.MarkFlagRequired("filename")
+
+	return cmd
+}
+
+func (o *PushOptions) push(ctx context.Context) error {
+	var nodes []*yaml.RNode
+	p := kio.Pipeline{
+		Outputs: []kio.Writer{kio.WriterFunc(func(n []*yaml.RNode) error {
+			nodes = append(nodes, n...)
+			return nil
+		})},
+	}
+	for _, filename := range o.Filenames {
+		p.Inputs = append(p.Inputs, o.YAMLReader(filename))
+	}
+	if err := p.Execute(); err != nil {
+		return err
+	}
+
+	manifests, err := packManifests(nodes)
+	if err != nil {
+		return err
+	}
+
+	store := content.NewMemory()
+	descriptors := []ocispec.Descriptor{}
+
+	manifestsDesc, err := store.Add("manifests.tar.gz", ManifestsLayerMediaType, manifests)
+	if err != nil {
+		return err
+	}
+	descriptors = append(descriptors, manifestsDesc)
+
+	if o.ValuesSchemaFile != "" {
+		f, err := o.OpenFile(o.ValuesSchemaFile)
+		if err != nil {
+			return err
+		}
+		schema, err := ioutil.ReadAll(f)
+		_ = f.Close()
+		if err != nil {
+			return err
+		}
+		schemaDesc, err := store.Add(o.ValuesSchemaFile, ValuesSchemaLayerMediaType, schema)
+		if err != nil {
+			return err
+		}
+		descriptors = append(descriptors, schemaDesc)
+	}
+
+	manifest, manifestDesc, config, configDesc, err := content.GenerateManifestAndConfig(nil, nil, descriptors...)
+	if err != nil {
+		return err
+	}
+	manifestDesc.MediaType, configDesc.MediaType = ocispec.MediaTypeImageManifest, ConfigMediaType
+	store.Set(configDesc, config)
+	store.Set(manifestDesc, manifest)
+
+	desc, err := oras.Push(ctx, o.resolver(), o.Reference, store, descriptors, oras.WithConfig(configDesc))
+	if err != nil {
+		return fmt.Errorf("failed to push %s: %w", o.Reference, err)
+	}
+
+	_, _ = fmt.Fprintf(o.Out, "pushed %s (digest: %s)\n", o.Reference, desc.Digest)
+	return nil
+}
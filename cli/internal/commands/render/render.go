@@ -0,0 +1,181 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package render implements the "render" command, which renders an experiment's patch
+// templates against a trial without contacting a cluster or the Optimize API.
+package render
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"strings"
+
+	"github.com/spf13/cobra"
+	optimizev1beta2 "github.com/thestormforge/optimize-controller/v2/api/v1beta2"
+	"github.com/thestormforge/optimize-controller/v2/cli/internal/commander"
+	"github.com/thestormforge/optimize-controller/v2/internal/experiment"
+	"github.com/thestormforge/optimize-controller/v2/internal/patch"
+	"github.com/thestormforge/optimize-controller/v2/internal/template"
+	"github.com/thestormforge/optimize-go/pkg/config"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/yaml"
+)
+
+// Options are the configuration for rendering an experiment's patch templates
+type Options struct {
+	// Config is the Optimize Configuration
+	Config *config.OptimizeConfig
+	// IOStreams are used to access the standard process streams
+	commander.IOStreams
+
+	// Filename of the experiment manifest to render
+	Filename string
+	// Assign are explicit `name=value` parameter assignments (repeatable)
+	Assign []string
+	// AssignmentsFile is a JSON/YAML file of assignments to apply
+	AssignmentsFile string
+	// Random causes unassigned parameters to be sampled from their feasible space
+	Random bool
+	// Target restricts rendering to the patch whose target reference name matches
+	Target string
+}
+
+// NewCommand creates a new command for rendering an experiment's patch templates
+func NewCommand(o *Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "render",
+		Short: "Render an experiment's patches",
+		Long: "Render the patch templates of an experiment manifest against a trial, without applying them to a\n" +
+			"cluster or contacting the Optimize API. This is useful for quickly iterating on patch templates.",
+
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			commander.SetStreams(&o.IOStreams, cmd)
+			if o.Filename == "" {
+				return fmt.Errorf("experiment filename must be specified")
+			}
+			return nil
+		},
+		RunE: commander.WithoutArgsE(o.render),
+	}
+
+	cmd.Flags().StringVarP(&o.Filename, "filename", "f", "", "experiment manifest `file` to render, - for stdin")
+	cmd.Flags().StringArrayVar(&o.Assign, "assign", nil, "assign a parameter value as `name=value` (repeatable)")
+	cmd.Flags().StringVar(&o.AssignmentsFile, "assignments-file", "", "JSON/YAML `file` of parameter assignments")
+	cmd.Flags().BoolVar(&o.Random, "random", false, "sample unassigned parameters from their feasible space")
+	cmd.Flags().StringVar(&o.Target, "target", "", "only render the patch targeting the named object")
+
+	_ = cmd.MarkFlagFilename("filename", "yml", "yaml")
+	_ = cmd.MarkFlagFilename("assignments-file", "yml", "yaml", "json")
+
+	return cmd
+}
+
+func (o *Options) render() error {
+	exp := &optimizev1beta2.Experiment{}
+	r, err := o.OpenFile(o.Filename)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	if err := commander.NewResourceReader().ReadInto(r, exp); err != nil {
+		return err
+	}
+
+	trial := &optimizev1beta2.Trial{}
+	experiment.PopulateTrialFromTemplate(exp, trial)
+
+	if o.Random {
+		for _, p := range exp.Spec.Parameters {
+			trial.Spec.Assignments = assignParameter(trial.Spec.Assignments, p.Name, randomAssignment(p))
+		}
+	}
+
+	if o.AssignmentsFile != "" {
+		af, err := o.OpenFile(o.AssignmentsFile)
+		if err != nil {
+			return err
+		}
+		defer af.Close()
+
+		data, err := io.ReadAll(af)
+		if err != nil {
+			return err
+		}
+
+		var fileAssignments []optimizev1beta2.Assignment
+		if err := yaml.UnmarshalStrict(data, &fileAssignments); err != nil {
+			return fmt.Errorf("unable to parse assignments file: %w", err)
+		}
+		for _, a := range fileAssignments {
+			trial.Spec.Assignments = assignParameter(trial.Spec.Assignments, a.Name, a.Value)
+		}
+	}
+
+	for _, assign := range o.Assign {
+		i := strings.Index(assign, "=")
+		if i < 0 {
+			return fmt.Errorf("invalid assignment %q, expected name=value", assign)
+		}
+		name, value := assign[:i], assign[i+1:]
+		trial.Spec.Assignments = assignParameter(trial.Spec.Assignments, name, intstr.Parse(value))
+	}
+
+	te := template.New()
+	for i := range exp.Spec.Patches {
+		p := &exp.Spec.Patches[i]
+		if o.Target != "" && (p.TargetRef == nil || p.TargetRef.Name != o.Target) {
+			continue
+		}
+
+		ref, data, err := patch.RenderTemplate(te, trial, p)
+		if err != nil {
+			return fmt.Errorf("unable to render patch %d: %w", i, err)
+		}
+
+		fmt.Fprintf(o.Out, "--- %s\n%s\n", ref.String(), data)
+	}
+
+	out, err := yaml.Marshal(trial)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(o.Out, "---\n%s", out)
+
+	return nil
+}
+
+// assignParameter sets (or replaces) the assignment for the named parameter.
+func assignParameter(assignments []optimizev1beta2.Assignment, name string, value intstr.IntOrString) []optimizev1beta2.Assignment {
+	for i := range assignments {
+		if assignments[i].Name == name {
+			assignments[i].Value = value
+			return assignments
+		}
+	}
+	return append(assignments, optimizev1beta2.Assignment{Name: name, Value: value})
+}
+
+// randomAssignment samples a value from a parameter's feasible space using the global
+// random number generator (seeded at process start using a cryptographic random source,
+// see cli/main.go).
+func randomAssignment(p optimizev1beta2.Parameter) intstr.IntOrString {
+	if len(p.Values) > 0 {
+		return intstr.FromString(p.Values[rand.Intn(len(p.Values))])
+	}
+	return intstr.FromInt(int(p.Min + rand.Int31n(p.Max-p.Min+1)))
+}
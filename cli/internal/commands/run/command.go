@@ -17,8 +17,6 @@ limitations under the License.
 package run
 
 import (
-	"bufio"
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -30,17 +28,18 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/muesli/termenv"
-	optimizev1beta2 "github.com/thestormforge/optimize-controller/v2/api/v1beta2"
 	"github.com/thestormforge/optimize-controller/v2/cli/internal/commander"
 	"github.com/thestormforge/optimize-controller/v2/cli/internal/commands/check"
 	"github.com/thestormforge/optimize-controller/v2/cli/internal/commands/initialize"
 	"github.com/thestormforge/optimize-controller/v2/cli/internal/commands/run/internal"
+	"github.com/thestormforge/optimize-controller/v2/cli/internal/commands/run/livestatestore"
 	versioncmd "github.com/thestormforge/optimize-controller/v2/cli/internal/commands/version"
+	"github.com/thestormforge/optimize-controller/v2/internal/sfio"
+	"github.com/thestormforge/optimize-controller/v2/pkg/kubeclient"
 	"github.com/thestormforge/optimize-go/pkg/config"
-	corev1 "k8s.io/api/core/v1"
-	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
 	"sigs.k8s.io/kustomize/kyaml/kio"
-	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
 
 // This is where you will find all of the tea.Cmd functions that are used to
@@ -165,20 +164,18 @@ func (o *Options) initializeController() tea.Msg {
 
 // listKubernetesNamespaces returns a list of the namespaces in the Kubernetes cluster.
 func (o *Options) listKubernetesNamespaces() tea.Msg {
-	ctx := context.TODO()
-	msg := internal.KubernetesNamespacesMsg{}
-
-	cmd, err := o.Config.Kubectl(ctx, "get", "namespaces", "--output", "name")
-	if err != nil {
+	if err := o.startKubeClient(); err != nil {
 		return err
 	}
-	data, err := cmd.Output()
+
+	names, err := o.kubeClient.Namespaces()
 	if err != nil {
 		return err
 	}
-	scanner := bufio.NewScanner(bytes.NewReader(data))
-	for scanner.Scan() {
-		if ns := strings.TrimPrefix(scanner.Text(), "namespace/"); !o.hideKubernetesNamespace(ns) {
+
+	msg := internal.KubernetesNamespacesMsg{}
+	for _, ns := range names {
+		if !o.hideKubernetesNamespace(ns) {
 			msg = append(msg, ns)
 		}
 	}
@@ -228,20 +225,16 @@ func (o *Options) generateExperiment() tea.Msg {
 
 // createExperimentInCluster creates the raw experiment manifests in the cluster.
 func (o *Options) createExperimentInCluster() tea.Msg {
-	ctx := context.TODO()
-
-	data, err := kio.StringAll(o.runModel.experiment)
-	if err != nil {
+	if err := o.startKubeClient(); err != nil {
 		return err
 	}
 
-	cmd, err := o.Config.Kubectl(ctx, "create", "-f", "-")
+	data, err := kio.StringAll(o.runModel.experiment)
 	if err != nil {
 		return err
 	}
 
-	cmd.Stdin = strings.NewReader(data)
-	if _, err := cmd.Output(); err != nil {
+	if _, err := o.kubeClient.Create(strings.NewReader(data)); err != nil {
 		return fmt.Errorf("could not create experiment, %w", err)
 	}
 
@@ -267,71 +260,116 @@ func (o *Options) createExperimentInFile() tea.Msg {
 	return internal.ExperimentCreatedMsg{Filename: f.Name()}
 }
 
-// refreshTrials fetches the trial list for the experiment as raw YAML.
-func (o *Options) refreshTrials() tea.Msg {
-	ctx := context.TODO()
+// watchLiveState waits for the live state store to report the next change to
+// the running experiment, starting the store on the first call. It blocks
+// until an event is available, so the TUI stays responsive by running it as
+// a tea.Cmd rather than polling on a fixed interval.
+func (o *Options) watchLiveState() tea.Msg {
+	if o.liveStateEvents == nil {
+		if err := o.startLiveState(); err != nil {
+			return err
+		}
+	}
 
-	// TODO This should be refactored to only use state from the runModel
-	// We are using the previewModel for functionality specific to the runModel,
-	// this should be changed so the namespace, name, and selector are on the runModel
-	namespace := o.previewModel.Experiment.Namespace
-	name := o.previewModel.Experiment.Name
-	labelSelector := meta.FormatLabelSelector(o.previewModel.Experiment.TrialSelector())
-
-	getExperiment, err := o.Config.Kubectl(ctx,
-		"get", "experiment",
-		"--namespace", namespace,
-		name,
-		"--output", "yaml")
+	evt, ok := <-o.liveStateEvents
+	if !ok {
+		return nil
+	}
+
+	switch evt := evt.(type) {
+	case livestatestore.TrialsEvent:
+		return internal.TrialsMsg(evt.Trials)
+	case livestatestore.ExperimentFinishedEvent:
+		return internal.ExperimentFinishedMsg{Failed: evt.Failed}
+	default:
+		return nil
+	}
+}
+
+// =============================================================================
+// All the tea.Cmd functions are above, helpers are below
+// =============================================================================
+
+// discard is an IOStreams equivalent of ioutil.Discard for combined output.
+var discard = commander.IOStreams{
+	Out:    ioutil.Discard,
+	ErrOut: ioutil.Discard,
+}
+
+// startLiveState constructs and starts the live state store for the cluster
+// the experiment was created in and subscribes to changes for it.
+func (o *Options) startLiveState() error {
+	cfg, err := restConfig(o.Config)
 	if err != nil {
 		return err
 	}
 
-	expNodes, err := (*execReader)(getExperiment).Read()
+	namespace := o.previewModel.Experiment.Namespace
+	store, err := livestatestore.NewStore(cfg, sfio.Scheme, namespace)
 	if err != nil {
-		return fmt.Errorf("could not get experiment for status, %w", err)
-	}
-	for _, node := range expNodes {
-		switch {
-		case conditionStatus(node, optimizev1beta2.ExperimentComplete) == corev1.ConditionTrue:
-			return internal.ExperimentFinishedMsg{}
-		case conditionStatus(node, optimizev1beta2.ExperimentFailed) == corev1.ConditionTrue:
-			return internal.ExperimentFinishedMsg{Failed: true}
-		}
+		return err
 	}
 
-	getTrials, err := o.Config.Kubectl(ctx,
-		"get", "trials",
-		"--namespace", namespace,
-		"--selector", labelSelector,
-		"--output", "yaml")
+	if err := store.Start(context.Background()); err != nil {
+		return err
+	}
+
+	o.liveState = store
+	o.liveStateEvents = store.Subscribe(namespace + "/" + o.previewModel.Experiment.Name)
+	return nil
+}
+
+// stopLiveState shuts down the live state store if it was started.
+func (o *Options) stopLiveState() {
+	if o.liveState != nil {
+		o.liveState.Stop()
+	}
+}
+
+// startKubeClient constructs the typed Kubernetes client used for direct cluster operations (creating the
+// experiment, listing namespaces), if it has not been created yet.
+func (o *Options) startKubeClient() error {
+	if o.kubeClient != nil {
+		return nil
+	}
+
+	cfg, err := restConfig(o.Config)
 	if err != nil {
 		return err
 	}
 
-	trialNodes, err := (*execReader)(getTrials).Read()
+	c, err := kubeclient.NewForConfig(cfg, o.previewModel.Experiment.Namespace)
 	if err != nil {
-		return fmt.Errorf("could not get trials for status, %w", err)
+		return err
 	}
-	return internal.TrialsMsg(trialNodes)
-}
 
-// refreshTrialsTick is used to initiate the a refresh of the trial list after
-// a fixed (2 second) delay.
-func (o *Options) refreshTrialsTick() tea.Cmd {
-	return tea.Tick(2*time.Second, func(t time.Time) tea.Msg {
-		return internal.TrialsRefreshMsg(t)
-	})
+	o.kubeClient = c
+	return nil
 }
 
-// =============================================================================
-// All the tea.Cmd functions are above, helpers are below
-// =============================================================================
+// restConfig resolves a REST configuration for the cluster currently
+// configured for Optimize, mirroring the kubeconfig/context/namespace
+// resolution used by Config.Kubectl.
+func restConfig(cfg *config.OptimizeConfig) (*rest.Config, error) {
+	cstr, err := config.CurrentCluster(cfg.Reader())
+	if err != nil {
+		return nil, err
+	}
 
-// discard is an IOStreams equivalent of ioutil.Discard for combined output.
-var discard = commander.IOStreams{
-	Out:    ioutil.Discard,
-	ErrOut: ioutil.Discard,
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if cstr.KubeConfig != "" {
+		loadingRules.ExplicitPath = cstr.KubeConfig
+	}
+
+	overrides := &clientcmd.ConfigOverrides{}
+	if cstr.Context != "" {
+		overrides.CurrentContext = cstr.Context
+	}
+	if cstr.Namespace != "" {
+		overrides.Context.Namespace = cstr.Namespace
+	}
+
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides).ClientConfig()
 }
 
 // hideKubernetesNamespace is used to filter the list of namespaces to display
@@ -380,23 +418,3 @@ type forgeResponse struct {
 type forgeAttributes struct {
 	Name string `json:"name"`
 }
-
-// execReader is used to parse YAML output from a command.
-type execReader exec.Cmd
-
-func (r *execReader) Read() ([]*yaml.RNode, error) {
-	data, err := (*exec.Cmd)(r).Output()
-	if err != nil {
-		return nil, err
-	}
-	return kio.FromBytes(data)
-}
-
-// conditionStatus looks for experiment conditions given a YAML representation of the experiment.
-func conditionStatus(n *yaml.RNode, t optimizev1beta2.ExperimentConditionType) corev1.ConditionStatus {
-	v, err := n.Pipe(yaml.Lookup("status", "conditions", fmt.Sprintf("[type=%s]", t), "status"))
-	if err == nil && v != nil {
-		return corev1.ConditionStatus(v.YNode().Value)
-	}
-	return corev1.ConditionUnknown
-}
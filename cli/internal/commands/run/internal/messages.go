@@ -17,8 +17,6 @@ limitations under the License.
 package internal
 
 import (
-	"time"
-
 	tea "github.com/charmbracelet/bubbletea"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
@@ -89,6 +87,3 @@ type ExperimentFinishedMsg struct {
 // TrialsMsg represents the current trial list of the experiment fetched as part
 // of a status update.
 type TrialsMsg []*yaml.RNode
-
-// TrialsRefreshMsg is used to indicate when the list of trials should be refreshed.
-type TrialsRefreshMsg time.Time
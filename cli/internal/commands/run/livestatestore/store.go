@@ -0,0 +1,342 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package livestatestore maintains a watch-driven view of the Experiment and
+// Trial objects associated with a single running experiment. Instead of
+// polling the API server on a fixed interval, it uses client-go informers to
+// keep a local cache in sync and notifies subscribers only when something
+// actually changes (inspired by the live state store used by PipeCD).
+package livestatestore
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	optimizev1beta2 "github.com/thestormforge/optimize-controller/v2/api/v1beta2"
+	"github.com/thestormforge/optimize-controller/v2/internal/sfio"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// debounceInterval bounds how long the store waits for additional informer
+// events before publishing, coalescing bursts of changes into a single update.
+const debounceInterval = 250 * time.Millisecond
+
+// trialExperimentIndex indexes trials by the experiment they belong to.
+const trialExperimentIndex = "experiment"
+
+// Event is implemented by the notifications delivered to a subscriber channel.
+type Event interface {
+	isEvent()
+}
+
+// TrialsEvent carries the current trial list for an experiment.
+type TrialsEvent struct {
+	Trials []*yaml.RNode
+}
+
+func (TrialsEvent) isEvent() {}
+
+// ExperimentFinishedEvent indicates the experiment has completed or failed.
+type ExperimentFinishedEvent struct {
+	Failed bool
+}
+
+func (ExperimentFinishedEvent) isEvent() {}
+
+// Store is a live, watch-driven cache of Experiment and Trial objects. It
+// must be started before use and stopped when it is no longer needed.
+type Store struct {
+	experiments cache.SharedIndexInformer
+	trials      cache.SharedIndexInformer
+
+	mu          sync.Mutex
+	subscribers map[string][]chan Event
+	timers      map[string]*time.Timer
+
+	cancel context.CancelFunc
+}
+
+// NewStore creates a store that watches Experiment and Trial objects in the
+// supplied namespace using the given REST configuration.
+func NewStore(config *rest.Config, scheme *runtime.Scheme, namespace string) (*Store, error) {
+	experimentClient, err := newRESTClient(config, scheme, &optimizev1beta2.Experiment{})
+	if err != nil {
+		return nil, fmt.Errorf("livestatestore: could not create experiment client: %w", err)
+	}
+
+	trialClient, err := newRESTClient(config, scheme, &optimizev1beta2.Trial{})
+	if err != nil {
+		return nil, fmt.Errorf("livestatestore: could not create trial client: %w", err)
+	}
+
+	s := &Store{
+		subscribers: make(map[string][]chan Event),
+		timers:      make(map[string]*time.Timer),
+	}
+
+	paramCodec := runtime.NewParameterCodec(scheme)
+
+	s.experiments = cache.NewSharedIndexInformer(
+		listWatch(experimentClient, "experiments", namespace, paramCodec, func() runtime.Object { return &optimizev1beta2.ExperimentList{} }),
+		&optimizev1beta2.Experiment{},
+		0,
+		cache.Indexers{},
+	)
+	s.experiments.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { s.notifyExperiment(obj) },
+		UpdateFunc: func(_, obj interface{}) { s.notifyExperiment(obj) },
+		DeleteFunc: func(obj interface{}) { s.notifyExperiment(obj) },
+	})
+
+	s.trials = cache.NewSharedIndexInformer(
+		listWatch(trialClient, "trials", namespace, paramCodec, func() runtime.Object { return &optimizev1beta2.TrialList{} }),
+		&optimizev1beta2.Trial{},
+		0,
+		cache.Indexers{trialExperimentIndex: trialExperimentIndexFunc},
+	)
+	s.trials.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { s.notifyTrial(obj) },
+		UpdateFunc: func(_, obj interface{}) { s.notifyTrial(obj) },
+		DeleteFunc: func(obj interface{}) { s.notifyTrial(obj) },
+	})
+
+	return s, nil
+}
+
+// Start runs the informers and blocks until their caches are synchronized.
+// The store keeps running in the background until Stop is called.
+func (s *Store) Start(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	s.cancel = cancel
+
+	go s.experiments.Run(ctx.Done())
+	go s.trials.Run(ctx.Done())
+
+	if !cache.WaitForCacheSync(ctx.Done(), s.experiments.HasSynced, s.trials.HasSynced) {
+		return fmt.Errorf("livestatestore: timed out waiting for caches to sync")
+	}
+
+	return nil
+}
+
+// Stop terminates the informers and closes all subscriber channels.
+func (s *Store) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, t := range s.timers {
+		t.Stop()
+	}
+	s.timers = make(map[string]*time.Timer)
+
+	for _, chs := range s.subscribers {
+		for _, ch := range chs {
+			close(ch)
+		}
+	}
+	s.subscribers = make(map[string][]chan Event)
+}
+
+// Subscribe returns a channel that receives an event every time the observed
+// state of the experiment identified by experimentKey ("namespace/name")
+// changes. The channel is closed when the store is stopped.
+func (s *Store) Subscribe(experimentKey string) <-chan Event {
+	ch := make(chan Event, 1)
+
+	s.mu.Lock()
+	s.subscribers[experimentKey] = append(s.subscribers[experimentKey], ch)
+	s.mu.Unlock()
+
+	return ch
+}
+
+// notifyExperiment schedules a publish for the experiment identified by obj.
+func (s *Store) notifyExperiment(obj interface{}) {
+	exp, ok := obj.(*optimizev1beta2.Experiment)
+	if !ok {
+		return
+	}
+	s.schedulePublish(exp.Namespace + "/" + exp.Name)
+}
+
+// notifyTrial schedules a publish for the experiment that owns the trial obj.
+func (s *Store) notifyTrial(obj interface{}) {
+	keys, err := trialExperimentIndexFunc(obj)
+	if err != nil || len(keys) == 0 {
+		return
+	}
+	s.schedulePublish(keys[0])
+}
+
+// schedulePublish debounces repeated notifications for the same experiment so
+// a burst of informer events only produces a single publish.
+func (s *Store) schedulePublish(experimentKey string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.subscribers[experimentKey]; !ok {
+		return
+	}
+
+	if t, ok := s.timers[experimentKey]; ok {
+		t.Reset(debounceInterval)
+		return
+	}
+	s.timers[experimentKey] = time.AfterFunc(debounceInterval, func() { s.publish(experimentKey) })
+}
+
+// publish sends the current snapshot of state for an experiment to its subscribers.
+func (s *Store) publish(experimentKey string) {
+	s.mu.Lock()
+	delete(s.timers, experimentKey)
+	chs := append([]chan Event(nil), s.subscribers[experimentKey]...)
+	s.mu.Unlock()
+
+	if len(chs) == 0 {
+		return
+	}
+
+	if obj, ok, err := s.experiments.GetStore().GetByKey(experimentKey); err == nil && ok {
+		exp := obj.(*optimizev1beta2.Experiment)
+		if finished, failed := experimentFinished(exp); finished {
+			send(chs, ExperimentFinishedEvent{Failed: failed})
+			return
+		}
+	}
+
+	objs, err := s.trials.GetIndexer().ByIndex(trialExperimentIndex, experimentKey)
+	if err != nil {
+		return
+	}
+
+	trials := make([]runtime.Object, 0, len(objs))
+	for _, obj := range objs {
+		trials = append(trials, obj.(*optimizev1beta2.Trial))
+	}
+
+	nodes, err := sfio.ObjectSlice(trials).Read()
+	if err != nil {
+		return
+	}
+
+	send(chs, TrialsEvent{Trials: nodes})
+}
+
+// send delivers an event to each channel, replacing any event the subscriber
+// has not yet drained so subscribers only ever see the most recent state.
+func send(chs []chan Event, e Event) {
+	for _, ch := range chs {
+		select {
+		case ch <- e:
+		default:
+			select {
+			case <-ch:
+			default:
+			}
+			ch <- e
+		}
+	}
+}
+
+// experimentFinished reports whether the experiment has reached a terminal
+// condition and, if so, whether that condition was a failure.
+func experimentFinished(exp *optimizev1beta2.Experiment) (finished, failed bool) {
+	for _, c := range exp.Status.Conditions {
+		if c.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch c.Type {
+		case optimizev1beta2.ExperimentComplete:
+			finished = true
+		case optimizev1beta2.ExperimentFailed:
+			finished, failed = true, true
+		}
+	}
+	return finished, failed
+}
+
+// trialExperimentIndexFunc indexes a trial by the experiment it belongs to.
+func trialExperimentIndexFunc(obj interface{}) ([]string, error) {
+	trial, ok := obj.(*optimizev1beta2.Trial)
+	if !ok {
+		return nil, nil
+	}
+	name := trial.Labels[optimizev1beta2.LabelExperiment]
+	if name == "" {
+		return nil, nil
+	}
+	return []string{trial.Namespace + "/" + name}, nil
+}
+
+// newRESTClient builds a REST client capable of reading/watching the type of
+// obj using the supplied scheme; there is no generated clientset for the
+// Optimize CRDs, so the client is constructed directly against the scheme the
+// rest of the CLI already uses to convert between typed objects and YAML.
+func newRESTClient(config *rest.Config, scheme *runtime.Scheme, obj runtime.Object) (rest.Interface, error) {
+	gvks, _, err := scheme.ObjectKinds(obj)
+	if err != nil {
+		return nil, err
+	}
+	gv := gvks[0].GroupVersion()
+
+	cfg := *config
+	cfg.GroupVersion = &gv
+	cfg.APIPath = "/apis"
+	cfg.NegotiatedSerializer = serializer.NewCodecFactory(scheme).WithoutConversion()
+	if cfg.UserAgent == "" {
+		cfg.UserAgent = rest.DefaultKubernetesUserAgent()
+	}
+
+	return rest.RESTClientFor(&cfg)
+}
+
+// listWatch creates a ListWatch for the supplied resource, scoped to namespace
+// (an empty namespace watches the entire cluster).
+func listWatch(client rest.Interface, resource, namespace string, paramCodec runtime.ParameterCodec, newList func() runtime.Object) *cache.ListWatch {
+	return &cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			result := newList()
+			err := client.Get().
+				Namespace(namespace).
+				Resource(resource).
+				VersionedParams(&opts, paramCodec).
+				Do(context.Background()).
+				Into(result)
+			return result, err
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			opts.Watch = true
+			return client.Get().
+				Namespace(namespace).
+				Resource(resource).
+				VersionedParams(&opts, paramCodec).
+				Watch(context.Background())
+		},
+	}
+}
@@ -33,9 +33,11 @@ import (
 	"github.com/thestormforge/optimize-controller/v2/cli/internal/commander"
 	"github.com/thestormforge/optimize-controller/v2/cli/internal/commands/run/form"
 	"github.com/thestormforge/optimize-controller/v2/cli/internal/commands/run/internal"
+	"github.com/thestormforge/optimize-controller/v2/cli/internal/commands/run/livestatestore"
 	"github.com/thestormforge/optimize-controller/v2/cli/internal/kustomize"
 	"github.com/thestormforge/optimize-controller/v2/internal/experiment"
 	"github.com/thestormforge/optimize-controller/v2/internal/version"
+	"github.com/thestormforge/optimize-controller/v2/pkg/kubeclient"
 	experimentsv1alpha1 "github.com/thestormforge/optimize-go/pkg/api/experiments/v1alpha1"
 	"github.com/thestormforge/optimize-go/pkg/config"
 	"github.com/yujunz/go-getter"
@@ -63,6 +65,15 @@ type Options struct {
 	generatorModel      generatorModel
 	previewModel        previewModel
 	runModel            runModel
+
+	// liveState watches the running experiment and its trials; it is created
+	// once the experiment is created in the cluster and stopped on exit.
+	liveState       *livestatestore.Store
+	liveStateEvents <-chan livestatestore.Event
+
+	// kubeClient is used for direct cluster operations (creating the experiment, listing namespaces)
+	// instead of shelling out to `kubectl`; it is created on first use.
+	kubeClient kubeclient.Interface
 }
 
 // NewCommand creates a new command for running experiments.
@@ -132,12 +143,14 @@ func (o *Options) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case tea.KeyEsc:
 			o.maybeQuit = true
 		case tea.KeyCtrlC:
+			o.stopLiveState()
 			return o, tea.Quit
 
 		default:
 			if o.maybeQuit {
 				switch msg.String() {
 				case "y", "Y", "enter":
+					o.stopLiveState()
 					return o, tea.Quit
 				case "n", "N":
 					o.maybeQuit = false
@@ -198,21 +211,21 @@ func (o *Options) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case internal.ExperimentCreatedMsg:
 		if msg.Filename == "" {
-			// The experiment is in the cluster, start refreshing the trial status
-			cmds = append(cmds, o.refreshTrialsTick())
+			// The experiment is in the cluster, start watching its live state
+			cmds = append(cmds, o.watchLiveState)
 		}
 
 	case internal.TrialsMsg:
-		// If we got a status refresh, initiate another
-		cmds = append(cmds, o.refreshTrialsTick())
+		// Keep watching for the next change once we have processed this one
+		cmds = append(cmds, o.watchLiveState)
 
-	case internal.TrialsRefreshMsg:
-		// Refresh the trials list
-		cmds = append(cmds, o.refreshTrials)
+	case internal.ExperimentFinishedMsg:
+		o.stopLiveState()
 
 	case error:
 		// Handle errors so any command returning tea.Msg can just return an error
 		o.lastErr = msg
+		o.stopLiveState()
 		return o, tea.Quit
 
 	}
@@ -190,7 +190,7 @@ func (p *Poller) handleActivity(ctx context.Context, activity applications.Activ
 	}
 
 	var assembledApp *optimizeappsv1alpha1.Application
-	if assembledApp, err = server.APIApplicationToClusterApplication(apiApp, scenario); err != nil {
+	if assembledApp, err = server.APIApplicationToClusterApplication(apiApp, []applications.Scenario{scenario}); err != nil {
 		p.handleErrors(ctx, log, activity.URL, ActivityReasonGenerationFailed, "Failed to assemble application", err)
 		return
 	}
@@ -0,0 +1,272 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	optimizev1beta2 "github.com/thestormforge/optimize-controller/v2/api/v1beta2"
+	"github.com/thestormforge/optimize-controller/v2/internal/controller"
+	"github.com/thestormforge/optimize-controller/v2/internal/trial"
+	"github.com/thestormforge/optimize-controller/v2/internal/trial/drift"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// driftRecheckedAnnotation is a scratch annotation used only to make sure the pre-completion drift check
+// runs exactly once per trial; unlike AnnotationDrift it is not meant to be read by anything else.
+const driftRecheckedAnnotation = "stormforge.io/drift-rechecked"
+
+// driftLastCheckedAnnotation is a scratch annotation recording the last time a periodic (Spec.DriftCheckInterval)
+// drift check ran, so Reconcile knows when the next one is due; like driftRecheckedAnnotation it is not meant
+// to be read by anything else.
+const driftLastCheckedAnnotation = "stormforge.io/drift-last-checked"
+
+// DriftReconciler checks that a trial's patches are still in effect on their live targets
+type DriftReconciler struct {
+	client.Client
+	Log    logr.Logger
+	Scheme *runtime.Scheme
+
+	// Keep the raw API reader for fetching patch targets: like the ready reconciler, we may only have
+	// get/patch permissions on arbitrary customer defined resources, so we cannot use the caching client
+	apiReader client.Reader
+
+	// Cache remembers the diff last computed for each patch target so polling on Spec.DriftCheckInterval
+	// does not recompute a diff against a target that has not changed since the last check
+	Cache *drift.Cache
+}
+
+// +kubebuilder:rbac:groups=optimize.stormforge.io,resources=trials,verbs=get;list;watch;update
+
+// Reconcile checks a trial's patch targets for drift once they become ready, again before the trial is
+// allowed to complete, and (if Spec.DriftCheckInterval is set) on that interval for as long as the trial
+// run is in progress
+func (r *DriftReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	ctx := context.Background()
+	now := metav1.Now()
+
+	t := &optimizev1beta2.Trial{}
+	if err := r.Get(ctx, req.NamespacedName, t); err != nil || r.ignoreTrial(t) {
+		return ctrl.Result{}, controller.IgnoreNotFound(err)
+	}
+
+	if result, err := r.checkDrift(ctx, t, &now); result != nil {
+		return *result, err
+	}
+
+	return ctrl.Result{RequeueAfter: r.nextCheckDelay(t, &now)}, nil
+}
+
+// SetupWithManager registers a new drift reconciler with the supplied manager
+func (r *DriftReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.apiReader = mgr.GetAPIReader()
+	r.Cache = drift.NewCache()
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("drift").
+		For(&optimizev1beta2.Trial{}).
+		Complete(r)
+}
+
+// ignoreTrial determines which trial objects can be ignored by this reconciler
+func (r *DriftReconciler) ignoreTrial(t *optimizev1beta2.Trial) bool {
+	// Ignore deleted and failed trials
+	if !t.DeletionTimestamp.IsZero() || trial.CheckCondition(&t.Status, optimizev1beta2.TrialFailed, corev1.ConditionTrue) {
+		return true
+	}
+
+	// Ignore trials that have opted out of drift detection
+	if t.Spec.DriftPolicy == optimizev1beta2.DriftPolicyIgnore {
+		return true
+	}
+
+	// Nothing to check until the patches have actually been applied
+	if !trial.CheckCondition(&t.Status, optimizev1beta2.TrialPatched, corev1.ConditionTrue) {
+		return true
+	}
+
+	return false
+}
+
+// checkDrift runs the detector at the points drift matters: once the application is ready to take traffic,
+// again right before the trial would be marked complete (catching a revert that happened during the run
+// itself), and, if Spec.DriftCheckInterval is set, on that interval for as long as the run is in progress
+func (r *DriftReconciler) checkDrift(ctx context.Context, t *optimizev1beta2.Trial, probeTime *metav1.Time) (*ctrl.Result, error) {
+	checkedAfterReady := !trial.CheckCondition(&t.Status, optimizev1beta2.TrialAssignmentsApplied, corev1.ConditionUnknown)
+	readyToCheck := trial.CheckCondition(&t.Status, optimizev1beta2.TrialReady, corev1.ConditionTrue)
+	checkedBeforeComplete := trial.CheckCondition(&t.Status, optimizev1beta2.TrialObserved, corev1.ConditionTrue) &&
+		trial.CheckCondition(&t.Status, optimizev1beta2.TrialComplete, corev1.ConditionUnknown)
+	periodicCheckDue := checkedAfterReady && !checkedBeforeComplete && r.periodicCheckDue(t, probeTime)
+
+	switch {
+	case !checkedAfterReady && readyToCheck:
+		// The first pass, shortly after the application became ready
+	case checkedAfterReady && checkedBeforeComplete && t.Annotations[driftRecheckedAnnotation] == "":
+		// The second pass, right before the trial is allowed to complete; use an annotation so we only
+		// run it once per trial instead of on every reconcile while "observed" is true
+	case periodicCheckDue:
+		// A periodic pass while the trial run is still in progress
+	default:
+		return nil, nil
+	}
+
+	detector := &drift.Detector{Reader: r.apiReader, Cache: r.Cache}
+	fields, err := detector.Check(ctx, t)
+	if err != nil {
+		return nil, err
+	}
+
+	remediated := false
+	if len(fields) > 0 && t.Spec.DriftAutoRemediate {
+		if err := r.remediateDrift(ctx, t); err != nil {
+			return nil, err
+		}
+		remediated = true
+	}
+
+	applyDriftResult(t, fields, remediated, probeTime)
+	if checkedAfterReady {
+		if t.Annotations == nil {
+			t.Annotations = make(map[string]string)
+		}
+		t.Annotations[driftRecheckedAnnotation] = "true"
+	}
+	if t.Spec.DriftCheckInterval != nil {
+		if t.Annotations == nil {
+			t.Annotations = make(map[string]string)
+		}
+		t.Annotations[driftLastCheckedAnnotation] = probeTime.Format(time.RFC3339)
+	}
+
+	err = r.Update(ctx, t)
+	return controller.RequeueConflict(err)
+}
+
+// periodicCheckDue reports whether Spec.DriftCheckInterval has elapsed since the last periodic drift check
+func (r *DriftReconciler) periodicCheckDue(t *optimizev1beta2.Trial, now *metav1.Time) bool {
+	if t.Spec.DriftCheckInterval == nil {
+		return false
+	}
+
+	last, ok := t.Annotations[driftLastCheckedAnnotation]
+	if !ok {
+		return true
+	}
+	lastChecked, err := time.Parse(time.RFC3339, last)
+	if err != nil {
+		return true
+	}
+	return now.Sub(lastChecked) >= t.Spec.DriftCheckInterval.Duration
+}
+
+// nextCheckDelay returns how long the drift reconciler should wait before looking at t again in order to
+// honor Spec.DriftCheckInterval, or zero if periodic checking is not applicable
+func (r *DriftReconciler) nextCheckDelay(t *optimizev1beta2.Trial, now *metav1.Time) time.Duration {
+	if t.Spec.DriftCheckInterval == nil || r.ignoreTrial(t) {
+		return 0
+	}
+	if !trial.CheckCondition(&t.Status, optimizev1beta2.TrialReady, corev1.ConditionTrue) ||
+		trial.CheckCondition(&t.Status, optimizev1beta2.TrialComplete, corev1.ConditionTrue) {
+		return 0
+	}
+
+	last, ok := t.Annotations[driftLastCheckedAnnotation]
+	if !ok {
+		return t.Spec.DriftCheckInterval.Duration
+	}
+	lastChecked, err := time.Parse(time.RFC3339, last)
+	if err != nil {
+		return t.Spec.DriftCheckInterval.Duration
+	}
+
+	if d := t.Spec.DriftCheckInterval.Duration - now.Sub(lastChecked); d > 0 {
+		return d
+	}
+	return t.Spec.DriftCheckInterval.Duration
+}
+
+// remediateDrift re-applies the trial's patches to correct drift that was detected on one or more of their
+// targets, mirroring the patch application done by PatchReconciler.applyPatches
+func (r *DriftReconciler) remediateDrift(ctx context.Context, t *optimizev1beta2.Trial) error {
+	for i := range t.Status.PatchOperations {
+		p := &t.Status.PatchOperations[i]
+		if p.AttemptsRemaining != 0 || trial.IsTrialJobReference(t, &p.TargetRef) {
+			continue
+		}
+
+		u := &unstructured.Unstructured{}
+		u.SetName(p.TargetRef.Name)
+		u.SetNamespace(p.TargetRef.Namespace)
+		u.SetGroupVersionKind(p.TargetRef.GroupVersionKind())
+		if err := r.Patch(ctx, u, client.RawPatch(p.PatchType, p.Data)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyDriftResult records the outcome of a drift check on the trial's status, honoring the configured
+// drift policy unless the drift was already corrected by remediateDrift
+func applyDriftResult(t *optimizev1beta2.Trial, fields []drift.Field, remediated bool, probeTime *metav1.Time) {
+	if len(fields) == 0 {
+		trial.ApplyCondition(&t.Status, optimizev1beta2.TrialAssignmentsApplied, corev1.ConditionTrue, "", "", probeTime)
+		delete(t.Annotations, optimizev1beta2.AnnotationDrift)
+		return
+	}
+
+	sort.Slice(fields, func(i, j int) bool { return fields[i].String() < fields[j].String() })
+
+	lines := make([]string, len(fields))
+	for i := range fields {
+		lines[i] = fields[i].String()
+	}
+	message := strings.Join(lines, "; ")
+
+	if t.Annotations == nil {
+		t.Annotations = make(map[string]string)
+	}
+	if diff, err := json.Marshal(fields); err == nil {
+		t.Annotations[optimizev1beta2.AnnotationDrift] = string(diff)
+	}
+
+	if remediated {
+		trial.ApplyCondition(&t.Status, optimizev1beta2.TrialAssignmentsApplied, corev1.ConditionTrue, "DriftRemediated", message, probeTime)
+		return
+	}
+
+	policy := t.Spec.DriftPolicy
+	if policy == "" {
+		policy = optimizev1beta2.DriftPolicyFail
+	}
+
+	switch policy {
+	case optimizev1beta2.DriftPolicyWarn:
+		trial.ApplyCondition(&t.Status, optimizev1beta2.TrialAssignmentsApplied, corev1.ConditionTrue, "DriftDetected", message, probeTime)
+	default:
+		trial.ApplyCondition(&t.Status, optimizev1beta2.TrialAssignmentsApplied, corev1.ConditionFalse, "DriftDetected", message, probeTime)
+		trial.ApplyCondition(&t.Status, optimizev1beta2.TrialFailed, corev1.ConditionTrue, "DriftDetected", message, probeTime)
+	}
+}
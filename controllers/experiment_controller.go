@@ -18,6 +18,7 @@ package controllers
 
 import (
 	"context"
+	"time"
 
 	"github.com/go-logr/logr"
 	optimizev1beta2 "github.com/thestormforge/optimize-controller/v2/api/v1beta2"
@@ -25,8 +26,10 @@ import (
 	"github.com/thestormforge/optimize-controller/v2/internal/experiment"
 	"github.com/thestormforge/optimize-controller/v2/internal/meta"
 	"github.com/thestormforge/optimize-controller/v2/internal/trial"
+	"github.com/thestormforge/optimize-controller/v2/internal/trial/ttl"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
@@ -38,6 +41,10 @@ import (
 type ExperimentReconciler struct {
 	client.Client
 	Log logr.Logger
+
+	// ttlScheduler wakes a reconcile exactly when a trial's cleanup TTL expires, instead of relying on
+	// some unrelated reconcile event happening to occur near the deadline
+	ttlScheduler *ttl.Scheduler
 }
 
 // +kubebuilder:rbac:groups=optimize.stormforge.io,resources=experiments;experiments/finalizers,verbs=get;list;watch;update
@@ -72,10 +79,12 @@ func (r *ExperimentReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error)
 }
 
 func (r *ExperimentReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.ttlScheduler = ttl.NewScheduler()
 	return ctrl.NewControllerManagedBy(mgr).
 		Named("experiment").
 		For(&optimizev1beta2.Experiment{}).
 		Watches(&source.Kind{Type: &optimizev1beta2.Trial{}}, &handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(trialToExperimentRequest)}).
+		Watches(&source.Channel{Source: r.ttlScheduler.Events()}, &handler.EnqueueRequestForObject{}).
 		Complete(r)
 }
 
@@ -139,6 +148,7 @@ func (r *ExperimentReconciler) updateTrialStatus(ctx context.Context, trialList
 
 // cleanupTrials will delete any trials whose TTL has expired or are active past
 func (r *ExperimentReconciler) cleanupTrials(ctx context.Context, exp *optimizev1beta2.Experiment, trialList *optimizev1beta2.TrialList) (*ctrl.Result, error) {
+	var nextCleanup time.Time
 	for i := range trialList.Items {
 		t := &trialList.Items[i]
 
@@ -153,8 +163,24 @@ func (r *ExperimentReconciler) cleanupTrials(ctx context.Context, exp *optimizev
 			if err := r.Delete(ctx, t); err != nil {
 				return &ctrl.Result{}, err
 			}
+			continue
+		}
+
+		// Track the earliest outstanding TTL deadline so we can arm a reconcile for it below
+		if deadline, ok := trial.NextCleanup(t); ok && (nextCleanup.IsZero() || deadline.Before(nextCleanup)) {
+			nextCleanup = deadline
 		}
 	}
+
+	// Make sure we get reconciled again right when the next trial becomes eligible for cleanup, instead of
+	// waiting on an unrelated event to happen to fire near the deadline
+	key := types.NamespacedName{Namespace: exp.Namespace, Name: exp.Name}
+	if nextCleanup.IsZero() {
+		r.ttlScheduler.Cancel(key)
+	} else {
+		r.ttlScheduler.Schedule(key, nextCleanup)
+	}
+
 	return nil, nil
 }
 
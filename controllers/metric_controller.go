@@ -18,6 +18,7 @@ package controllers
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"math"
 	"strconv"
@@ -180,7 +181,7 @@ func (r *MetricReconciler) collectMetrics(ctx context.Context, t *optimizev1beta
 		}
 
 		// Capture the metric value
-		value, valueError, err := metric.CaptureMetric(ctx, log, t, m, target)
+		value, valueError, err := metric.CaptureMetric(ctx, log, r.Client, t, m, target)
 		if err != nil {
 			return r.collectionAttempt(ctx, log, t, v, probeTime, err)
 		}
@@ -199,7 +200,7 @@ func (r *MetricReconciler) collectMetrics(ctx context.Context, t *optimizev1beta
 	if !trial.IsBaseline(t, exp) {
 		for i := range t.Spec.Values {
 			v := &t.Spec.Values[i]
-			if err := validation.CheckMetricBounds(metrics[v.Name], v); err != nil {
+			if err := validation.CheckMetricBounds(metrics[v.Name], t.Labels[optimizev1beta2.LabelScenario], v); err != nil {
 				trial.ApplyCondition(&t.Status, optimizev1beta2.TrialFailed, corev1.ConditionTrue, "MetricBound", err.Error(), probeTime)
 				err := r.Update(ctx, t)
 				return controller.RequeueConflict(err)
@@ -220,10 +221,21 @@ func (r *MetricReconciler) collectionAttempt(ctx context.Context, log logr.Logge
 		return &ctrl.Result{RequeueAfter: merr.RetryAfter}, nil
 	}
 
-	// Update the number of remaining attempts
-	v.AttemptsRemaining--
-	if err == nil || v.AttemptsRemaining < 0 {
+	// A fired alert fails the trial immediately, regardless of how many attempts remain
+	if aerr, ok := err.(*metric.AlertFiredError); ok {
 		v.AttemptsRemaining = 0
+		if t.Annotations == nil {
+			t.Annotations = make(map[string]string)
+		}
+		if diff, jerr := json.Marshal(aerr.Annotations); jerr == nil {
+			t.Annotations[optimizev1beta2.AnnotationAlert] = string(diff)
+		}
+	} else {
+		// Update the number of remaining attempts
+		v.AttemptsRemaining--
+		if err == nil || v.AttemptsRemaining < 0 {
+			v.AttemptsRemaining = 0
+		}
 	}
 
 	// Update the probe time and ensure that trial observed is still explicitly false (i.e. we have started observation but it is not complete)
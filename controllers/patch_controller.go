@@ -18,7 +18,10 @@ package controllers
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"sort"
+	"time"
 
 	"github.com/go-logr/logr"
 	optimizev1beta2 "github.com/thestormforge/optimize-controller/v2/api/v1beta2"
@@ -27,15 +30,31 @@ import (
 	"github.com/thestormforge/optimize-controller/v2/internal/ready"
 	"github.com/thestormforge/optimize-controller/v2/internal/template"
 	"github.com/thestormforge/optimize-controller/v2/internal/trial"
+	"github.com/thestormforge/optimize-controller/v2/internal/trial/drift"
 	"github.com/thestormforge/optimize-controller/v2/internal/validation"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
+// rollbackSnapshotInlineLimit is the largest OriginalData we will store inline on a PatchOperation before
+// externalizing it to a ConfigMap instead (etcd objects have a practical size ceiling well under 1MiB, and
+// a trial may accumulate a snapshot per patch target)
+const rollbackSnapshotInlineLimit = 8 * 1024
+
+// defaultRollbackAttempts is the retry budget given to a rollback, the same way createReadinessCheck gives
+// patch readiness a fixed attempt budget
+const defaultRollbackAttempts = 3
+
+// defaultRollbackTimeoutSeconds bounds how long rollback is retried if Spec.RollbackTimeoutSeconds is unset
+const defaultRollbackTimeoutSeconds = int32(300)
+
 // PatchReconciler reconciles the patches on a Trial object
 type PatchReconciler struct {
 	client.Client
@@ -45,6 +64,8 @@ type PatchReconciler struct {
 
 // +kubebuilder:rbac:groups=optimize.stormforge.io,resources=experiments,verbs=get;list;watch
 // +kubebuilder:rbac:groups=optimize.stormforge.io,resources=trials,verbs=get;list;watch;update
+// +kubebuilder:rbac:groups=work.open-cluster-management.io,resources=manifestworks,verbs=get;list;watch;create;update
+// +kubebuilder:rbac:groups=cluster.open-cluster-management.io,resources=placementdecisions,verbs=get;list;watch
 
 // Reconcile inspects a trial to see if patches need to be applied. The "trial patched" status condition
 // is used to control what actions need to be taken. If the status is "unknown" then the experiment is fetched
@@ -68,6 +89,10 @@ func (r *PatchReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 		return *result, err
 	}
 
+	if result, err := r.rollbackPatches(ctx, t, &now); result != nil {
+		return *result, err
+	}
+
 	return ctrl.Result{}, nil
 }
 
@@ -86,9 +111,9 @@ func (r *PatchReconciler) ignoreTrial(t *optimizev1beta2.Trial) bool {
 		return true
 	}
 
-	// Ignore failed trials
+	// Failed trials are only relevant if there is still rollback work to do
 	if trial.CheckCondition(&t.Status, optimizev1beta2.TrialFailed, corev1.ConditionTrue) {
-		return true
+		return !hasPendingRollback(t)
 	}
 
 	// Ignore uninitialized trials
@@ -102,9 +127,9 @@ func (r *PatchReconciler) ignoreTrial(t *optimizev1beta2.Trial) bool {
 		return true
 	}
 
-	// Ignore patched trials
+	// Patched trials are only relevant once more if there is still rollback work to do
 	if trial.CheckCondition(&t.Status, optimizev1beta2.TrialPatched, corev1.ConditionTrue) {
-		return true
+		return !hasPendingRollback(t)
 	}
 
 	// Reconcile everything else
@@ -181,6 +206,11 @@ func (r *PatchReconciler) applyPatches(ctx context.Context, t *optimizev1beta2.T
 		return nil, nil
 	}
 
+	// Render patch previews instead of actually applying anything when dry run is requested
+	if isDryRun(t) {
+		return r.previewPatches(ctx, t)
+	}
+
 	// Iterate over the patches, looking for remaining attempts
 	for i := range t.Status.PatchOperations {
 		p := &t.Status.PatchOperations[i]
@@ -188,25 +218,40 @@ func (r *PatchReconciler) applyPatches(ctx context.Context, t *optimizev1beta2.T
 			continue
 		}
 
-		// Construct a patch on an unstructured object
-		// RBAC: We assume that we have "patch" permission from a customer defined role so we do not limit what types we can patch
-		u := &unstructured.Unstructured{}
-		u.SetName(p.TargetRef.Name)
-		u.SetNamespace(p.TargetRef.Namespace)
-		u.SetGroupVersionKind(p.TargetRef.GroupVersionKind())
-		if err := r.Patch(ctx, u, client.RawPatch(p.PatchType, p.Data)); err != nil {
+		// Snapshot the target's pre-patch state if it will need to be restored on rollback
+		if p.Rollback != "" && p.Rollback != optimizev1beta2.RollbackNever && len(p.OriginalData) == 0 && !hasPatchSnapshot(t, i, &p.TargetRef) {
+			if err := r.snapshotOriginal(ctx, t, i, p); err != nil {
+				return &ctrl.Result{}, err
+			}
+		}
+
+		// Apply the patch, dispatching to a remote ManifestWork-backed applier when the operation has a
+		// PlacementRef instead of always assuming the target lives on the local cluster
+		applier := patch.ApplierFor(r.Client, p)
+		var requeueAfter time.Duration
+		if err := applier.Apply(ctx, t, p); err != nil {
 			p.AttemptsRemaining = p.AttemptsRemaining - 1
 			if p.AttemptsRemaining == 0 {
 				// There are no remaining patch attempts remaining, fail the trial
 				trial.ApplyCondition(&t.Status, optimizev1beta2.TrialFailed, corev1.ConditionTrue, "PatchFailed", err.Error(), probeTime)
 			}
-		} else {
+		} else if isReady, err := applier.Ready(ctx, t, p); err != nil {
+			return &ctrl.Result{}, err
+		} else if isReady {
 			p.AttemptsRemaining = 0
+		} else {
+			// The patch was delivered but has not taken effect on every selected cluster yet
+			requeueAfter = 5 * time.Second
 		}
 
 		// Update the patch operation status
-		err := r.Update(ctx, t)
-		return controller.RequeueConflict(err)
+		if err := r.Update(ctx, t); err != nil {
+			return controller.RequeueConflict(err)
+		}
+		if requeueAfter > 0 {
+			return &ctrl.Result{RequeueAfter: requeueAfter}, nil
+		}
+		return &ctrl.Result{}, nil
 	}
 
 	// We made it through all of the patches without needing additional changes
@@ -215,6 +260,256 @@ func (r *PatchReconciler) applyPatches(ctx context.Context, t *optimizev1beta2.T
 	return controller.RequeueConflict(err)
 }
 
+// isDryRun reports whether t has opted into patch previews instead of actually being patched
+func isDryRun(t *optimizev1beta2.Trial) bool {
+	return t.Annotations[optimizev1beta2.AnnotationDryRun] == "true"
+}
+
+// previewPatches submits every patch operation that has not yet been previewed as a server-side dry run
+// and records the result under Status.PatchPreviews. Unlike applyPatches, it never advances TrialPatched
+// to true: a dry run trial is only ever meant to produce previews, never to actually execute.
+func (r *PatchReconciler) previewPatches(ctx context.Context, t *optimizev1beta2.Trial) (*ctrl.Result, error) {
+	for i := range t.Status.PatchOperations {
+		p := &t.Status.PatchOperations[i]
+		if hasPatchPreview(t, &p.TargetRef) {
+			continue
+		}
+
+		u := &unstructured.Unstructured{}
+		u.SetName(p.TargetRef.Name)
+		u.SetNamespace(p.TargetRef.Namespace)
+		u.SetGroupVersionKind(p.TargetRef.GroupVersionKind())
+		if err := r.Patch(ctx, u, client.RawPatch(p.PatchType, p.Data), &client.PatchOptions{DryRun: []string{metav1.DryRunAll}}); err != nil {
+			return &ctrl.Result{}, err
+		}
+
+		rendered, err := json.Marshal(u.Object)
+		if err != nil {
+			return &ctrl.Result{}, err
+		}
+
+		t.Status.PatchPreviews = append(t.Status.PatchPreviews, optimizev1beta2.PatchPreview{TargetRef: p.TargetRef, Rendered: rendered})
+		err = r.Update(ctx, t)
+		return controller.RequeueConflict(err)
+	}
+
+	// Every patch operation has a preview recorded; there is nothing further for a dry run trial to do
+	return &ctrl.Result{}, nil
+}
+
+// hasPatchPreview returns true if a dry run preview has already been recorded for ref
+func hasPatchPreview(t *optimizev1beta2.Trial, ref *corev1.ObjectReference) bool {
+	for i := range t.Status.PatchPreviews {
+		if t.Status.PatchPreviews[i].TargetRef == *ref {
+			return true
+		}
+	}
+	return false
+}
+
+// snapshotOriginal captures p's pre-patch state (as a JSON-Patch that would restore it) so its Rollback
+// policy can be honored later, storing it inline on p.OriginalData or, if it is too large, externalizing it
+// to a ConfigMap referenced from t.Status.PatchSnapshots. If the target cannot be read (for example because
+// it does not exist yet) no snapshot is captured; rollback will simply have nothing to restore for it.
+// index is p's position in t.Status.PatchOperations, recorded on the externalized snapshot so another
+// operation sharing the same TargetRef does not mistake this snapshot for its own.
+func (r *PatchReconciler) snapshotOriginal(ctx context.Context, t *optimizev1beta2.Trial, index int, p *optimizev1beta2.PatchOperation) error {
+	live := &unstructured.Unstructured{}
+	live.SetGroupVersionKind(p.TargetRef.GroupVersionKind())
+	key := client.ObjectKey{Namespace: p.TargetRef.Namespace, Name: p.TargetRef.Name}
+	if err := r.Get(ctx, key, live); err != nil {
+		return nil
+	}
+
+	original, err := drift.OriginalPatch(p, live)
+	if err != nil || len(original) == 0 {
+		return err
+	}
+
+	if len(original) <= rollbackSnapshotInlineLimit {
+		p.OriginalData = original
+		return nil
+	}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: fmt.Sprintf("%s-rollback-", t.Name),
+			Namespace:    t.Namespace,
+		},
+		Data: map[string]string{"originalData": string(original)},
+	}
+	if err := controllerutil.SetControllerReference(t, cm, r.Scheme); err != nil {
+		return err
+	}
+	if err := r.Create(ctx, cm); err != nil {
+		return err
+	}
+
+	t.Status.PatchSnapshots = append(t.Status.PatchSnapshots, optimizev1beta2.PatchSnapshot{
+		PatchIndex:   index,
+		TargetRef:    p.TargetRef,
+		ConfigMapRef: corev1.LocalObjectReference{Name: cm.Name},
+	})
+	return nil
+}
+
+// hasPatchSnapshot returns true if a ConfigMap-backed snapshot has already been externalized for the
+// patch operation at index. Both index and ref must match, not just index: a PatchSnapshot recorded
+// by a controller version prior to the addition of PatchIndex decodes with PatchIndex defaulting to
+// zero, and requiring ref to also match keeps such a stale entry from being mistaken for operation 0's
+// snapshot unless it actually targets the same object.
+func hasPatchSnapshot(t *optimizev1beta2.Trial, index int, ref *corev1.ObjectReference) bool {
+	for i := range t.Status.PatchSnapshots {
+		if t.Status.PatchSnapshots[i].PatchIndex == index && t.Status.PatchSnapshots[i].TargetRef == *ref {
+			return true
+		}
+	}
+	return false
+}
+
+// originalData returns the rollback patch captured for p (found at index within
+// t.Status.PatchOperations), loading it from a referenced ConfigMap if the snapshot was too large to
+// keep inline
+func (r *PatchReconciler) originalData(ctx context.Context, t *optimizev1beta2.Trial, index int, p *optimizev1beta2.PatchOperation) ([]byte, error) {
+	if len(p.OriginalData) > 0 {
+		return p.OriginalData, nil
+	}
+
+	for i := range t.Status.PatchSnapshots {
+		snap := &t.Status.PatchSnapshots[i]
+		if snap.PatchIndex != index || snap.TargetRef != p.TargetRef {
+			continue
+		}
+
+		cm := &corev1.ConfigMap{}
+		if err := r.Get(ctx, client.ObjectKey{Namespace: t.Namespace, Name: snap.ConfigMapRef.Name}, cm); err != nil {
+			return nil, err
+		}
+		return []byte(cm.Data["originalData"]), nil
+	}
+
+	return nil, nil
+}
+
+// rollbackApplies determines whether p's Rollback policy calls for it to be restored given how the trial
+// that applied it turned out
+func rollbackApplies(p *optimizev1beta2.PatchOperation, t *optimizev1beta2.Trial) bool {
+	switch p.Rollback {
+	case optimizev1beta2.RollbackAlways:
+		return true
+	case optimizev1beta2.RollbackOnFailure:
+		return trial.CheckCondition(&t.Status, optimizev1beta2.TrialFailed, corev1.ConditionTrue)
+	default:
+		return false
+	}
+}
+
+// hasPendingRollback returns true if the trial has reached a terminal state and has at least one patch
+// operation whose Rollback policy applies and has not yet been restored
+func hasPendingRollback(t *optimizev1beta2.Trial) bool {
+	terminal := trial.CheckCondition(&t.Status, optimizev1beta2.TrialComplete, corev1.ConditionTrue) ||
+		trial.CheckCondition(&t.Status, optimizev1beta2.TrialFailed, corev1.ConditionTrue)
+	if !terminal || trial.CheckCondition(&t.Status, optimizev1beta2.TrialRolledBack, corev1.ConditionTrue) {
+		return false
+	}
+
+	for i := range t.Status.PatchOperations {
+		p := &t.Status.PatchOperations[i]
+		if rollbackApplies(p, t) && !p.RolledBack {
+			return true
+		}
+	}
+	return false
+}
+
+// terminalTransitionTime returns when the trial first reached a terminal state, or nil if it has not
+func terminalTransitionTime(t *optimizev1beta2.Trial) *metav1.Time {
+	for i := range t.Status.Conditions {
+		c := &t.Status.Conditions[i]
+		if c.Status != corev1.ConditionTrue {
+			continue
+		}
+		if c.Type == optimizev1beta2.TrialComplete || c.Type == optimizev1beta2.TrialFailed {
+			return &c.LastTransitionTime
+		}
+	}
+	return nil
+}
+
+// rollbackPatches restores the pre-patch state of any patch target whose Rollback policy applies once the
+// trial reaches a terminal state, reusing the same AttemptsRemaining retry machinery applyPatches uses for
+// the forward patch
+func (r *PatchReconciler) rollbackPatches(ctx context.Context, t *optimizev1beta2.Trial, probeTime *metav1.Time) (*ctrl.Result, error) {
+	if !hasPendingRollback(t) {
+		return nil, nil
+	}
+
+	if since := terminalTransitionTime(t); since != nil {
+		timeout := defaultRollbackTimeoutSeconds
+		if t.Spec.RollbackTimeoutSeconds != nil {
+			timeout = *t.Spec.RollbackTimeoutSeconds
+		}
+		if probeTime.Sub(since.Time) > time.Duration(timeout)*time.Second {
+			// Give up on whatever is left rather than retrying indefinitely
+			for i := range t.Status.PatchOperations {
+				t.Status.PatchOperations[i].RolledBack = true
+			}
+			trial.ApplyCondition(&t.Status, optimizev1beta2.TrialRolledBack, corev1.ConditionFalse, "RollbackTimeout", "gave up restoring patch targets within the rollback timeout", probeTime)
+			err := r.Update(ctx, t)
+			return controller.RequeueConflict(err)
+		}
+	}
+
+	for i := range t.Status.PatchOperations {
+		p := &t.Status.PatchOperations[i]
+		if p.RolledBack || !rollbackApplies(p, t) {
+			continue
+		}
+
+		original, err := r.originalData(ctx, t, i, p)
+		if err != nil {
+			return &ctrl.Result{}, err
+		}
+		if len(original) == 0 {
+			// Nothing was ever captured for this target, there is nothing to restore
+			p.RolledBack = true
+			continue
+		}
+
+		if p.AttemptsRemaining == 0 {
+			p.AttemptsRemaining = defaultRollbackAttempts
+		}
+
+		u := &unstructured.Unstructured{}
+		u.SetName(p.TargetRef.Name)
+		u.SetNamespace(p.TargetRef.Namespace)
+		u.SetGroupVersionKind(p.TargetRef.GroupVersionKind())
+		if err := r.Patch(ctx, u, client.RawPatch(types.JSONPatchType, original)); err != nil {
+			if apierrors.IsNotFound(err) {
+				// The target was deleted between apply and rollback, there is nothing left to restore
+				p.RolledBack = true
+				trial.ApplyCondition(&t.Status, optimizev1beta2.TrialRolledBack, corev1.ConditionFalse, "RollbackTargetMissing", fmt.Sprintf("%s/%s no longer exists, skipping rollback", p.TargetRef.Kind, p.TargetRef.Name), probeTime)
+			} else {
+				p.AttemptsRemaining--
+				if p.AttemptsRemaining == 0 {
+					p.RolledBack = true
+					trial.ApplyCondition(&t.Status, optimizev1beta2.TrialRolledBack, corev1.ConditionFalse, "RollbackFailed", fmt.Sprintf("%s/%s: %s", p.TargetRef.Kind, p.TargetRef.Name, err.Error()), probeTime)
+				}
+			}
+		} else {
+			p.RolledBack = true
+		}
+
+		err = r.Update(ctx, t)
+		return controller.RequeueConflict(err)
+	}
+
+	// Every eligible patch operation had nothing to restore and was resolved without an API call above
+	trial.ApplyCondition(&t.Status, optimizev1beta2.TrialRolledBack, corev1.ConditionTrue, "", "", probeTime)
+	err := r.Update(ctx, t)
+	return controller.RequeueConflict(err)
+}
+
 // createReadinessCheck creates a readiness check for a patch operation
 func (r *PatchReconciler) createReadinessCheck(t *optimizev1beta2.Trial, ref *corev1.ObjectReference, readinessGates []optimizev1beta2.PatchReadinessGate) (*optimizev1beta2.ReadinessCheck, error) {
 	// Do not create a readiness check on the trial job or if there is already an explicit readiness gate
@@ -0,0 +1,108 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	optimizev1beta2 "github.com/thestormforge/optimize-controller/v2/api/v1beta2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// TestPatchReconciler_OriginalData_SamePatchTarget verifies that two patch operations targeting the
+// same object each get back their own externalized snapshot, keyed by their position in
+// Status.PatchOperations rather than by the (shared) TargetRef.
+func TestPatchReconciler_OriginalData_SamePatchTarget(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+
+	cm0 := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "rollback-0", Namespace: "default"},
+		Data:       map[string]string{"originalData": `[{"op":"replace","path":"/a","value":"orig-a"}]`},
+	}
+	cm1 := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "rollback-1", Namespace: "default"},
+		Data:       map[string]string{"originalData": `[{"op":"replace","path":"/b","value":"orig-b"}]`},
+	}
+
+	ref := corev1.ObjectReference{APIVersion: "v1", Kind: "ConfigMap", Namespace: "default", Name: "target"}
+	trial := &optimizev1beta2.Trial{
+		Status: optimizev1beta2.TrialStatus{
+			PatchOperations: []optimizev1beta2.PatchOperation{
+				{TargetRef: ref},
+				{TargetRef: ref},
+			},
+			PatchSnapshots: []optimizev1beta2.PatchSnapshot{
+				{PatchIndex: 0, TargetRef: ref, ConfigMapRef: corev1.LocalObjectReference{Name: cm0.Name}},
+				{PatchIndex: 1, TargetRef: ref, ConfigMapRef: corev1.LocalObjectReference{Name: cm1.Name}},
+			},
+		},
+	}
+
+	r := &PatchReconciler{Client: fake.NewFakeClientWithScheme(scheme, cm0, cm1), Scheme: scheme}
+
+	if !hasPatchSnapshot(trial, 0, &ref) || !hasPatchSnapshot(trial, 1, &ref) {
+		t.Fatal("expected a snapshot to be found for both patch operations")
+	}
+	if hasPatchSnapshot(trial, 2, &ref) {
+		t.Fatal("expected no snapshot for a patch operation index with no recorded snapshot")
+	}
+
+	got0, err := r.originalData(context.TODO(), trial, 0, &trial.Status.PatchOperations[0])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(got0) != cm0.Data["originalData"] {
+		t.Fatalf("expected patch operation 0 to get its own snapshot, got %q", got0)
+	}
+
+	got1, err := r.originalData(context.TODO(), trial, 1, &trial.Status.PatchOperations[1])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(got1) != cm1.Data["originalData"] {
+		t.Fatalf("expected patch operation 1 to get its own snapshot (not operation 0's), got %q", got1)
+	}
+}
+
+// TestHasPatchSnapshot_StalePatchIndex verifies that a PatchSnapshot recorded by a controller version
+// prior to the addition of PatchIndex (and therefore decoding with PatchIndex defaulting to zero) is
+// not mistaken for a snapshot of operation 0 unless it also targets the same object.
+func TestHasPatchSnapshot_StalePatchIndex(t *testing.T) {
+	ref := corev1.ObjectReference{APIVersion: "v1", Kind: "ConfigMap", Namespace: "default", Name: "target"}
+	otherRef := corev1.ObjectReference{APIVersion: "v1", Kind: "ConfigMap", Namespace: "default", Name: "other"}
+	trial := &optimizev1beta2.Trial{
+		Status: optimizev1beta2.TrialStatus{
+			PatchOperations: []optimizev1beta2.PatchOperation{
+				{TargetRef: ref},
+			},
+			PatchSnapshots: []optimizev1beta2.PatchSnapshot{
+				// No PatchIndex set: as if persisted before the field existed, so it decodes as 0.
+				{TargetRef: otherRef, ConfigMapRef: corev1.LocalObjectReference{Name: "rollback-stale"}},
+			},
+		},
+	}
+
+	if hasPatchSnapshot(trial, 0, &ref) {
+		t.Fatal("expected a stale snapshot for a different target not to match operation 0")
+	}
+}
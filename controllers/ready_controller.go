@@ -128,6 +128,7 @@ func (r *ReadyReconciler) evaluateReadinessChecks(ctx context.Context, t *optimi
 			},
 			Selector:            c.Selector,
 			ConditionTypes:      c.ConditionTypes,
+			Expressions:         c.Expressions,
 			InitialDelaySeconds: c.InitialDelaySeconds,
 			PeriodSeconds:       c.PeriodSeconds,
 			AttemptsRemaining:   c.FailureThreshold,
@@ -321,6 +322,11 @@ func (rc *readinessChecker) check(ctx context.Context, c *optimizev1beta2.Readin
 		if !ok || err != nil {
 			break
 		}
+
+		msg, ok, err = rc.checker.CheckExpressions(&ul.Items[i], c.Expressions)
+		if !ok || err != nil {
+			break
+		}
 	}
 
 	// If a check is missing it's kind, just mark it as completed (e.g. if this
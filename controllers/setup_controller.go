@@ -18,6 +18,7 @@ package controllers
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -25,12 +26,17 @@ import (
 	"github.com/thestormforge/optimize-controller/v2/internal/controller"
 	"github.com/thestormforge/optimize-controller/v2/internal/meta"
 	"github.com/thestormforge/optimize-controller/v2/internal/setup"
+	"github.com/thestormforge/optimize-controller/v2/internal/setup/bundle"
+	"github.com/thestormforge/optimize-controller/v2/internal/setup/helm"
 	"github.com/thestormforge/optimize-controller/v2/internal/trial"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/cli"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -41,6 +47,10 @@ type SetupReconciler struct {
 	client.Client
 	Log    logr.Logger
 	Scheme *runtime.Scheme
+
+	// RESTConfig is used to install and uninstall Helm chart setup tasks in-process; if it is nil,
+	// Helm chart setup tasks fall back to being skipped (nothing will be installed for them).
+	RESTConfig *rest.Config
 }
 
 // +kubebuilder:rbac:groups=optimize.stormforge.io,resources=trials;trials/finalizers,verbs=get;list;watch;update
@@ -198,10 +208,43 @@ func (r *SetupReconciler) createSetupJob(ctx context.Context, t *optimizev1beta2
 
 	// Create a setup job if necessary
 	if mode != "" {
-		job, err := setup.NewJob(t, mode)
-		if err != nil {
+		// Helm chart tasks are reconciled in-process rather than through the setup job
+		if err := r.syncHelmSetupTasks(ctx, t, mode); err != nil {
+			trial.ApplyCondition(&t.Status, optimizev1beta2.TrialFailed, corev1.ConditionTrue, "HelmSetupFailed", err.Error(), probeTime)
+			return &ctrl.Result{}, r.Update(ctx, t)
+		}
+
+		// SetupBundle releases are reconciled in-process, in dependency order, same as Helm chart tasks
+		if err := r.syncSetupBundle(ctx, t, mode); err != nil {
+			trial.ApplyCondition(&t.Status, optimizev1beta2.TrialFailed, corev1.ConditionTrue, "SetupBundleFailed", err.Error(), probeTime)
+			return &ctrl.Result{}, r.Update(ctx, t)
+		}
+
+		ns := &corev1.Namespace{}
+		if err := r.Get(ctx, client.ObjectKey{Name: t.Namespace}, ns); err != nil {
 			return &ctrl.Result{}, err
 		}
+
+		job, err := setup.NewJob(t, mode, ns)
+		if err != nil {
+			// The setup task or setup volumes violate the enforced Pod Security profile: surface it on
+			// the trial instead of letting the API server reject the pod with a less helpful error
+			trial.ApplyCondition(&t.Status, optimizev1beta2.TrialFailed, corev1.ConditionTrue, "PodSecurityViolation", err.Error(), probeTime)
+			return &ctrl.Result{}, r.Update(ctx, t)
+		}
+
+		// If every task for this mode was a Helm chart task, there is nothing left for the job to do:
+		// mark the corresponding condition complete directly instead of creating an empty job
+		if len(job.Spec.Template.Spec.Containers) == 0 {
+			conditionType := optimizev1beta2.TrialSetupCreated
+			if mode == setup.ModeDelete {
+				conditionType = optimizev1beta2.TrialSetupDeleted
+			}
+			trial.ApplyCondition(&t.Status, conditionType, corev1.ConditionTrue, "", "", probeTime)
+			err := r.Update(ctx, t)
+			return controller.RequeueConflict(err)
+		}
+
 		if err := controllerutil.SetControllerReference(t, job, r.Scheme); err != nil {
 			return &ctrl.Result{}, err
 		}
@@ -220,6 +263,139 @@ func (r *SetupReconciler) createSetupJob(ctx context.Context, t *optimizev1beta2
 	return nil, nil
 }
 
+// syncHelmSetupTasks installs or uninstalls the Helm releases for any of the trial's setup tasks that
+// have HelmChart set, in-process, rather than shelling out to a setup image.
+func (r *SetupReconciler) syncHelmSetupTasks(ctx context.Context, t *optimizev1beta2.Trial, mode string) error {
+	if r.RESTConfig == nil {
+		return nil
+	}
+
+	for i := range t.Spec.SetupTasks {
+		task := &t.Spec.SetupTasks[i]
+		if task.HelmChart == "" && task.HelmChartRef == nil {
+			continue
+		}
+		if (mode == setup.ModeCreate && task.SkipCreate) || (mode == setup.ModeDelete && task.SkipDelete) {
+			continue
+		}
+
+		if err := r.syncHelmSetupTask(ctx, t, task, mode); err != nil {
+			return fmt.Errorf("helm setup task '%s': %w", task.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func (r *SetupReconciler) syncHelmSetupTask(ctx context.Context, t *optimizev1beta2.Trial, task *optimizev1beta2.SetupTask, mode string) error {
+	actionConfig, err := helm.NewActionConfiguration(r.RESTConfig, t.Namespace, r.Log)
+	if err != nil {
+		return err
+	}
+
+	values, err := helm.Values(ctx, r.Client, t, task)
+	if err != nil {
+		return err
+	}
+
+	var chrt *chart.Chart
+	if mode == setup.ModeCreate {
+		if chrt, err = helm.LoadChart(ctx, r.Client, t, task, cli.New()); err != nil {
+			return err
+		}
+	}
+
+	rm := helm.NewReleaseManager(actionConfig, t.Namespace, helm.ReleaseName(t, task), chrt, values)
+	_, err = rm.Sync(ctx, mode)
+	return err
+}
+
+// syncSetupBundle installs or uninstalls a trial's SetupBundle releases in-process, in dependency
+// order (reverse order for deletion). Releases are currently installed sequentially even when their
+// dependencies would allow for parallelism; that is left as a follow-up optimization.
+func (r *SetupReconciler) syncSetupBundle(ctx context.Context, t *optimizev1beta2.Trial, mode string) error {
+	if r.RESTConfig == nil || t.Spec.SetupBundle == nil {
+		return nil
+	}
+
+	releases, err := bundle.Plan(t.Spec.SetupBundle)
+	if err != nil {
+		return fmt.Errorf("setup bundle: %w", err)
+	}
+
+	if mode == setup.ModeDelete {
+		for i, j := 0, len(releases)-1; i < j; i, j = i+1, j-1 {
+			releases[i], releases[j] = releases[j], releases[i]
+		}
+	}
+
+	for i := range releases {
+		if err := r.syncBundleRelease(ctx, t, &releases[i], mode); err != nil {
+			return fmt.Errorf("setup bundle release '%s': %w", releases[i].Name, err)
+		}
+	}
+
+	return nil
+}
+
+// syncBundleRelease installs or uninstalls a single SetupBundle release, reusing the same Helm
+// machinery as a Helm chart SetupTask by adapting the release into a synthetic SetupTask.
+func (r *SetupReconciler) syncBundleRelease(ctx context.Context, t *optimizev1beta2.Trial, rel *optimizev1beta2.BundleRelease, mode string) error {
+	namespace := bundle.Namespace(rel, t.Namespace)
+
+	actionConfig, err := helm.NewActionConfiguration(r.RESTConfig, namespace, r.Log)
+	if err != nil {
+		return err
+	}
+
+	task := &optimizev1beta2.SetupTask{
+		Name:             rel.Name,
+		HelmChart:        rel.Chart,
+		HelmChartVersion: rel.Version,
+		HelmValues:       bundle.Values(t.Spec.SetupBundle, rel),
+	}
+
+	values, err := helm.Values(ctx, r.Client, t, task)
+	if err != nil {
+		return err
+	}
+
+	var chrt *chart.Chart
+	if mode == setup.ModeCreate {
+		if chrt, err = helm.LoadChart(ctx, r.Client, t, task, cli.New()); err != nil {
+			return err
+		}
+	}
+
+	rm := helm.NewReleaseManager(actionConfig, namespace, t.Name+"-"+rel.Name, chrt, values)
+	_, syncErr := rm.Sync(ctx, mode)
+
+	phase := "Installed"
+	if mode == setup.ModeDelete {
+		phase = "Uninstalled"
+	}
+	message := ""
+	if syncErr != nil {
+		phase, message = "Failed", syncErr.Error()
+	}
+	setBundleReleaseStatus(t, rel.Name, phase, message)
+
+	return syncErr
+}
+
+// setBundleReleaseStatus records the observed phase of a SetupBundle release, replacing any existing
+// status entry for the same release.
+func setBundleReleaseStatus(t *optimizev1beta2.Trial, name, phase, message string) {
+	for i := range t.Status.BundleReleases {
+		if t.Status.BundleReleases[i].Name == name {
+			t.Status.BundleReleases[i].Phase = phase
+			t.Status.BundleReleases[i].Message = message
+			return
+		}
+	}
+	t.Status.BundleReleases = append(t.Status.BundleReleases, optimizev1beta2.BundleReleaseStatus{Name: name, Phase: phase, Message: message})
+}
+
 // finish takes care of removing initializers and finalizers
 func (r *SetupReconciler) finish(ctx context.Context, t *optimizev1beta2.Trial) (*ctrl.Result, error) {
 	// If the create job isn't finished, wait for it (unless the trial is already finished, i.e. failed)
@@ -18,6 +18,7 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -36,6 +37,10 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
+// templateUnresolvedRetryDelay is how long to wait before retrying a trial job whose template
+// referenced a value (e.g. a LoadBalancer IP) that was not available yet.
+const templateUnresolvedRetryDelay = 5 * time.Second
+
 // TrialJobReconciler reconciles a Trial's job
 type TrialJobReconciler struct {
 	client.Client
@@ -46,6 +51,8 @@ type TrialJobReconciler struct {
 // +kubebuilder:rbac:groups=optimize.stormforge.io,resources=trials,verbs=get;list;watch;update
 // +kubebuilder:rbac:groups=batch;extensions,resources=jobs,verbs=get;list;watch;create;patch
 // +kubebuilder:rbac:groups="",resources=pods,verbs=list
+// +kubebuilder:rbac:groups="",resources=services,verbs=get
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get
 
 func (r *TrialJobReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	ctx := context.Background()
@@ -85,7 +92,7 @@ func (r *TrialJobReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	}
 
 	// Create the trial run job
-	if result, err := r.createJob(ctx, t); result != nil {
+	if result, err := r.createJob(ctx, t, &now); result != nil {
 		return *result, err
 	}
 
@@ -141,13 +148,36 @@ func (r *TrialJobReconciler) updateStatus(ctx context.Context, t *optimizev1beta
 }
 
 // createJob will create a new trial run job
-func (r *TrialJobReconciler) createJob(ctx context.Context, t *optimizev1beta2.Trial) (*ctrl.Result, error) {
-	job := trial.NewJob(t)
+func (r *TrialJobReconciler) createJob(ctx context.Context, t *optimizev1beta2.Trial, probeTime *metav1.Time) (*ctrl.Result, error) {
+	ns := &corev1.Namespace{}
+	if err := r.Get(ctx, client.ObjectKey{Name: t.Namespace}, ns); err != nil {
+		return &ctrl.Result{}, err
+	}
+
+	job, err := trial.NewJob(t, ns)
+	if err != nil {
+		// The job template or setup volumes violate the enforced Pod Security profile: surface it on the
+		// trial instead of letting the API server reject the pod with a less helpful error
+		trial.ApplyCondition(&t.Status, optimizev1beta2.TrialFailed, corev1.ConditionTrue, "PodSecurityViolation", err.Error(), probeTime)
+		return &ctrl.Result{}, r.Update(ctx, t)
+	}
+
+	if err := trial.RenderTemplates(ctx, r.Client, job); err != nil {
+		if errors.Is(err, trial.ErrTemplateUnresolved) {
+			// The referenced value (e.g. a LoadBalancer IP) is not available yet, try again shortly
+			// rather than launch a job with an incomplete environment
+			return &ctrl.Result{RequeueAfter: templateUnresolvedRetryDelay}, nil
+		}
+
+		trial.ApplyCondition(&t.Status, optimizev1beta2.TrialFailed, corev1.ConditionTrue, "TemplateError", err.Error(), probeTime)
+		return &ctrl.Result{}, r.Update(ctx, t)
+	}
+
 	if err := controllerutil.SetControllerReference(t, job, r.Scheme); err != nil {
 		return &ctrl.Result{}, err
 	}
 
-	err := r.Create(ctx, job)
+	err = r.Create(ctx, job)
 	return &ctrl.Result{}, err
 }
 
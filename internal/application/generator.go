@@ -54,6 +54,7 @@ func (g *Generator) Execute(output kio.Writer) error {
 		Inputs: []kio.Reader{g.Resources},
 		Filters: []kio.Filter{
 			g.FilterOptions.NewFilter(g.WorkingDirectory),
+			scan.ExcludeHelmHooks,
 			&scan.Scanner{
 				Selectors:   []scan.Selector{g},
 				Transformer: g,
@@ -17,12 +17,13 @@ limitations under the License.
 package config
 
 import (
+	"context"
 	"fmt"
 	"net/url"
 	"os/exec"
 	"strings"
 
-	"github.com/redskyops/redskyops-controller/internal/oauth2/discovery"
+	"github.com/thestormforge/optimize-controller/v2/internal/oauth2/discovery"
 )
 
 // The default loader must NEVER make changes via RedSkyConfig.Update or RedSkyConfig.unpersisted
@@ -31,7 +32,7 @@ func defaultLoader(cfg *RedSkyConfig) error {
 	// NOTE: Any errors reported here are effectively fatal errors for a program that needs configuration since they will
 	// not be able to load the configuration. Errors should be limited to unusable configurations.
 
-	d := &defaults{cfg: &cfg.data, env: cfg.Environment(), clusterName: "default"}
+	d := &defaults{cfg: &cfg.data, env: cfg.Environment(), clusterName: "default", noDiscovery: cfg.Overrides.NoDiscovery}
 
 	// This constitutes a "bootstrap" invocation of "kubectl", we can't use the configuration because we are actually creating it
 	cmd := exec.Command("kubectl", "config", "view", "--minify", "--output", "jsonpath={.clusters[0].name}")
@@ -63,6 +64,13 @@ func defaultString(s1 *string, s2 string) {
 	}
 }
 
+// defaultBool overwrites b1 with b2 if b1 is still false
+func defaultBool(b1 *bool, b2 bool) {
+	if !*b1 {
+		*b1 = b2
+	}
+}
+
 func defaultServerRoots(env string, srv *Server) error {
 	// The environment corresponds to deployment details of the proprietary backend
 	switch env {
@@ -78,7 +86,7 @@ func defaultServerRoots(env string, srv *Server) error {
 	return nil
 }
 
-func defaultServerEndpoints(srv *Server) error {
+func defaultServerEndpoints(srv *Server, noDiscovery bool) error {
 	// Determine the default base URLs
 	api, err := discovery.IssuerURL(srv.Identifier)
 	if err != nil {
@@ -93,8 +101,15 @@ func defaultServerEndpoints(srv *Server) error {
 	defaultString(&srv.RedSky.ExperimentsEndpoint, api+"/experiments/")
 	defaultString(&srv.RedSky.AccountsEndpoint, api+"/accounts/")
 
+	// Merge in whatever the issuer's own RFC 8414 metadata reports before falling back to the
+	// hard coded paths below: a value already present on srv came from the loaded configuration
+	// and is left alone (defaultString only fills in blanks), so the precedence ends up being
+	// user-configured, then server-reported, then hard coded default.
+	if !noDiscovery {
+		mergeDiscoveredEndpoints(srv, issuer)
+	}
+
 	// Apply the authorization defaults
-	// TODO We should try discovery, e.g. fetch `discovery.WellKnownURI(issuer, "oauth-authorization-server")` and _merge_ (not _default_ since the server reported values win)
 	defaultString(&srv.Authorization.AuthorizationEndpoint, issuer+"/authorize")
 	defaultString(&srv.Authorization.TokenEndpoint, issuer+"/oauth/token")
 	defaultString(&srv.Authorization.RevocationEndpoint, issuer+"/oauth/revoke")
@@ -113,10 +128,28 @@ func defaultServerEndpoints(srv *Server) error {
 	return nil
 }
 
+// mergeDiscoveredEndpoints fetches the issuer's authorization server metadata document and fills in
+// any endpoint srv does not already have a value for. Fetch failures (offline, no such document,
+// unreachable issuer) are not fatal: they just leave the hard coded path-based defaults in effect.
+func mergeDiscoveredEndpoints(srv *Server, issuer string) {
+	md, err := discovery.Fetch(context.Background(), issuer)
+	if err != nil {
+		return
+	}
+
+	defaultString(&srv.Authorization.AuthorizationEndpoint, md.AuthorizationEndpoint)
+	defaultString(&srv.Authorization.TokenEndpoint, md.TokenEndpoint)
+	defaultString(&srv.Authorization.RevocationEndpoint, md.RevocationEndpoint)
+	defaultString(&srv.Authorization.RegistrationEndpoint, md.RegistrationEndpoint)
+	defaultString(&srv.Authorization.DeviceAuthorizationEndpoint, md.DeviceAuthorizationEndpoint)
+	defaultString(&srv.Authorization.JSONWebKeySetURI, md.JSONWebKeySetURI)
+}
+
 type defaults struct {
 	cfg         *Config
 	env         string
 	clusterName string
+	noDiscovery bool
 }
 
 func (d *defaults) addDefaultObjects() {
@@ -149,7 +182,7 @@ func (d *defaults) applyServerDefaults() error {
 			return err
 		}
 
-		if err := defaultServerEndpoints(srv); err != nil {
+		if err := defaultServerEndpoints(srv, d.noDiscovery); err != nil {
 			return err
 		}
 	}
@@ -16,7 +16,10 @@ limitations under the License.
 
 package config
 
-import "os"
+import (
+	"os"
+	"strconv"
+)
 
 // envLoader adds environment variable overrides to the configuration
 func envLoader(cfg *RedSkyConfig) error {
@@ -25,6 +28,9 @@ func envLoader(cfg *RedSkyConfig) error {
 	defaultString(&cfg.Overrides.ServerIssuer, os.Getenv("REDSKY_SERVER_ISSUER"))
 	defaultString(&cfg.Overrides.Credential.ClientID, os.Getenv("REDSKY_AUTHORIZATION_CLIENT_ID"))
 	defaultString(&cfg.Overrides.Credential.ClientSecret, os.Getenv("REDSKY_AUTHORIZATION_CLIENT_SECRET"))
+	if noDiscovery, err := strconv.ParseBool(os.Getenv("REDSKY_NO_DISCOVERY")); err == nil {
+		defaultBool(&cfg.Overrides.NoDiscovery, noDiscovery)
+	}
 	return nil
 }
 
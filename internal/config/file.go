@@ -37,25 +37,61 @@ const (
 	configFilename       = "redsky/config"
 )
 
-// fileLoader loads a configuration from the currently configured filename
+// fileLoader loads a configuration by reading every XDG config file in precedence order and
+// merging them from lowest to highest priority, the same layering model used by git config: a
+// site-wide /etc/xdg/redsky/config can set defaults (e.g. server endpoints) while the user's
+// XDG_CONFIG_HOME file overrides just what it cares about (e.g. the current context).
 func fileLoader(cfg *RedSkyConfig) error {
-	f := &file{}
-
 	// If we are using a configuration file, the filename _must_ be set
-	filename := cfg.Filename
-	if filename == "" {
-		filename, cfg.Filename = f.filename()
+	if cfg.Filename == "" {
+		_, cfg.Filename = filename()
 	}
 
-	if err := f.read(filename); err != nil {
-		return err
+	for _, fn := range mergeOrder() {
+		f := &file{}
+		if err := f.read(fn); err != nil {
+			return err
+		}
+		cfg.Merge(&f.data)
 	}
 
-	cfg.Merge(&f.data)
-
 	return nil
 }
 
+// mergeOrder returns every candidate configuration file, ordered from lowest to highest merge
+// priority: XDG_CONFIG_DIRS entries in reverse search order, followed by XDG_CONFIG_HOME last so
+// it always has the final say. Non-existent files are included (read is a no-op for them) so a
+// file created after Load was (or wasn't) called doesn't change the precedence order.
+func mergeOrder() []string {
+	dirs := searchDirs()
+
+	order := make([]string, len(dirs))
+	for i, dir := range dirs {
+		order[len(dirs)-1-i] = filepath.Join(dir, configFilename)
+	}
+	return order
+}
+
+// searchDirs returns the XDG configuration directories in search order: XDG_CONFIG_HOME first
+// (highest precedence), followed by each entry of XDG_CONFIG_DIRS in the order it was configured.
+func searchDirs() []string {
+	xdgConfigHome := os.Getenv(xdgConfigHomeEnv)
+	if xdgConfigHome == "" {
+		home := os.Getenv(homeEnv)
+		if home == "" {
+			home = "~" // TODO Does this work? Or do we need to error out?
+		}
+		xdgConfigHome = filepath.Join(home, xdgConfigHomeDefault)
+	}
+
+	xdgConfigDirs := os.Getenv(xdgConfigDirsEnv)
+	if xdgConfigDirs == "" {
+		xdgConfigDirs = xdgConfigDirsDefault
+	}
+
+	return append([]string{xdgConfigHome}, filepath.SplitList(xdgConfigDirs)...)
+}
+
 // file represents the data of a configuration file
 type file struct {
 	data Config
@@ -98,31 +134,79 @@ func (l *file) write(filename string) error {
 	return nil
 }
 
-// filename finds the configuration file and returns both the current file and where changes should be written
-func (l *file) filename() (string, string) {
-	xdgConfigHome := os.Getenv(xdgConfigHomeEnv)
-	if xdgConfigHome == "" {
-		home := os.Getenv(homeEnv)
-		if home == "" {
-			home = "~" // TODO Does this work? Or do we need to error out?
-		}
-		xdgConfigHome = filepath.Join(home, xdgConfigHomeDefault)
-	}
-
-	xdgConfigDirs := os.Getenv(xdgConfigDirsEnv)
-	if xdgConfigDirs == "" {
-		xdgConfigDirs = xdgConfigDirsDefault
-	}
+// filename finds the highest priority existing configuration file and returns it along with the
+// file writes should always target (XDG_CONFIG_HOME, regardless of which file reads came from).
+func filename() (string, string) {
+	dirs := searchDirs()
 
-	userConfigFilename := filepath.Join(xdgConfigHome, configFilename)
+	userConfigFilename := filepath.Join(dirs[0], configFilename)
 	currentConfigFilename := userConfigFilename
-	for _, dir := range append([]string{xdgConfigHome}, filepath.SplitList(xdgConfigDirs)...) {
-		filename := filepath.Join(dir, configFilename)
-		if _, err := os.Stat(filename); err == nil {
-			currentConfigFilename = filename
+	for _, dir := range dirs {
+		fn := filepath.Join(dir, configFilename)
+		if _, err := os.Stat(fn); err == nil {
+			currentConfigFilename = fn
 			break
 		}
 	}
 
 	return currentConfigFilename, userConfigFilename
 }
+
+// FilePath describes one layer of the merged configuration, in the order it was applied (lowest
+// priority first). Keys lists the top-level configuration fields the file set, so a later file
+// silently overriding an earlier one's value is easy to spot.
+type FilePath struct {
+	// Filename is the path of the configuration file, whether or not it exists
+	Filename string
+	// Exists indicates whether the file was found on disk
+	Exists bool
+	// Keys lists the top-level configuration fields this file populated
+	Keys []string
+}
+
+// Paths reports the merge order fileLoader uses along with, for each existing file, which
+// top-level keys it contributed; this backs the "config paths" debugging command, since layered
+// overrides are otherwise silent.
+func Paths() ([]FilePath, error) {
+	order := mergeOrder()
+	paths := make([]FilePath, len(order))
+
+	for i, fn := range order {
+		paths[i] = FilePath{Filename: fn}
+
+		f := &file{}
+		if err := f.read(fn); err != nil {
+			return nil, err
+		}
+		if _, err := os.Stat(fn); err == nil {
+			paths[i].Exists = true
+			paths[i].Keys = dataKeys(&f.data)
+		}
+	}
+
+	return paths, nil
+}
+
+// dataKeys lists the top-level Config fields a file populated.
+func dataKeys(data *Config) []string {
+	var keys []string
+	if len(data.Servers) > 0 {
+		keys = append(keys, "servers")
+	}
+	if len(data.Authorizations) > 0 {
+		keys = append(keys, "authorizations")
+	}
+	if len(data.Clusters) > 0 {
+		keys = append(keys, "clusters")
+	}
+	if len(data.Controllers) > 0 {
+		keys = append(keys, "controllers")
+	}
+	if len(data.Contexts) > 0 {
+		keys = append(keys, "contexts")
+	}
+	if data.CurrentContext != "" {
+		keys = append(keys, "current-context")
+	}
+	return keys
+}
@@ -34,6 +34,9 @@ type Overrides struct {
 	KubeConfig string
 	// Namespace overrides the current cluster's default namespace
 	Namespace string
+	// NoDiscovery disables fetching the authorization server's RFC 8414 metadata document, for
+	// air-gapped installs that cannot make outbound network calls during configuration loading
+	NoDiscovery bool
 }
 
 var _ Reader = &overrideReader{}
@@ -64,7 +67,7 @@ func (o *overrideReader) Server(name string) (Server, error) {
 	}
 
 	if o.overrides.ServerIdentifier != "" || o.overrides.ServerIssuer != "" {
-		if err := defaultServerEndpoints(&srv); err != nil {
+		if err := defaultServerEndpoints(&srv, o.overrides.NoDiscovery); err != nil {
 			return Server{}, err
 		}
 	}
@@ -0,0 +1,200 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package diff renders a unified diff between two strings, independent of any external diff library (none
+// of this module's resolved dependencies expose one).
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// context is the number of unchanged lines kept around a change, matching the "diff -u" default.
+const contextLines = 3
+
+// Unified renders a unified diff of from and to, labelling the two sides fromLabel and toLabel. An empty
+// string is returned if from and to are identical.
+func Unified(fromLabel, toLabel, from, to string) string {
+	fromLines := splitLines(from)
+	toLines := splitLines(to)
+	ops := diffLines(fromLines, toLines)
+
+	hunks := hunksFor(ops)
+	if len(hunks) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n", fromLabel)
+	fmt.Fprintf(&sb, "+++ %s\n", toLabel)
+	for _, h := range hunks {
+		writeHunk(&sb, fromLines, toLines, ops[h.start:h.end])
+	}
+	return sb.String()
+}
+
+// splitLines splits s into lines, dropping a single trailing newline so that "a\nb\n" and "a\nb" produce
+// the same line count.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// opKind identifies whether a line in the edit script was kept, removed, or added.
+type opKind int
+
+const (
+	opEqual opKind = iota
+	opDelete
+	opInsert
+)
+
+// op is a single line of the edit script, indexed into fromLines or toLines depending on kind.
+type op struct {
+	kind opKind
+	i, j int
+}
+
+// diffLines computes a line-level edit script from from to to using the standard LCS dynamic program; this
+// is the same algorithm "diff -u" is built on, just without any of its output formatting.
+func diffLines(from, to []string) []op {
+	n, m := len(from), len(to)
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			switch {
+			case from[i] == to[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var ops []op
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case from[i] == to[j]:
+			ops = append(ops, op{opEqual, i, j})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, op{opDelete, i, 0})
+			i++
+		default:
+			ops = append(ops, op{opInsert, 0, j})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		ops = append(ops, op{opDelete, i, 0})
+	}
+	for ; j < m; j++ {
+		ops = append(ops, op{opInsert, 0, j})
+	}
+	return ops
+}
+
+// hunkRange is a contiguous run of the edit script that contains at least one change, padded with up to
+// contextLines unchanged lines on either side.
+type hunkRange struct {
+	start, end int // indices into the edit script, end exclusive
+}
+
+// hunksFor groups ops into hunks, merging runs of changes that are within 2*contextLines of each other.
+func hunksFor(ops []op) []hunkRange {
+	var changed []int
+	for i, o := range ops {
+		if o.kind != opEqual {
+			changed = append(changed, i)
+		}
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+
+	var hunks []hunkRange
+	start := max(0, changed[0]-contextLines)
+	end := min(len(ops), changed[0]+contextLines+1)
+	for _, c := range changed[1:] {
+		lo := max(0, c-contextLines)
+		if lo <= end {
+			end = min(len(ops), c+contextLines+1)
+			continue
+		}
+		hunks = append(hunks, hunkRange{start, end})
+		start = lo
+		end = min(len(ops), c+contextLines+1)
+	}
+	hunks = append(hunks, hunkRange{start, end})
+	return hunks
+}
+
+// writeHunk renders a single hunk in "diff -u" format, including its "@@ -l,s +l,s @@" header.
+func writeHunk(sb *strings.Builder, from, to []string, ops []op) {
+	var fromStart, fromCount, toStart, toCount int
+	for _, o := range ops {
+		switch o.kind {
+		case opEqual, opDelete:
+			if fromCount == 0 {
+				fromStart = o.i
+			}
+			fromCount++
+		}
+		switch o.kind {
+		case opEqual, opInsert:
+			if toCount == 0 {
+				toStart = o.j
+			}
+			toCount++
+		}
+	}
+
+	fmt.Fprintf(sb, "@@ -%d,%d +%d,%d @@\n", fromStart+1, fromCount, toStart+1, toCount)
+	for _, o := range ops {
+		switch o.kind {
+		case opEqual:
+			fmt.Fprintf(sb, " %s\n", from[o.i])
+		case opDelete:
+			fmt.Fprintf(sb, "-%s\n", from[o.i])
+		case opInsert:
+			fmt.Fprintf(sb, "+%s\n", to[o.j])
+		}
+	}
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
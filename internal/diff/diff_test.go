@@ -0,0 +1,56 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnified(t *testing.T) {
+	cases := []struct {
+		desc     string
+		from, to string
+		expected string
+	}{
+		{
+			desc:     "identical",
+			from:     "line1\nline2\n",
+			to:       "line1\nline2\n",
+			expected: "",
+		},
+		{
+			desc:     "single line changed",
+			from:     "line1\nline2\nline3\n",
+			to:       "line1\nlineX\nline3\n",
+			expected: "--- a\n+++ b\n@@ -1,3 +1,3 @@\n line1\n-line2\n+lineX\n line3\n",
+		},
+		{
+			desc:     "line appended",
+			from:     "line1\n",
+			to:       "line1\nline2\n",
+			expected: "--- a\n+++ b\n@@ -1,1 +1,2 @@\n line1\n+line2\n",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			assert.Equal(t, c.expected, Unified("a", "b", c.from, c.to))
+		})
+	}
+}
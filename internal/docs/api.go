@@ -0,0 +1,326 @@
+// Copyright 2016 The prometheus-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// This file originally comes from the Prometheus Operator project:
+// https://github.com/coreos/prometheus-operator/blob/master/cmd/po-docgen/api.go
+// Modifications have been made to account for using this code in this project
+// (package directory parsing and kubebuilder marker rendering), the previous
+// version is available in Git history under pkg/redskyctl/cmd/docs.
+
+// Package docs generates Markdown API reference documentation from the doc
+// comments and kubebuilder/gengo validation markers on a Go package's struct
+// types, in the style of the Kubernetes API reference.
+package docs
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/doc"
+	"go/parser"
+	"go/token"
+	"io"
+	"reflect"
+	"strings"
+)
+
+const firstParagraph = `
+# API Docs
+
+`
+
+var (
+	links = map[string]string{
+		"batchv1beta1.JobTemplateSpec": "https://kubernetes.io/docs/reference/generated/kubernetes-api/v1.14/#jobtemplatespec-v1beta1-batch",
+		"corev1.ObjectReference":       "https://kubernetes.io/docs/reference/generated/kubernetes-api/v1.14/#objectreference-v1-core",
+		"corev1.Volume":                "https://kubernetes.io/docs/reference/generated/kubernetes-api/v1.14/#volume-v1-core",
+		"corev1.VolumeMount":           "https://kubernetes.io/docs/reference/generated/kubernetes-api/v1.14/#volumemount-v1-core",
+		"corev1.PolicyRule":            "https://kubernetes.io/docs/reference/generated/kubernetes-api/v1.14/#policyrule-v1-rbac",
+		"metav1.ObjectMeta":            "https://kubernetes.io/docs/reference/generated/kubernetes-api/v1.14/#objectmeta-v1-meta",
+		"metav1.ListMeta":              "https://kubernetes.io/docs/reference/generated/kubernetes-api/v1.14/#listmeta-v1-meta",
+		"metav1.LabelSelector":         "https://kubernetes.io/docs/reference/generated/kubernetes-api/v1.14/#labelselector-v1-meta",
+		"metav1.Time":                  "https://kubernetes.io/docs/reference/generated/kubernetes-api/v1.14/#time-v1-meta",
+		"metav1.Duration":              "https://kubernetes.io/docs/reference/generated/kubernetes-api/v1.14/#duration-v1-meta",
+	}
+
+	selfLinks = map[string]string{}
+)
+
+// Pair is a field (or, for index 0 of a KubeTypes, the struct itself) along with its documentation
+// and the validation constraints recovered from its kubebuilder/gengo markers.
+type Pair struct {
+	Name, Doc, Type string
+	Mandatory       bool
+	markers
+}
+
+// KubeTypes represents all the documented fields of a single struct type, in declaration order,
+// with the struct itself as element 0.
+type KubeTypes []Pair
+
+// GenMarkdown renders the API documentation for every struct type declared in the Go package found
+// at dir as a single Markdown document.
+func GenMarkdown(dir string, out io.Writer) error {
+	types, err := ParseDocumentationFrom(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, t := range types {
+		selfLinks[t[0].Name] = "#" + strings.ToLower(t[0].Name)
+	}
+
+	// Parse again now that selfLinks is populated so field types can cross-reference one another
+	types, err = ParseDocumentationFrom(dir)
+	if err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprint(out, firstParagraph); err != nil {
+		return err
+	}
+
+	printTOC(out, types)
+
+	for _, t := range types {
+		strukt := t[0]
+		if _, err := fmt.Fprintf(out, "\n## %s\n\n%s\n\n", strukt.Name, strukt.Doc); err != nil {
+			return err
+		}
+
+		if _, err := fmt.Fprintln(out, "| Field | Description | Scheme | Required | Default | Enum values | Min/Max | Pattern | List type |"); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(out, "| ----- | ----------- | ------ | -------- | ------- | ------------ | ------- | ------- | --------- |"); err != nil {
+			return err
+		}
+
+		fields := t[1:]
+		for _, f := range fields {
+			if _, err := fmt.Fprintln(out, "|", fmt.Sprintf("`%s`", f.Name), "|", f.Doc, "|",
+				fmt.Sprintf("_%s_", f.Type), "|", f.Mandatory, "|", f.defaultColumn(), "|", f.enumColumn(), "|",
+				f.minMaxColumn(), "|", f.Pattern, "|", f.listTypeColumn(), "|"); err != nil {
+				return err
+			}
+		}
+		if len(fields) == 0 {
+			if _, err := fmt.Fprintln(out, "| _N/A_ |"); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintln(out); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(out, "[Back to TOC](#table-of-contents)"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func printTOC(out io.Writer, types []KubeTypes) {
+	_, _ = fmt.Fprintf(out, "\n## Table of Contents\n")
+	for _, t := range types {
+		strukt := t[0]
+		_, _ = fmt.Fprintf(out, "* [%s](#%s)\n", strukt.Name, toSectionLink(strukt.Name))
+	}
+}
+
+func toSectionLink(name string) string {
+	name = strings.ToLower(name)
+	name = strings.Replace(name, " ", "-", -1)
+	return name
+}
+
+// ParseDocumentationFrom gets all of the struct types' documentation from the Go package in dir and
+// returns them as a slice (we use a slice rather than a map so field order is preserved). Each type
+// is in turn represented as a slice with element 0 holding the documentation for the type itself.
+// Unlike a single-file parse, this merges every file in the package so a struct whose field types are
+// declared in a sibling file within the same package are resolved correctly. Fields and struct
+// definitions with no documentation are returned as {Name, ""}.
+func ParseDocumentationFrom(dir string) ([]KubeTypes, error) {
+	pkg, err := astFrom(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var docForTypes []KubeTypes
+	for _, kubType := range pkg.Types {
+		structType, ok := kubType.Decl.Specs[0].(*ast.TypeSpec).Type.(*ast.StructType)
+		if !ok {
+			continue
+		}
+
+		typeDoc, typeMarkers := parseMarkers(kubType.Doc)
+		ks := KubeTypes{{Name: kubType.Name, Doc: fmtRawDoc(typeDoc), markers: typeMarkers}}
+
+		for _, field := range structType.Fields.List {
+			n := fieldName(field)
+			if n == "-" {
+				continue
+			}
+
+			fieldDoc, fieldMarkers := parseMarkers(field.Doc.Text())
+			ks = append(ks, Pair{
+				Name:      n,
+				Doc:       fmtRawDoc(fieldDoc),
+				Type:      fieldType(field.Type),
+				Mandatory: fieldRequired(field, fieldMarkers),
+				markers:   fieldMarkers,
+			})
+		}
+		docForTypes = append(docForTypes, ks)
+	}
+
+	return docForTypes, nil
+}
+
+// astFrom parses every Go file in dir as a single package, so that types referenced across files
+// (e.g. a field whose type is declared in a different file of the same package) resolve correctly.
+func astFrom(dir string) (*doc.Package, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, nil, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, pkg := range pkgs {
+		if strings.HasSuffix(name, "_test") {
+			continue
+		}
+
+		files := make([]*ast.File, 0, len(pkg.Files))
+		for _, f := range pkg.Files {
+			files = append(files, f)
+		}
+		return doc.NewFromFiles(fset, files, name, doc.AllDecls)
+	}
+
+	return nil, fmt.Errorf("no Go package found in %s", dir)
+}
+
+// fmtRawDoc formats a doc comment for Markdown, with kubebuilder/gengo markers already extracted
+// (see parseMarkers) and TODOs dropped; unlike the upstream version this no longer truncates
+// everything following a "---" line, since that is exactly where markers tend to live.
+func fmtRawDoc(rawDoc string) string {
+	var buffer bytes.Buffer
+	delPrevChar := func() {
+		if buffer.Len() > 0 {
+			buffer.Truncate(buffer.Len() - 1) // Delete the last " " or "\n"
+		}
+	}
+
+	for _, line := range strings.Split(rawDoc, "\n") {
+		line = strings.TrimRight(line, " ")
+		leading := strings.TrimLeft(line, " ")
+		switch {
+		case len(line) == 0: // Keep paragraphs
+			delPrevChar()
+			buffer.WriteString("\n\n")
+		case strings.HasPrefix(leading, "TODO"): // Ignore one line TODOs
+		case leading == "---": // Ignore the now-meaningless separator itself
+		default:
+			if strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") {
+				delPrevChar()
+				line = "\n" + line + "\n" // Replace it with newline. This is useful when we have a line with: "Example:\n\tJSON-someting..."
+			} else {
+				line += " "
+			}
+			buffer.WriteString(line)
+		}
+	}
+
+	postDoc := strings.TrimRight(buffer.String(), "\n")
+	postDoc = strings.Replace(postDoc, "\n", "\\n", -1)
+	postDoc = strings.Replace(postDoc, "\t", "\\t", -1)
+	postDoc = strings.Replace(postDoc, "|", "\\|", -1)
+
+	return postDoc
+}
+
+func toLink(typeName string) string {
+	if selfLink, ok := selfLinks[typeName]; ok {
+		return wrapInLink(typeName, selfLink)
+	}
+
+	if link, ok := links[typeName]; ok {
+		return wrapInLink(typeName, link)
+	}
+
+	return typeName
+}
+
+func wrapInLink(text, link string) string {
+	parts := strings.Split(text, ".")
+	if len(parts) > 0 {
+		text = parts[len(parts)-1]
+	}
+	return fmt.Sprintf("[%s](%s)", text, link)
+}
+
+// fieldName returns the name of the field as it should appear in JSON format, "-" indicates that
+// this field is not part of the JSON representation.
+func fieldName(field *ast.Field) string {
+	jsonTag := ""
+	if field.Tag != nil {
+		jsonTag = reflect.StructTag(field.Tag.Value[1 : len(field.Tag.Value)-1]).Get("json")
+		if strings.Contains(jsonTag, "inline") {
+			return "-"
+		}
+	}
+
+	jsonTag = strings.Split(jsonTag, ",")[0]
+	if jsonTag == "" {
+		if field.Names != nil {
+			return field.Names[0].Name
+		}
+		return field.Type.(*ast.Ident).Name
+	}
+	return jsonTag
+}
+
+// fieldRequired returns whether a field is required. An explicit +optional or
+// +kubebuilder:validation:Required marker takes precedence over the absence of "omitempty" on the
+// JSON tag, which is only used as a fallback for fields that predate marker annotation.
+func fieldRequired(field *ast.Field, m markers) bool {
+	if m.required != nil {
+		return *m.required
+	}
+
+	if field.Tag != nil {
+		jsonTag := reflect.StructTag(field.Tag.Value[1 : len(field.Tag.Value)-1]).Get("json")
+		return !strings.Contains(jsonTag, "omitempty")
+	}
+
+	return false
+}
+
+func fieldType(typ ast.Expr) string {
+	switch t := typ.(type) {
+	case *ast.Ident:
+		return toLink(t.Name)
+	case *ast.StarExpr:
+		return "*" + toLink(fieldType(t.X))
+	case *ast.SelectorExpr:
+		pkg := t.X.(*ast.Ident)
+		return toLink(pkg.Name + "." + t.Sel.Name)
+	case *ast.ArrayType:
+		return "[]" + toLink(fieldType(t.Elt))
+	case *ast.MapType:
+		return "map[" + toLink(fieldType(t.Key)) + "]" + toLink(fieldType(t.Value))
+	default:
+		return ""
+	}
+}
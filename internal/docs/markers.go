@@ -0,0 +1,133 @@
+/*
+Copyright 2020 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docs
+
+import (
+	"fmt"
+	"strings"
+)
+
+// markers holds the kubebuilder/gengo validation markers recovered from a doc comment, so they can
+// be rendered as their own columns instead of being silently dropped.
+type markers struct {
+	// required is non-nil when a +optional or +kubebuilder:validation:Required marker was present,
+	// taking precedence over the "omitempty" JSON tag heuristic
+	required *bool
+	// Default is the raw value of a +kubebuilder:default marker
+	Default string
+	// Enum is the allowed value set of a +kubebuilder:validation:Enum marker
+	Enum []string
+	// Minimum and Maximum are the raw values of +kubebuilder:validation:Minimum/Maximum markers
+	Minimum, Maximum string
+	// Pattern is the raw value of a +kubebuilder:validation:Pattern marker
+	Pattern string
+	// ListType and ListMapKey are the raw values of +listType and +listMapKey markers
+	ListType, ListMapKey string
+}
+
+// parseMarkers splits a raw doc comment into the text meant for human consumption and the
+// kubebuilder/gengo markers it carries. Marker lines (anything starting with "+") are always
+// removed from the returned text, matching the existing "ignore instructions to go2idl" behavior,
+// but unlike that behavior the markers themselves are no longer discarded.
+func parseMarkers(rawDoc string) (string, markers) {
+	var m markers
+	var text []string
+
+	for _, line := range strings.Split(rawDoc, "\n") {
+		leading := strings.TrimSpace(line)
+		if !strings.HasPrefix(leading, "+") {
+			text = append(text, line)
+			continue
+		}
+
+		switch {
+		case leading == "+optional":
+			f := false
+			m.required = &f
+		case leading == "+kubebuilder:validation:Required":
+			t := true
+			m.required = &t
+		case hasMarkerValue(leading, "+kubebuilder:validation:Enum="):
+			m.Enum = strings.Split(markerValue(leading, "+kubebuilder:validation:Enum="), ";")
+		case hasMarkerValue(leading, "+kubebuilder:validation:Minimum="):
+			m.Minimum = markerValue(leading, "+kubebuilder:validation:Minimum=")
+		case hasMarkerValue(leading, "+kubebuilder:validation:Maximum="):
+			m.Maximum = markerValue(leading, "+kubebuilder:validation:Maximum=")
+		case hasMarkerValue(leading, "+kubebuilder:validation:Pattern="):
+			m.Pattern = markerValue(leading, "+kubebuilder:validation:Pattern=")
+		case hasMarkerValue(leading, "+kubebuilder:default="):
+			m.Default = markerValue(leading, "+kubebuilder:default=")
+		case hasMarkerValue(leading, "+listType="):
+			m.ListType = markerValue(leading, "+listType=")
+		case hasMarkerValue(leading, "+listMapKey="):
+			m.ListMapKey = markerValue(leading, "+listMapKey=")
+		}
+		// Any other "+" marker (+kubebuilder:object:root, +genclient, etc.) is recognized only by
+		// being dropped from the rendered text; it has no documented column of its own.
+	}
+
+	return strings.Join(text, "\n"), m
+}
+
+func hasMarkerValue(line, prefix string) bool {
+	return strings.HasPrefix(line, prefix)
+}
+
+func markerValue(line, prefix string) string {
+	return strings.TrimPrefix(line, prefix)
+}
+
+func (p Pair) defaultColumn() string {
+	if p.Default == "" {
+		return ""
+	}
+	return fmt.Sprintf("`%s`", p.Default)
+}
+
+func (p Pair) enumColumn() string {
+	if len(p.Enum) == 0 {
+		return ""
+	}
+	quoted := make([]string, len(p.Enum))
+	for i, v := range p.Enum {
+		quoted[i] = fmt.Sprintf("`%s`", v)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func (p Pair) minMaxColumn() string {
+	switch {
+	case p.Minimum != "" && p.Maximum != "":
+		return fmt.Sprintf("%s / %s", p.Minimum, p.Maximum)
+	case p.Minimum != "":
+		return fmt.Sprintf("%s / _N/A_", p.Minimum)
+	case p.Maximum != "":
+		return fmt.Sprintf("_N/A_ / %s", p.Maximum)
+	default:
+		return ""
+	}
+}
+
+func (p Pair) listTypeColumn() string {
+	if p.ListType == "" {
+		return ""
+	}
+	if p.ListMapKey != "" {
+		return fmt.Sprintf("%s (key: `%s`)", p.ListType, p.ListMapKey)
+	}
+	return p.ListType
+}
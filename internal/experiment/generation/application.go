@@ -51,6 +51,10 @@ func (s *ApplicationSelector) Map(*yaml.RNode, yaml.ResourceMeta) ([]interface{}
 			result = append(result, &StormForgerSource{Scenario: s.Scenario, Objective: s.Objective, Application: s.Application})
 		case s.Scenario.Locust != nil:
 			result = append(result, &LocustSource{Scenario: s.Scenario, Objective: s.Objective, Application: s.Application})
+		case s.Scenario.K6 != nil:
+			result = append(result, &K6Source{Scenario: s.Scenario, Objective: s.Objective, Application: s.Application})
+		case s.Scenario.JMeter != nil:
+			result = append(result, &JMeterSource{Scenario: s.Scenario, Objective: s.Objective, Application: s.Application})
 		case s.Scenario.Custom != nil:
 			result = append(result, &CustomSource{Scenario: s.Scenario, Objective: s.Objective, Application: s.Application})
 		}
@@ -78,5 +82,18 @@ func (s *ApplicationSelector) Map(*yaml.RNode, yaml.ResourceMeta) ([]interface{}
 		ClusterRoleBindingName: "optimize-setup-prometheus",
 	})
 
+	result = append(result, &BeylaSource{
+		Scenario:               s.Scenario,
+		Objective:              s.Objective,
+		SetupTaskName:          "beyla",
+		ClusterRoleName:        "optimize-beyla",
+		ServiceAccountName:     "optimize-setup",
+		ClusterRoleBindingName: "optimize-setup-beyla",
+	})
+
+	if s.Application != nil && s.Application.Build != nil && s.Application.Build.Tekton != nil {
+		result = append(result, &TektonSource{Application: s.Application, Scenario: s.Scenario})
+	}
+
 	return result, nil
 }
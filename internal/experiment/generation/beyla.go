@@ -0,0 +1,201 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generation
+
+import (
+	"fmt"
+
+	optimizeappsv1alpha1 "github.com/thestormforge/optimize-controller/v2/api/apps/v1alpha1"
+	optimizev1beta2 "github.com/thestormforge/optimize-controller/v2/api/v1beta2"
+	"github.com/thestormforge/optimize-controller/v2/internal/sfio"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+)
+
+// BeylaSource installs the eBPF-based Beyla setup task so RED metrics (latency, error rate) can be
+// collected for an application's own pods without adding any client side load generator or application
+// instrumentation. It only activates when the objective has a Latency or ErrorRate goal that no
+// scenario specific source (see k6.go, locust.go, stormforger.go) has already produced a query for --
+// i.e. there is no load generator already measuring the same thing.
+type BeylaSource struct {
+	Scenario  *optimizeappsv1alpha1.Scenario
+	Objective *optimizeappsv1alpha1.Objective
+
+	SetupTaskName          string
+	ClusterRoleName        string
+	ServiceAccountName     string
+	ClusterRoleBindingName string
+
+	sfio.ObjectSlice
+}
+
+var _ ExperimentSource = &BeylaSource{} // Service account name and setup task
+var _ MetricSource = &BeylaSource{}     // Latency and error rate goals
+var _ kio.Reader = &BeylaSource{}       // RBAC
+
+// needed reports whether there is at least one Latency or ErrorRate goal that isn't already implemented
+// by a scenario specific source; a Scenario being present means a load generator is already in a
+// position to measure those goals client side, so Beyla is not required.
+func (s *BeylaSource) needed() bool {
+	if s.Scenario != nil || s.Objective == nil {
+		return false
+	}
+
+	for i := range s.Objective.Goals {
+		g := &s.Objective.Goals[i]
+		if g.Implemented {
+			continue
+		}
+		if g.Latency != nil || g.ErrorRate != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *BeylaSource) Update(exp *optimizev1beta2.Experiment) error {
+	if !s.needed() {
+		return nil
+	}
+
+	exp.Spec.TrialTemplate.Spec.SetupServiceAccountName = s.ServiceAccountName
+	exp.Spec.TrialTemplate.Spec.SetupTasks = append(exp.Spec.TrialTemplate.Spec.SetupTasks,
+		optimizev1beta2.SetupTask{
+			Name: s.SetupTaskName,
+			Args: []string{"beyla", "$(MODE)"},
+		})
+
+	s.ObjectSlice = append(s.ObjectSlice,
+		&corev1.ServiceAccount{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: s.ServiceAccountName,
+			},
+		},
+
+		&rbacv1.ClusterRole{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: s.ClusterRoleName,
+			},
+			Rules: []rbacv1.PolicyRule{
+				// Required to run Beyla as a DaemonSet that attaches eBPF probes to the application's pods
+				{
+					Verbs:     []string{"get", "create", "delete"},
+					APIGroups: []string{"apps"},
+					Resources: []string{"daemonsets"},
+				},
+				{
+					Verbs:     []string{"get", "create", "delete"},
+					APIGroups: []string{""},
+					Resources: []string{"serviceaccounts", "configmaps"},
+				},
+
+				// Permissions needed to discover the pods Beyla should attach probes to
+				{
+					Verbs:     []string{"list", "watch", "get"},
+					APIGroups: []string{""},
+					Resources: []string{"pods", "nodes"},
+				},
+			},
+		},
+
+		&rbacv1.ClusterRoleBinding{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: s.ClusterRoleBindingName,
+			},
+			RoleRef: rbacv1.RoleRef{
+				APIGroup: rbacv1.GroupName,
+				Kind:     "ClusterRole",
+				Name:     s.ClusterRoleName,
+			},
+			Subjects: []rbacv1.Subject{
+				{
+					Kind: "ServiceAccount",
+					Name: s.ServiceAccountName,
+				},
+			},
+		},
+	)
+
+	return nil
+}
+
+func (s *BeylaSource) Metrics() ([]optimizev1beta2.Metric, error) {
+	var result []optimizev1beta2.Metric
+	if !s.needed() {
+		return result, nil
+	}
+
+	for i := range s.Objective.Goals {
+		goal := &s.Objective.Goals[i]
+		switch {
+
+		case goal.Implemented:
+			// Do nothing
+
+		case goal.Latency != nil:
+			if q := beylaLatencyQuery(goal.Latency.LatencyType); q != "" {
+				result = append(result, newGoalMetric(goal, q))
+			}
+
+		case goal.ErrorRate != nil:
+			if goal.ErrorRate.ErrorRateType == optimizeappsv1alpha1.ErrorRateRequests {
+				result = append(result, newGoalMetric(goal, beylaErrorRateQuery()))
+			}
+
+		}
+	}
+
+	return result, nil
+}
+
+// beylaLatencyQuery returns a PromQL query for the supplied latency type computed against the
+// "http_server_request_duration_seconds" histogram Beyla exports for the application's pods.
+func beylaLatencyQuery(lt optimizeappsv1alpha1.LatencyType) string {
+	switch optimizeappsv1alpha1.FixLatency(lt) {
+	case optimizeappsv1alpha1.LatencyMinimum:
+		return `scalar(min(http_server_request_duration_seconds{job="trialRun",instance="{{ .Trial.Name }}"}))`
+	case optimizeappsv1alpha1.LatencyMaximum:
+		return `scalar(max(http_server_request_duration_seconds{job="trialRun",instance="{{ .Trial.Name }}"}))`
+	case optimizeappsv1alpha1.LatencyMean:
+		return `scalar(rate(http_server_request_duration_seconds_sum{job="trialRun",instance="{{ .Trial.Name }}"}[5m]) / rate(http_server_request_duration_seconds_count{job="trialRun",instance="{{ .Trial.Name }}"}[5m]))`
+	case optimizeappsv1alpha1.LatencyPercentile50:
+		return beylaQuantileQuery(0.5)
+	case optimizeappsv1alpha1.LatencyPercentile95:
+		return beylaQuantileQuery(0.95)
+	case optimizeappsv1alpha1.LatencyPercentile99:
+		return beylaQuantileQuery(0.99)
+	default:
+		return ""
+	}
+}
+
+// beylaQuantileQuery returns a histogram_quantile query for the supplied quantile (e.g. 0.95).
+func beylaQuantileQuery(quantile float64) string {
+	return fmt.Sprintf(`scalar(histogram_quantile(%g, sum(rate(http_server_request_duration_seconds_bucket{job="trialRun",instance="{{ .Trial.Name }}"}[5m])) by (le)))`, quantile)
+}
+
+// beylaErrorRateQuery returns a PromQL query for the ratio of 5xx responses Beyla observed for the
+// application's pods.
+func beylaErrorRateQuery() string {
+	return `scalar(` +
+		`sum(rate(http_server_request_duration_seconds_count{job="trialRun",instance="{{ .Trial.Name }}",http_status_code=~"5.."}[5m])) / ` +
+		`sum(rate(http_server_request_duration_seconds_count{job="trialRun",instance="{{ .Trial.Name }}"}[5m]))` +
+		`)`
+}
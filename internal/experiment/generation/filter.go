@@ -19,14 +19,42 @@ package generation
 import (
 	"crypto/sha256"
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/thestormforge/konjure/pkg/filters"
 	optimizev1beta2 "github.com/thestormforge/optimize-controller/v2/api/v1beta2"
+	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/kustomize/kyaml/openapi"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
 
+// AppliedLabelsAnnotation records the keys most recently applied by SetLabels so a later generation can
+// determine which labels to retract via the Unset option.
+const AppliedLabelsAnnotation = "optimize.stormforge.io/applied-labels"
+
+// DefaultSetupServiceAccountName is the name assigned to the setup service account created by
+// EnsureSetupServiceAccount.
+const DefaultSetupServiceAccountName = "optimize-setup"
+
+// EnsureSetupServiceAccount makes sure the experiment has an explicit setup service account name,
+// returning the ServiceAccount object that needs to be created alongside the experiment. If the
+// experiment already names a setup service account, nil is returned and no changes are made.
+func EnsureSetupServiceAccount(exp *optimizev1beta2.Experiment) *corev1.ServiceAccount {
+	if exp.Spec.TrialTemplate.Spec.SetupServiceAccountName != "" {
+		return nil
+	}
+	exp.Spec.TrialTemplate.Spec.SetupServiceAccountName = DefaultSetupServiceAccountName
+
+	return &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: exp.Spec.TrialTemplate.Spec.SetupServiceAccountName,
+		},
+	}
+}
+
 // SetExperimentLabel is a filter that sets a label on an experiment object.
 func SetExperimentLabel(key, value string) yaml.Filter {
 	return yaml.FilterFunc(func(node *yaml.RNode) (*yaml.RNode, error) {
@@ -45,6 +73,91 @@ func SetExperimentLabel(key, value string) yaml.Filter {
 	})
 }
 
+// LabelOption configures the behavior of SetLabels.
+type LabelOption func(*labelOptions)
+
+type labelOptions struct {
+	unset map[string]string
+}
+
+// Unset configures SetLabels to remove labels that were present in a prior invocation (for example, as
+// recorded in AppliedLabelsAnnotation) but are absent from the new label set.
+func Unset(previous map[string]string) LabelOption {
+	return func(o *labelOptions) { o.unset = previous }
+}
+
+// SetLabels is a filter that applies a set of labels across all generated resources and their embedded
+// pod/job/trial templates, recording the applied keys in AppliedLabelsAnnotation so a subsequent
+// generation can pass that value back in via Unset to cleanly retract them.
+func SetLabels(labels map[string]string, opts ...LabelOption) yaml.Filter {
+	o := &labelOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	var unsetKeys []string
+	for k := range o.unset {
+		if _, ok := labels[k]; !ok {
+			unsetKeys = append(unsetKeys, k)
+		}
+	}
+	sort.Strings(unsetKeys)
+
+	appliedKeys := make([]string, 0, len(labels))
+	for k := range labels {
+		appliedKeys = append(appliedKeys, k)
+	}
+	sort.Strings(appliedKeys)
+
+	// apply unsets and sets metadata.labels, records which keys were applied, and (where present) updates
+	// the value of any spec.selector.matchLabels key that already exists. Selectors are immutable once a
+	// Deployment/Job is created, so a brand new key is never added there: doing so would orphan pods from
+	// a prior generation that do not carry it.
+	applyLabels := yaml.FilterFunc(func(node *yaml.RNode) (*yaml.RNode, error) {
+		for _, key := range unsetKeys {
+			if err := node.PipeE(clearLabel(key)); err != nil {
+				return nil, err
+			}
+		}
+		for key, value := range labels {
+			if err := node.PipeE(yaml.SetLabel(key, value)); err != nil {
+				return nil, err
+			}
+		}
+		if err := node.PipeE(yaml.SetAnnotation(AppliedLabelsAnnotation, strings.Join(appliedKeys, ","))); err != nil {
+			return nil, err
+		}
+
+		matchLabels, err := node.Pipe(yaml.Lookup("spec", "selector", "matchLabels"))
+		if err != nil || matchLabels == nil {
+			return node, err
+		}
+		return node, matchLabels.VisitFields(func(field *yaml.MapNode) error {
+			key := yaml.GetValue(field.Key)
+			value, ok := labels[key]
+			if !ok {
+				return nil
+			}
+			return matchLabels.PipeE(yaml.FieldSetter{Name: key, Value: yaml.NewStringRNode(value)})
+		})
+	})
+
+	return yaml.FilterFunc(func(node *yaml.RNode) (*yaml.RNode, error) {
+		return node.Pipe(yaml.Tee(
+			applyLabels,
+			yaml.Lookup("spec", "trialTemplate"), yaml.Tee(applyLabels),
+			yaml.Lookup("spec", "jobTemplate"), yaml.Tee(applyLabels),
+			yaml.Lookup("spec", "template"), yaml.Tee(applyLabels),
+		))
+	})
+}
+
+func clearLabel(key string) yaml.Filter {
+	return yaml.FilterFunc(func(node *yaml.RNode) (*yaml.RNode, error) {
+		return node.Pipe(yaml.Lookup(yaml.MetadataField, yaml.LabelsField), yaml.FieldClearer{Name: key})
+	})
+}
+
 // SetNamespace sets the namespace on a resource (if necessary).
 func SetNamespace(namespace string) yaml.Filter {
 	return yaml.FilterFunc(func(node *yaml.RNode) (*yaml.RNode, error) {
@@ -0,0 +1,77 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const experimentYAML = `
+apiVersion: optimize.stormforge.io/v1beta2
+kind: Experiment
+metadata:
+  name: my-experiment
+  labels:
+    team: other
+    stale: old
+spec:
+  selector:
+    matchLabels:
+      team: other
+  trialTemplate:
+    spec:
+      jobTemplate:
+        spec:
+          template:
+            metadata:
+              labels: {}
+`
+
+func TestSetLabels(t *testing.T) {
+	node, err := yaml.Parse(experimentYAML)
+	require.NoError(t, err)
+
+	labels := map[string]string{"team": "sre", "cost-center": "42"}
+	previous := map[string]string{"team": "other", "stale": "old"}
+
+	_, err = node.Pipe(SetLabels(labels, Unset(previous)))
+	require.NoError(t, err)
+
+	meta, err := node.Pipe(yaml.Lookup("metadata", "labels"))
+	require.NoError(t, err)
+	assert.Equal(t, "sre", yaml.GetValue(meta.Field("team").Value))
+	assert.Equal(t, "42", yaml.GetValue(meta.Field("cost-center").Value))
+	assert.Nil(t, meta.Field("stale"), "unset label should have been removed")
+
+	annotations, err := node.Pipe(yaml.Lookup("metadata", "annotations"))
+	require.NoError(t, err)
+	assert.Equal(t, "cost-center,team", yaml.GetValue(annotations.Field(AppliedLabelsAnnotation).Value))
+
+	selector, err := node.Pipe(yaml.Lookup("spec", "selector", "matchLabels"))
+	require.NoError(t, err)
+	assert.Equal(t, "sre", yaml.GetValue(selector.Field("team").Value))
+	assert.Nil(t, selector.Field("cost-center"), "new keys must not be added to an existing selector")
+
+	templateLabels, err := node.Pipe(
+		yaml.Lookup("spec", "trialTemplate", "spec", "jobTemplate", "spec", "template", "metadata", "labels"))
+	require.NoError(t, err)
+	assert.Equal(t, "sre", yaml.GetValue(templateLabels.Field("team").Value))
+}
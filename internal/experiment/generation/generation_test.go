@@ -72,6 +72,34 @@ func TestConvertPrometheusSelector(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc:           "negated fake regexp set",
+			metricSelector: `a!~"A|B|C"`,
+			expected: &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "a", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"A", "B", "C"}},
+				},
+			},
+		},
+		{
+			desc:           "finite regexp expansion",
+			metricSelector: `a=~"(A|B)C"`,
+			expected: &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "a", Operator: metav1.LabelSelectorOpIn, Values: []string{"AC", "BC"}},
+				},
+			},
+		},
+		{
+			desc:           "escaped quote and comma in value",
+			metricSelector: `a="x\"y",b="c,d"`,
+			expected: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"a": `x"y`,
+					"b": "c,d",
+				},
+			},
+		},
 	}
 	for _, c := range cases {
 		t.Run(c.desc, func(t *testing.T) {
@@ -107,7 +135,12 @@ func TestConvertPrometheusSelectorError(t *testing.T) {
 		{
 			desc:           "cannot fake regexp",
 			metricSelector: `a=~".+"`,
-			errStr:         `invalid metric selector`,
+			errStr:         `invalid metric selector: unsupported regular expression in matcher a=~".+": does not expand to a finite set of values`,
+		},
+		{
+			desc:           "infinite regexp with quantifier",
+			metricSelector: `pod=~"myapp-.*"`,
+			errStr:         `invalid metric selector: unsupported regular expression in matcher pod=~"myapp-.*": does not expand to a finite set of values`,
 		},
 	}
 	for _, c := range cases {
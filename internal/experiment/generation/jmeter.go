@@ -0,0 +1,176 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generation
+
+import (
+	"fmt"
+
+	optimizeappsv1alpha1 "github.com/thestormforge/optimize-controller/v2/api/apps/v1alpha1"
+	optimizev1beta2 "github.com/thestormforge/optimize-controller/v2/api/v1beta2"
+	"github.com/thestormforge/optimize-controller/v2/internal/sfio"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+type JMeterSource struct {
+	Scenario    *optimizeappsv1alpha1.Scenario
+	Objective   *optimizeappsv1alpha1.Objective
+	Application *optimizeappsv1alpha1.Application
+}
+
+func (s *JMeterSource) Update(exp *optimizev1beta2.Experiment) error {
+	if s.Scenario == nil || s.Application == nil {
+		return nil
+	}
+
+	pod := &ensureTrialJobPod(exp).Spec
+	pod.Containers = []corev1.Container{
+		{
+			Name:  "jmeter",
+			Image: trialJobImage("jmeter"),
+			Args:  []string{"-n", "-t", "/mnt/jmeter/" + s.testPlanKey()},
+			Env:   s.jmeterEnv(),
+			VolumeMounts: []corev1.VolumeMount{
+				{
+					Name:      "jmeter-test-plan",
+					ReadOnly:  true,
+					MountPath: "/mnt/jmeter",
+				},
+			},
+		},
+	}
+
+	pod.Volumes = []corev1.Volume{
+		{
+			Name: "jmeter-test-plan",
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: s.jmeterConfigMapName(),
+					},
+				},
+			},
+		},
+	}
+
+	// TODO We need to rethink how ingress scanning works, this just preserves existing behavior
+	var ingressURL string
+	if s.Application != nil && s.Application.Ingress != nil {
+		ingressURL = s.Application.Ingress.URL
+	}
+	if ingressURL != "" {
+		pod.Containers[0].Env = append(pod.Containers[0].Env, corev1.EnvVar{Name: "JMETER_TARGET", Value: ingressURL})
+	}
+
+	return nil
+}
+
+func (s *JMeterSource) Read() ([]*yaml.RNode, error) {
+	result := sfio.ObjectSlice{}
+
+	if s.Scenario.JMeter.TestPlan == "" {
+		return nil, fmt.Errorf("missing JMeter test plan for scenario %q", s.Scenario.Name)
+	}
+
+	data, err := loadApplicationData(s.Application, s.Scenario.JMeter.TestPlan)
+	if err != nil {
+		return nil, err
+	}
+
+	cm := &corev1.ConfigMap{}
+	cm.Name = s.jmeterConfigMapName()
+	cm.Data = map[string]string{s.testPlanKey(): string(data)}
+	result = append(result, cm)
+
+	return result.Read()
+}
+
+func (s *JMeterSource) Metrics() ([]optimizev1beta2.Metric, error) {
+	var result []optimizev1beta2.Metric
+	if s.Objective == nil {
+		return result, nil
+	}
+
+	for i := range s.Objective.Goals {
+		goal := &s.Objective.Goals[i]
+		switch {
+
+		case goal.Implemented:
+			// Do nothing
+
+		case goal.Latency != nil:
+			if l := s.jmeterLatency(goal.Latency.LatencyType); l != "" {
+				query := `scalar(` + l + `{job="trialRun",instance="{{ .Trial.Name }}"})`
+				result = append(result, newGoalMetric(goal, query))
+			}
+
+		case goal.ErrorRate != nil:
+			if goal.ErrorRate.ErrorRateType == optimizeappsv1alpha1.ErrorRateRequests {
+				query := `scalar(jmeter_error_ratio{job="trialRun",instance="{{ .Trial.Name }}"})`
+				result = append(result, newGoalMetric(goal, query))
+			}
+
+		}
+	}
+
+	return result, nil
+}
+
+func (s *JMeterSource) jmeterConfigMapName() string {
+	return fmt.Sprintf("%s-jmeter-test-plan", s.Scenario.Name)
+}
+
+func (s *JMeterSource) testPlanKey() string {
+	return "test-plan.jmx"
+}
+
+func (s *JMeterSource) jmeterEnv() []corev1.EnvVar {
+	var env []corev1.EnvVar
+
+	if users := s.Scenario.JMeter.Users; users != nil {
+		env = append(env, corev1.EnvVar{Name: "JMETER_USERS", Value: fmt.Sprintf("%d", *users)})
+	}
+
+	if rampTime := s.Scenario.JMeter.RampTime; rampTime != nil {
+		env = append(env, corev1.EnvVar{Name: "JMETER_RAMP_TIME", Value: fmt.Sprintf("%.0f", rampTime.Seconds())})
+	}
+
+	if duration := s.Scenario.JMeter.Duration; duration != nil {
+		env = append(env, corev1.EnvVar{Name: "JMETER_DURATION", Value: fmt.Sprintf("%.0f", duration.Seconds())})
+	}
+
+	return env
+}
+
+func (s *JMeterSource) jmeterLatency(lt optimizeappsv1alpha1.LatencyType) string {
+	switch optimizeappsv1alpha1.FixLatency(lt) {
+	case optimizeappsv1alpha1.LatencyMinimum:
+		return "jmeter_response_time_min"
+	case optimizeappsv1alpha1.LatencyMaximum:
+		return "jmeter_response_time_max"
+	case optimizeappsv1alpha1.LatencyMean:
+		return "jmeter_response_time_mean"
+	case optimizeappsv1alpha1.LatencyPercentile50:
+		return "jmeter_response_time_median"
+	case optimizeappsv1alpha1.LatencyPercentile95:
+		return "jmeter_response_time_p95"
+	case optimizeappsv1alpha1.LatencyPercentile99:
+		return "jmeter_response_time_p99"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,195 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generation
+
+import (
+	"fmt"
+
+	optimizeappsv1alpha1 "github.com/thestormforge/optimize-controller/v2/api/apps/v1alpha1"
+	optimizev1beta2 "github.com/thestormforge/optimize-controller/v2/api/v1beta2"
+	"github.com/thestormforge/optimize-controller/v2/internal/sfio"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+type K6Source struct {
+	Scenario    *optimizeappsv1alpha1.Scenario
+	Objective   *optimizeappsv1alpha1.Objective
+	Application *optimizeappsv1alpha1.Application
+}
+
+func (s *K6Source) Update(exp *optimizev1beta2.Experiment) error {
+	if s.Scenario == nil || s.Application == nil {
+		return nil
+	}
+
+	pod := &ensureTrialJobPod(exp).Spec
+	pod.Containers = []corev1.Container{
+		{
+			Name:  "k6",
+			Image: trialJobImage("k6"),
+			Args:  []string{"run", "--out", "experimental-prometheus-rw", "/mnt/k6/" + s.scriptKey()},
+			Env:   s.k6Env(),
+			VolumeMounts: []corev1.VolumeMount{
+				{
+					Name:      "k6-script",
+					ReadOnly:  true,
+					MountPath: "/mnt/k6",
+				},
+			},
+		},
+	}
+
+	pod.Volumes = []corev1.Volume{
+		{
+			Name: "k6-script",
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: s.k6ConfigMapName(),
+					},
+				},
+			},
+		},
+	}
+
+	return nil
+}
+
+func (s *K6Source) Read() ([]*yaml.RNode, error) {
+	result := sfio.ObjectSlice{}
+
+	if s.Scenario.K6.Script == "" {
+		return nil, fmt.Errorf("missing k6 script for scenario %q", s.Scenario.Name)
+	}
+
+	data, err := loadApplicationData(s.Application, s.Scenario.K6.Script)
+	if err != nil {
+		return nil, err
+	}
+
+	cm := &corev1.ConfigMap{}
+	cm.Name = s.k6ConfigMapName()
+	cm.Data = map[string]string{s.scriptKey(): string(data)}
+	result = append(result, cm)
+
+	return result.Read()
+}
+
+func (s *K6Source) Metrics() ([]optimizev1beta2.Metric, error) {
+	var result []optimizev1beta2.Metric
+	if s.Objective == nil {
+		return result, nil
+	}
+
+	for i := range s.Objective.Goals {
+		goal := &s.Objective.Goals[i]
+		switch {
+
+		case goal.Implemented:
+			// Do nothing
+
+		case goal.Latency != nil:
+			if l := s.k6Latency(goal.Latency.LatencyType); l != "" {
+				query := `scalar(` + l + `{job="trialRun",instance="{{ .Trial.Name }}"})`
+				result = append(result, newGoalMetric(goal, query))
+			}
+
+		case goal.ErrorRate != nil:
+			if goal.ErrorRate.ErrorRateType == optimizeappsv1alpha1.ErrorRateRequests {
+				query := `scalar(http_req_failed{job="trialRun",instance="{{ .Trial.Name }}"})`
+				result = append(result, newGoalMetric(goal, query))
+			}
+
+		}
+	}
+
+	return result, nil
+}
+
+func (s *K6Source) k6ConfigMapName() string {
+	return fmt.Sprintf("%s-k6-script", s.Scenario.Name)
+}
+
+func (s *K6Source) scriptKey() string {
+	return "script.js"
+}
+
+func (s *K6Source) k6Env() []corev1.EnvVar {
+	var env []corev1.EnvVar
+
+	if vus := s.Scenario.K6.VUs; vus != nil {
+		env = append(env, corev1.EnvVar{Name: "K6_VUS", Value: fmt.Sprintf("%d", *vus)})
+	}
+
+	if duration := s.Scenario.K6.Duration; duration != nil {
+		env = append(env, corev1.EnvVar{Name: "K6_DURATION", Value: duration.Duration.String()})
+	}
+
+	if stages := s.Scenario.K6.Stages; len(stages) > 0 {
+		env = append(env, corev1.EnvVar{Name: "K6_STAGES", Value: k6Stages(stages)})
+	}
+
+	for name, thresholds := range s.Scenario.K6.Thresholds {
+		env = append(env, corev1.EnvVar{Name: "K6_THRESHOLD_" + name, Value: k6Thresholds(thresholds)})
+	}
+
+	return env
+}
+
+// k6Stages renders stages in the comma separated "duration:target" form the K6_STAGES environment
+// variable expects.
+func k6Stages(stages []optimizeappsv1alpha1.K6Stage) string {
+	var s string
+	for i, stage := range stages {
+		if i > 0 {
+			s += ","
+		}
+		s += fmt.Sprintf("%s:%d", stage.Duration.Duration, stage.Target)
+	}
+	return s
+}
+
+func k6Thresholds(thresholds []string) string {
+	var s string
+	for i, t := range thresholds {
+		if i > 0 {
+			s += ","
+		}
+		s += t
+	}
+	return s
+}
+
+func (s *K6Source) k6Latency(lt optimizeappsv1alpha1.LatencyType) string {
+	switch optimizeappsv1alpha1.FixLatency(lt) {
+	case optimizeappsv1alpha1.LatencyMinimum:
+		return "http_req_duration_min"
+	case optimizeappsv1alpha1.LatencyMaximum:
+		return "http_req_duration_max"
+	case optimizeappsv1alpha1.LatencyMean:
+		return "http_req_duration_avg"
+	case optimizeappsv1alpha1.LatencyPercentile50:
+		return "http_req_duration_med"
+	case optimizeappsv1alpha1.LatencyPercentile95:
+		return "http_req_duration_p95"
+	case optimizeappsv1alpha1.LatencyPercentile99:
+		return "http_req_duration_p99"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,38 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generation
+
+import "sigs.k8s.io/kustomize/kyaml/kio"
+
+// LoadGeneratorSource is implemented by each supported load generator integration (StormForger, k6,
+// Locust, JMeter, ...). It combines the trial job wiring (ExperimentSource), the translation of
+// Objective goals into the generator's own PromQL metric names (MetricSource), and any ConfigMap or
+// Secret objects the generator needs mounted into the trial job (kio.Reader) into a single contract,
+// so new generators can be added without the caller needing to know which of these a given scenario
+// actually requires.
+type LoadGeneratorSource interface {
+	ExperimentSource
+	MetricSource
+	kio.Reader
+}
+
+var (
+	_ LoadGeneratorSource = &StormForgerSource{}
+	_ LoadGeneratorSource = &K6Source{}
+	_ LoadGeneratorSource = &LocustSource{}
+	_ LoadGeneratorSource = &JMeterSource{}
+)
@@ -23,7 +23,6 @@ import (
 	optimizev1beta2 "github.com/thestormforge/optimize-controller/v2/api/v1beta2"
 	"github.com/thestormforge/optimize-controller/v2/internal/sfio"
 	corev1 "k8s.io/api/core/v1"
-	"sigs.k8s.io/kustomize/kyaml/kio"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
 
@@ -33,10 +32,6 @@ type LocustSource struct {
 	Application *optimizeappsv1alpha1.Application
 }
 
-var _ ExperimentSource = &LocustSource{} // Update trial job
-var _ MetricSource = &LocustSource{}     // Locust specific metrics
-var _ kio.Reader = &LocustSource{}       // ConfigMap for the locustfile.py
-
 func (s *LocustSource) Update(exp *optimizev1beta2.Experiment) error {
 	if s.Scenario == nil || s.Application == nil {
 		return nil
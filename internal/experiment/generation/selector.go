@@ -0,0 +1,251 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generation
+
+import (
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// matcherRE recognizes a single PromQL-style label matcher: a label name, one of the four matcher
+// operators, and a double-quoted value that may contain escaped characters.
+var matcherRE = regexp.MustCompile(`^\s*([a-zA-Z_][a-zA-Z0-9_]*)\s*(=~|!~|!=|=)\s*"((?:[^"\\]|\\.)*)"\s*`)
+
+// maxCharClassExpansion bounds how many runes a single character class is allowed to expand into,
+// so a class standing in for "any character" (e.g. the one "." compiles to) is rejected as infinite
+// rather than enumerated.
+const maxCharClassExpansion = 64
+
+// maxLiteralAlternatives bounds the total number of strings literalAlternatives is allowed to
+// produce. maxCharClassExpansion only bounds a single character class; without this, concatenating
+// a handful of large-but-individually-allowed character classes would still multiply out to a
+// combinatorial explosion (e.g. five 62-entry classes in a row is 62^5 strings).
+const maxLiteralAlternatives = 512
+
+// bareAlternativeRE matches a single "|"-separated alternative that is a plain literal, so a regexp
+// like "A|B|C" can be treated as an enumeration without invoking the regexp engine at all.
+var bareAlternativeRE = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// convertPrometheusSelector parses a comma-separated list of PromQL label matchers (e.g.
+// `a="A",b!="B",c=~"D|E"`) and converts it into an equivalent metav1.LabelSelector. All four PromQL
+// matcher operators are supported: "=" and "!=" become a single-value In/NotIn requirement (or
+// MatchLabels for "="), and "=~"/"!~" become an In/NotIn requirement whose values are the finite set
+// of strings the regular expression matches. A regular expression that does not reduce to a finite
+// set (e.g. ".+") is rejected with an error identifying the offending matcher.
+func convertPrometheusSelector(metricSelector string) (*metav1.LabelSelector, error) {
+	rest := strings.TrimSpace(metricSelector)
+	if rest == "" {
+		return nil, nil
+	}
+
+	var matchLabels map[string]string
+	var matchExpressions []metav1.LabelSelectorRequirement
+
+	for rest != "" {
+		m := matcherRE.FindStringSubmatch(rest)
+		if m == nil {
+			return nil, fmt.Errorf("invalid metric selector")
+		}
+
+		key, op, rawValue := m[1], m[2], m[3]
+		value, err := unquotePromQLValue(rawValue)
+		if err != nil {
+			return nil, fmt.Errorf("invalid metric selector")
+		}
+		rest = strings.TrimSpace(rest[len(m[0]):])
+
+		switch op {
+		case "=":
+			if matchLabels == nil {
+				matchLabels = make(map[string]string, 1)
+			}
+			matchLabels[key] = value
+
+		case "!=":
+			matchExpressions = append(matchExpressions, metav1.LabelSelectorRequirement{
+				Key: key, Operator: metav1.LabelSelectorOpNotIn, Values: []string{value},
+			})
+
+		case "=~", "!~":
+			values, err := expandRegexpAlternatives(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid metric selector: unsupported regular expression in matcher %s%s%q: %w", key, op, value, err)
+			}
+
+			operator := metav1.LabelSelectorOpIn
+			if op == "!~" {
+				operator = metav1.LabelSelectorOpNotIn
+			}
+			matchExpressions = append(matchExpressions, metav1.LabelSelectorRequirement{
+				Key: key, Operator: operator, Values: values,
+			})
+		}
+
+		if rest == "" {
+			break
+		}
+		if !strings.HasPrefix(rest, ",") {
+			return nil, fmt.Errorf("invalid metric selector")
+		}
+		rest = strings.TrimSpace(strings.TrimPrefix(rest, ","))
+	}
+
+	if matchLabels == nil && matchExpressions == nil {
+		return nil, nil
+	}
+	return &metav1.LabelSelector{MatchLabels: matchLabels, MatchExpressions: matchExpressions}, nil
+}
+
+// unquotePromQLValue resolves the backslash escapes in a matcher value that was captured without
+// its surrounding quotes.
+func unquotePromQLValue(rawValue string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(rawValue); i++ {
+		c := rawValue[i]
+		if c != '\\' {
+			b.WriteByte(c)
+			continue
+		}
+
+		i++
+		if i >= len(rawValue) {
+			return "", fmt.Errorf("trailing escape")
+		}
+		switch rawValue[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		default:
+			b.WriteByte(rawValue[i])
+		}
+	}
+	return b.String(), nil
+}
+
+// expandRegexpAlternatives returns the finite set of strings matched by pattern. The common case of
+// a bare "|"-separated list of literals (e.g. "A|B|C") is handled without compiling a regular
+// expression at all; anything else is validated with the regexp package and then expanded by
+// walking its syntax tree, which only succeeds if the pattern is built entirely out of literals,
+// concatenation, and alternation (i.e. it has no wildcards, repetition, or character classes that
+// would make the match set infinite or unreasonably large).
+func expandRegexpAlternatives(pattern string) ([]string, error) {
+	if values, ok := splitBareAlternatives(pattern); ok {
+		return values, nil
+	}
+
+	if _, err := regexp.Compile(pattern); err != nil {
+		return nil, err
+	}
+
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return nil, err
+	}
+
+	values, ok := literalAlternatives(re.Simplify())
+	if !ok || len(values) == 0 {
+		return nil, fmt.Errorf("does not expand to a finite set of values")
+	}
+
+	return values, nil
+}
+
+// splitBareAlternatives recognizes a regular expression that is nothing more than a "|"-separated
+// list of literal values (no metacharacters at all), the common case of faking an enumeration with a
+// regular expression matcher.
+func splitBareAlternatives(pattern string) ([]string, bool) {
+	values := strings.Split(pattern, "|")
+	for _, v := range values {
+		if !bareAlternativeRE.MatchString(v) {
+			return nil, false
+		}
+	}
+	return values, true
+}
+
+// literalAlternatives walks a regexp syntax tree that has already been confirmed to parse, and
+// returns the finite set of strings it matches, provided it is built entirely out of literals,
+// concatenation, and alternation.
+func literalAlternatives(re *syntax.Regexp) ([]string, bool) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		return []string{string(re.Rune)}, true
+
+	case syntax.OpEmptyMatch:
+		return []string{""}, true
+
+	case syntax.OpCapture:
+		return literalAlternatives(re.Sub[0])
+
+	case syntax.OpCharClass:
+		// A single-character alternation such as "A|B" simplifies down to a character class rather
+		// than an OpAlternate of OpLiterals, so it needs to be expanded the same way; cap the size so
+		// a class standing in for "any character" (e.g. from ".") is correctly treated as infinite.
+		var values []string
+		for i := 0; i+1 < len(re.Rune); i += 2 {
+			lo, hi := re.Rune[i], re.Rune[i+1]
+			if hi-lo > maxCharClassExpansion {
+				return nil, false
+			}
+			for r := lo; r <= hi; r++ {
+				values = append(values, string(r))
+			}
+		}
+		return values, true
+
+	case syntax.OpConcat:
+		values := []string{""}
+		for _, sub := range re.Sub {
+			subValues, ok := literalAlternatives(sub)
+			if !ok {
+				return nil, false
+			}
+
+			if len(values)*len(subValues) > maxLiteralAlternatives {
+				return nil, false
+			}
+
+			next := make([]string, 0, len(values)*len(subValues))
+			for _, v := range values {
+				for _, sv := range subValues {
+					next = append(next, v+sv)
+				}
+			}
+			values = next
+		}
+		return values, true
+
+	case syntax.OpAlternate:
+		var values []string
+		for _, sub := range re.Sub {
+			subValues, ok := literalAlternatives(sub)
+			if !ok {
+				return nil, false
+			}
+			values = append(values, subValues...)
+		}
+		return values, true
+
+	default:
+		return nil, false
+	}
+}
@@ -27,7 +27,6 @@ import (
 	optimizev1beta2 "github.com/thestormforge/optimize-controller/v2/api/v1beta2"
 	"github.com/thestormforge/optimize-controller/v2/internal/sfio"
 	corev1 "k8s.io/api/core/v1"
-	"sigs.k8s.io/kustomize/kyaml/kio"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
 
@@ -37,10 +36,6 @@ type StormForgerSource struct {
 	Application *optimizeappsv1alpha1.Application
 }
 
-var _ ExperimentSource = &StormForgerSource{} // Update trial job
-var _ MetricSource = &StormForgerSource{}     // StormForger specific metrics
-var _ kio.Reader = &StormForgerSource{}       // ConfigMap for the test case file
-
 func (s *StormForgerSource) Update(exp *optimizev1beta2.Experiment) error {
 	if s.Scenario == nil || s.Application == nil {
 		return nil
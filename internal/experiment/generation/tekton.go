@@ -0,0 +1,274 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generation
+
+import (
+	optimizeappsv1alpha1 "github.com/thestormforge/optimize-controller/v2/api/apps/v1alpha1"
+	optimizev1beta2 "github.com/thestormforge/optimize-controller/v2/api/v1beta2"
+	"github.com/thestormforge/optimize-controller/v2/internal/sfio"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+)
+
+// TektonSource generates a Tekton Pipeline used to build and deploy the application
+// prior to running a trial, as an alternative to the default setup tasks.
+type TektonSource struct {
+	Application *optimizeappsv1alpha1.Application
+	Scenario    *optimizeappsv1alpha1.Scenario
+
+	SetupTaskName          string
+	ServiceAccountName     string
+	ClusterRoleName        string
+	ClusterRoleBindingName string
+
+	sfio.ObjectSlice
+}
+
+var _ ExperimentSource = &TektonSource{} // Service account name and setup task
+var _ kio.Reader = &TektonSource{}       // Pipeline, Task, PipelineRun and RBAC
+
+// Update configures the trial template to run the generated Tekton Pipeline as a setup task
+// instead of the default inline setup Job.
+func (s *TektonSource) Update(exp *optimizev1beta2.Experiment) error {
+	tekton := s.tekton()
+	if tekton == nil {
+		return nil
+	}
+
+	exp.Spec.TrialTemplate.Spec.SetupServiceAccountName = s.serviceAccountName()
+	exp.Spec.TrialTemplate.Spec.SetupTasks = append(exp.Spec.TrialTemplate.Spec.SetupTasks,
+		optimizev1beta2.SetupTask{
+			Name: s.setupTaskName(),
+			Args: []string{"tekton", "$(MODE)"},
+		})
+
+	s.ObjectSlice = append(s.ObjectSlice, s.serviceAccount(), s.clusterRole(), s.clusterRoleBinding(), s.loadTestTask(), s.pipeline(tekton), s.pipelineRun())
+
+	return nil
+}
+
+func (s *TektonSource) tekton() *optimizeappsv1alpha1.Tekton {
+	if s.Application == nil || s.Application.Build == nil {
+		return nil
+	}
+	return s.Application.Build.Tekton
+}
+
+func (s *TektonSource) setupTaskName() string {
+	if s.SetupTaskName != "" {
+		return s.SetupTaskName
+	}
+	return "tekton"
+}
+
+func (s *TektonSource) serviceAccountName() string {
+	if s.ServiceAccountName != "" {
+		return s.ServiceAccountName
+	}
+	return "redsky-tekton"
+}
+
+func (s *TektonSource) builder() string {
+	if b := s.tekton().Builder; b != "" {
+		return b
+	}
+	return "buildpacks"
+}
+
+// serviceAccount is the identity used by the generated PipelineRun to create and manage
+// the pipeline run it triggers, mirroring `k8s.EnsureSetupServiceAccount` for the Tekton backend.
+func (s *TektonSource) serviceAccount() *corev1.ServiceAccount {
+	return &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: s.serviceAccountName(),
+		},
+	}
+}
+
+func (s *TektonSource) clusterRoleName() string {
+	if s.ClusterRoleName != "" {
+		return s.ClusterRoleName
+	}
+	return "redsky-tekton"
+}
+
+func (s *TektonSource) clusterRoleBindingName() string {
+	if s.ClusterRoleBindingName != "" {
+		return s.ClusterRoleBindingName
+	}
+	return "redsky-setup-tekton"
+}
+
+func (s *TektonSource) clusterRole() *rbacv1.ClusterRole {
+	return &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: s.clusterRoleName(),
+		},
+		Rules: []rbacv1.PolicyRule{
+			{
+				Verbs:     []string{"get", "create", "delete", "list", "watch"},
+				APIGroups: []string{"tekton.dev"},
+				Resources: []string{"pipelineruns", "taskruns"},
+			},
+		},
+	}
+}
+
+func (s *TektonSource) clusterRoleBinding() *rbacv1.ClusterRoleBinding {
+	return &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: s.clusterRoleBindingName(),
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "ClusterRole",
+			Name:     s.clusterRoleName(),
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind: "ServiceAccount",
+				Name: s.serviceAccountName(),
+			},
+		},
+	}
+}
+
+// loadTestTask wraps the existing load generation step (normally run directly by the trial Job)
+// as a Tekton Task so it can be run as the final step of the generated Pipeline.
+func (s *TektonSource) loadTestTask() *unstructured.Unstructured {
+	scenario := "trial"
+	if s.Scenario != nil && s.Scenario.Name != "" {
+		scenario = s.Scenario.Name
+	}
+
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "tekton.dev/v1beta1",
+		"kind":       "Task",
+		"metadata": map[string]interface{}{
+			"name": "redsky-run-trial",
+		},
+		"spec": map[string]interface{}{
+			"params": []interface{}{
+				map[string]interface{}{"name": "trial-name"},
+			},
+			"steps": []interface{}{
+				map[string]interface{}{
+					"name":  "run-trial",
+					"image": trialJobImage(scenario),
+					"args":  []interface{}{"$(params.trial-name)"},
+				},
+			},
+		},
+	}}
+}
+
+// pipeline returns the generated Pipeline describing the clone/build/deploy/trial sequence.
+func (s *TektonSource) pipeline(tekton *optimizeappsv1alpha1.Tekton) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "tekton.dev/v1beta1",
+		"kind":       "Pipeline",
+		"metadata": map[string]interface{}{
+			"name": "redsky-trial-build",
+		},
+		"spec": map[string]interface{}{
+			"params": []interface{}{
+				map[string]interface{}{"name": "repo-url"},
+				map[string]interface{}{"name": "image"},
+				map[string]interface{}{"name": "trial-name"},
+			},
+			"workspaces": []interface{}{
+				map[string]interface{}{"name": "source"},
+			},
+			"tasks": []interface{}{
+				map[string]interface{}{
+					"name":    "fetch-source",
+					"taskRef": map[string]interface{}{"name": "git-clone"},
+					"params": []interface{}{
+						map[string]interface{}{"name": "url", "value": "$(params.repo-url)"},
+					},
+					"workspaces": []interface{}{
+						map[string]interface{}{"name": "output", "workspace": "source"},
+					},
+				},
+				map[string]interface{}{
+					"name":     "build-image",
+					"taskRef":  map[string]interface{}{"name": s.builder()},
+					"runAfter": []interface{}{"fetch-source"},
+					"params": []interface{}{
+						map[string]interface{}{"name": "IMAGE", "value": "$(params.image)"},
+					},
+					"workspaces": []interface{}{
+						map[string]interface{}{"name": "source", "workspace": "source"},
+					},
+				},
+				map[string]interface{}{
+					"name":     "apply-manifests",
+					"taskRef":  map[string]interface{}{"name": "kustomize"},
+					"runAfter": []interface{}{"build-image"},
+					"workspaces": []interface{}{
+						map[string]interface{}{"name": "source", "workspace": "source"},
+					},
+				},
+				map[string]interface{}{
+					"name":     "run-trial",
+					"taskRef":  map[string]interface{}{"name": "redsky-run-trial"},
+					"runAfter": []interface{}{"apply-manifests"},
+					"params": []interface{}{
+						map[string]interface{}{"name": "trial-name", "value": "$(params.trial-name)"},
+					},
+				},
+			},
+		},
+	}}
+}
+
+// pipelineRun triggers the generated Pipeline using the setup service account, the same way
+// the controller currently creates a setup Job.
+func (s *TektonSource) pipelineRun() *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "tekton.dev/v1beta1",
+		"kind":       "PipelineRun",
+		"metadata": map[string]interface{}{
+			"name": "redsky-trial-build",
+		},
+		"spec": map[string]interface{}{
+			"serviceAccountName": s.serviceAccountName(),
+			"pipelineRef":        map[string]interface{}{"name": "redsky-trial-build"},
+			"params": []interface{}{
+				map[string]interface{}{"name": "repo-url", "value": ""},
+				map[string]interface{}{"name": "image", "value": ""},
+				map[string]interface{}{"name": "trial-name", "value": "$(TRIAL_NAME)"},
+			},
+			"workspaces": []interface{}{
+				map[string]interface{}{
+					"name": "source",
+					"volumeClaimTemplate": map[string]interface{}{
+						"spec": map[string]interface{}{
+							"accessModes": []interface{}{"ReadWriteOnce"},
+							"resources": map[string]interface{}{
+								"requests": map[string]interface{}{"storage": "1Gi"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}}
+}
@@ -78,6 +78,9 @@ func (g *Generator) Execute(output kio.Writer) error {
 			// Expand resource references using Konjure
 			g.FilterOptions.NewFilter(application.WorkingDirectory(&g.Application)),
 
+			// Drop Helm hook-only objects (e.g. install/upgrade Jobs) before scanning for parameters
+			scan.ExcludeHelmHooks,
+
 			// Scan the resources and transform them into an experiment (and it's supporting resources)
 			&scan.Scanner{
 				Transformer: &generation.Transformer{
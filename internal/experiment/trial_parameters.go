@@ -0,0 +1,86 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experiment
+
+import (
+	"fmt"
+	"strings"
+
+	optimizev1beta2 "github.com/thestormforge/optimize-controller/v2/api/v1beta2"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// trialParameterToken is the token substituted in an unstructured trial template, e.g.
+// "${trialParameters.replicas}" for a TrialParameterSpec named "replicas".
+func trialParameterToken(name string) string {
+	return "${trialParameters." + name + "}"
+}
+
+// SubstituteTrialParameters replaces every "${trialParameters.<Name>}" token found in u's string
+// fields with the assignment value referenced by the matching TrialParameterSpec. This lets a
+// ConfigMap-backed or inline unstructured trial template stand in for a typed TrialSpec without
+// the controller needing to know anything about the runner's CRD.
+func SubstituteTrialParameters(u *unstructured.Unstructured, params []optimizev1beta2.TrialParameterSpec, assignments []optimizev1beta2.Assignment) error {
+	values := make(map[string]string, len(params))
+	for _, tp := range params {
+		v, err := trialParameterValue(tp, assignments)
+		if err != nil {
+			return err
+		}
+		values[trialParameterToken(tp.Name)] = v
+	}
+
+	u.Object = substituteTrialParameterTokens(u.Object, values).(map[string]interface{})
+	return nil
+}
+
+// trialParameterValue looks up the assignment referenced by a TrialParameterSpec.
+func trialParameterValue(tp optimizev1beta2.TrialParameterSpec, assignments []optimizev1beta2.Assignment) (string, error) {
+	for _, a := range assignments {
+		if a.Name == tp.Reference {
+			return a.Value.String(), nil
+		}
+	}
+	return "", fmt.Errorf("trial parameter %q references unknown parameter %q", tp.Name, tp.Reference)
+}
+
+// substituteTrialParameterTokens walks an arbitrary unstructured value (the result of JSON
+// unmarshalling), replacing any occurrence of a token key with its corresponding value in every
+// string it finds.
+func substituteTrialParameterTokens(in interface{}, values map[string]string) interface{} {
+	switch t := in.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(t))
+		for k, v := range t {
+			out[k] = substituteTrialParameterTokens(v, values)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(t))
+		for i, v := range t {
+			out[i] = substituteTrialParameterTokens(v, values)
+		}
+		return out
+	case string:
+		for token, value := range values {
+			t = strings.ReplaceAll(t, token, value)
+		}
+		return t
+	default:
+		return in
+	}
+}
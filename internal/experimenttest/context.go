@@ -0,0 +1,59 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experimenttest
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Context bundles a context.Context with a namespace isolated for a single test, so that
+// multiple tests (and the trials within them) can run concurrently against the same cluster.
+type Context struct {
+	context.Context
+	Framework *Framework
+	Namespace string
+}
+
+// Context creates a new namespace for the test and returns a Context scoped to it. The namespace
+// (and everything created in it) is deleted when the test completes via t.Cleanup.
+func (f *Framework) Context(t *testing.T) *Context {
+	t.Helper()
+
+	ns := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "experimenttest-",
+			Labels:       map[string]string{"experimenttest.stormforge.io/test": t.Name()},
+		},
+	}
+
+	ctx := context.Background()
+	if err := f.Client.Create(ctx, ns); err != nil {
+		t.Fatalf("experimenttest: unable to create test namespace: %v", err)
+	}
+
+	t.Cleanup(func() {
+		if err := f.Client.Delete(context.Background(), ns); err != nil {
+			t.Errorf("experimenttest: unable to delete test namespace %q: %v", ns.Name, err)
+		}
+	})
+
+	return &Context{Context: ctx, Framework: f, Namespace: ns.Name}
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package experimenttest provides a small end-to-end test harness for exercising experiment and
+// trial behavior against a real (or envtest) control plane, modeled on the operator-sdk test
+// framework: a shared Framework owns the client for the whole test binary, and each test gets its
+// own namespace-isolated Context with automatic cleanup.
+package experimenttest
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	optimizeappsv1alpha1 "github.com/thestormforge/optimize-controller/v2/api/apps/v1alpha1"
+	optimizev1beta2 "github.com/thestormforge/optimize-controller/v2/api/v1beta2"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+)
+
+var (
+	kubeconfig string
+	local      bool
+)
+
+func init() {
+	flag.StringVar(&kubeconfig, "kubeconfig", "", "path to the kubeconfig of an existing cluster to run the e2e tests against")
+	flag.BoolVar(&local, "local", false, "start a local envtest control plane instead of using -kubeconfig")
+}
+
+// Framework owns the client used by every test in the binary. It is created once by TestMain and
+// retrieved by individual tests via NewFramework.
+type Framework struct {
+	Client client.Client
+	Scheme *runtime.Scheme
+
+	testEnv *envtest.Environment
+}
+
+var framework *Framework
+
+// TestMain starts the Framework (either against -kubeconfig, a local envtest control plane
+// selected with -local, or the ambient in-cluster/KUBECONFIG configuration), runs the test
+// binary, and tears the control plane back down. Packages using this harness should call this
+// from their own TestMain:
+//
+//	func TestMain(m *testing.M) { os.Exit(experimenttest.TestMain(m)) }
+func TestMain(m *testing.M) int {
+	if !flag.Parsed() {
+		flag.Parse()
+	}
+
+	fw, err := newFramework()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "experimenttest: %v\n", err)
+		return 1
+	}
+	framework = fw
+
+	code := m.Run()
+
+	if err := fw.stop(); err != nil {
+		fmt.Fprintf(os.Stderr, "experimenttest: %v\n", err)
+	}
+
+	return code
+}
+
+// NewFramework returns the Framework started by TestMain, failing the test immediately if
+// TestMain was not used to initialize it.
+func NewFramework(t *testing.T) *Framework {
+	t.Helper()
+	if framework == nil {
+		t.Fatal("experimenttest: the test binary's TestMain must call experimenttest.TestMain")
+	}
+	return framework
+}
+
+func newFramework() (*Framework, error) {
+	scheme := runtime.NewScheme()
+	for _, add := range []func(*runtime.Scheme) error{
+		clientgoscheme.AddToScheme,
+		optimizev1beta2.AddToScheme,
+		optimizeappsv1alpha1.AddToScheme,
+	} {
+		if err := add(scheme); err != nil {
+			return nil, err
+		}
+	}
+
+	cfg, testEnv, err := restConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Framework{Client: c, Scheme: scheme, testEnv: testEnv}, nil
+}
+
+// restConfig resolves the configuration for the control plane the tests should run against: an
+// explicit -kubeconfig takes priority, followed by a local envtest control plane started with
+// -local, falling back to the ambient KUBECONFIG/in-cluster configuration.
+func restConfig() (*rest.Config, *envtest.Environment, error) {
+	switch {
+	case kubeconfig != "":
+		cfg, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+		return cfg, nil, err
+
+	case local:
+		testEnv := &envtest.Environment{
+			CRDDirectoryPaths: []string{filepath.Join("..", "..", "config", "crd", "bases")},
+		}
+		cfg, err := testEnv.Start()
+		return cfg, testEnv, err
+
+	default:
+		cfg, err := rest.InClusterConfig()
+		if err == nil {
+			return cfg, nil, nil
+		}
+		cfg, err = clientcmd.NewDefaultClientConfigLoadingRules().Load()
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to resolve a cluster configuration, use -kubeconfig or -local: %w", err)
+		}
+		restCfg, err := clientcmd.NewDefaultClientConfig(*cfg, &clientcmd.ConfigOverrides{}).ClientConfig()
+		return restCfg, nil, err
+	}
+}
+
+func (f *Framework) stop() error {
+	if f.testEnv == nil {
+		return nil
+	}
+	return f.testEnv.Stop()
+}
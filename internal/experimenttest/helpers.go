@@ -0,0 +1,167 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package experimenttest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/thestormforge/optimize-controller/v2/internal/experiment/generation"
+	"github.com/thestormforge/optimize-controller/v2/internal/sfio"
+
+	optimizev1beta2 "github.com/thestormforge/optimize-controller/v2/api/v1beta2"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// defaultBackoff is used by the Wait* helpers: ~30s total, starting at 500ms and backing off by 1.5x.
+func defaultBackoff() wait.Backoff {
+	return wait.Backoff{
+		Duration: 500 * time.Millisecond,
+		Factor:   1.5,
+		Steps:    15,
+	}
+}
+
+// CreateExperiment synthesizes an experiment in the test namespace, reusing the generation
+// filters to assign the name and namespace and to ensure a dedicated setup service account is
+// created alongside it.
+func (c *Context) CreateExperiment(t *testing.T, name string, exp *optimizev1beta2.Experiment) *optimizev1beta2.Experiment {
+	t.Helper()
+
+	exp = exp.DeepCopy()
+	sa := generation.EnsureSetupServiceAccount(exp)
+
+	nodes, err := (sfio.ObjectSlice{exp}).Read()
+	if err != nil {
+		t.Fatalf("experimenttest: unable to serialize experiment: %v", err)
+	}
+	if err := nodes[0].PipeE(generation.SetExperimentName(name), generation.SetNamespace(c.Namespace)); err != nil {
+		t.Fatalf("experimenttest: unable to name experiment: %v", err)
+	}
+
+	w := sfio.ObjectList{}
+	if err := w.Write(nodes); err != nil {
+		t.Fatalf("experimenttest: unable to deserialize experiment: %v", err)
+	}
+	result := w.Items[0].Object.(*optimizev1beta2.Experiment)
+
+	if sa != nil {
+		sa.Namespace = c.Namespace
+		if err := c.Framework.Client.Create(c, sa); err != nil {
+			t.Fatalf("experimenttest: unable to create setup service account: %v", err)
+		}
+		t.Cleanup(func() { _ = c.Framework.Client.Delete(c, sa) })
+	}
+
+	if err := c.Framework.Client.Create(c, result); err != nil {
+		t.Fatalf("experimenttest: unable to create experiment: %v", err)
+	}
+	t.Cleanup(func() { _ = c.Framework.Client.Delete(c, result) })
+
+	return result
+}
+
+// WaitForExperimentCondition polls the named experiment until it reports the supplied condition
+// type as True, or the backoff is exhausted.
+func (c *Context) WaitForExperimentCondition(t *testing.T, name string, condType optimizev1beta2.ExperimentConditionType) *optimizev1beta2.Experiment {
+	t.Helper()
+
+	exp := &optimizev1beta2.Experiment{}
+	err := wait.ExponentialBackoff(defaultBackoff(), func() (bool, error) {
+		if err := c.Framework.Client.Get(c, types.NamespacedName{Namespace: c.Namespace, Name: name}, exp); err != nil {
+			if apierrors.IsNotFound(err) {
+				return false, nil
+			}
+			return false, err
+		}
+
+		for _, cond := range exp.Status.Conditions {
+			if cond.Type == condType && cond.Status == corev1.ConditionTrue {
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+	if err != nil {
+		t.Fatalf("experimenttest: timed out waiting for experiment %q to report %q: %v", name, condType, err)
+	}
+
+	return exp
+}
+
+// WaitForTrialPhase polls the named trial until it reports the expected phase, or the backoff is
+// exhausted.
+func (c *Context) WaitForTrialPhase(t *testing.T, name, phase string) *optimizev1beta2.Trial {
+	t.Helper()
+
+	trial := &optimizev1beta2.Trial{}
+	err := wait.ExponentialBackoff(defaultBackoff(), func() (bool, error) {
+		if err := c.Framework.Client.Get(c, types.NamespacedName{Namespace: c.Namespace, Name: name}, trial); err != nil {
+			if apierrors.IsNotFound(err) {
+				return false, nil
+			}
+			return false, err
+		}
+		return trial.Status.Phase == phase, nil
+	})
+	if err != nil {
+		t.Fatalf("experimenttest: timed out waiting for trial %q to reach phase %q: %v", name, phase, err)
+	}
+
+	return trial
+}
+
+// RunTrialAndAssert creates a trial for the experiment with the supplied assignments, waits for
+// it to complete, and asserts that the observed values match the expected metrics.
+func (c *Context) RunTrialAndAssert(t *testing.T, exp *optimizev1beta2.Experiment, assignments []optimizev1beta2.Assignment, expectedMetrics map[string]string) *optimizev1beta2.Trial {
+	t.Helper()
+
+	trial := &optimizev1beta2.Trial{}
+	trial.Namespace = c.Namespace
+	trial.GenerateName = exp.Name + "-"
+	trial.Labels = map[string]string{optimizev1beta2.LabelExperiment: exp.Name}
+	trial.Spec.ExperimentRef = &corev1.ObjectReference{Name: exp.Name, Namespace: exp.Namespace}
+	trial.Spec.Assignments = assignments
+
+	if err := c.Framework.Client.Create(c, trial); err != nil {
+		t.Fatalf("experimenttest: unable to create trial: %v", err)
+	}
+	t.Cleanup(func() { _ = c.Framework.Client.Delete(c, trial) })
+
+	trial = c.WaitForTrialPhase(t, trial.Name, "Completed")
+
+	values := make(map[string]string, len(trial.Status.Values))
+	for _, v := range trial.Status.Values {
+		values[v.Name] = v.Value
+	}
+
+	for name, want := range expectedMetrics {
+		got, ok := values[name]
+		if !ok {
+			t.Errorf("experimenttest: trial %q did not report metric %q", trial.Name, name)
+			continue
+		}
+		if got != want {
+			t.Errorf("experimenttest: trial %q metric %q = %q, want %q", trial.Name, name, got, want)
+		}
+	}
+
+	return trial
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metric
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	optimizev1beta2 "github.com/thestormforge/optimize-controller/v2/api/v1beta2"
+)
+
+// AlertFiredError indicates a "prometheus-alert" metric found a matching alert firing at or above its
+// configured severity threshold during the sampling window. Unlike CaptureError, this is never
+// retried: it always fails the trial immediately, no matter how many collection attempts remain.
+type AlertFiredError struct {
+	// AlertName is the name of the alert that fired
+	AlertName string
+	// Annotations are the Alertmanager annotations of the firing alert, recorded on the trial
+	Annotations map[string]string
+}
+
+func (e *AlertFiredError) Error() string {
+	return fmt.Sprintf("alert %s is firing", e.AlertName)
+}
+
+// alertSeverity ranks the conventional Alertmanager "severity" label values from least to most severe;
+// anything unrecognized (including an unset label) is treated as the least severe.
+var alertSeverity = map[string]int{
+	"none":     0,
+	"info":     1,
+	"warning":  2,
+	"critical": 3,
+}
+
+// captureAlertMetric polls Alertmanager's v2 API for active alerts named m.Query, failing the trial
+// immediately (via AlertFiredError) if one is firing at or above the configured minimum severity.
+// The query is "<alertName>" or "<alertName>:<minSeverity>" (default minSeverity "warning"); m.URL is
+// the base address of the Alertmanager to query, e.g. "http://alertmanager.monitoring:9093". There is
+// no meaningful point-in-time value for this metric type, so a non-firing result is always 0.
+func captureAlertMetric(ctx context.Context, m *optimizev1beta2.Metric) (float64, float64, error) {
+	alertName, minSeverity := m.Query, "warning"
+	if i := strings.LastIndex(m.Query, ":"); i >= 0 {
+		alertName, minSeverity = m.Query[:i], m.Query[i+1:]
+	}
+	threshold, ok := alertSeverity[minSeverity]
+	if !ok {
+		return 0, 0, fmt.Errorf("unknown alert severity: %s", minSeverity)
+	}
+
+	alerts, err := queryAlerts(ctx, m.URL, alertName)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for _, a := range alerts {
+		if a.Status.State != "active" {
+			continue
+		}
+		if alertSeverity[a.Labels["severity"]] < threshold {
+			continue
+		}
+		return 0, 0, &AlertFiredError{AlertName: alertName, Annotations: a.Annotations}
+	}
+
+	return 0, 0, nil
+}
+
+// alertmanagerAlert is the subset of Alertmanager's `GET /api/v2/alerts` response this package needs.
+type alertmanagerAlert struct {
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+	Status      struct {
+		State string `json:"state"`
+	} `json:"status"`
+}
+
+// queryAlerts fetches the active alerts named alertName from the Alertmanager at baseURL.
+func queryAlerts(ctx context.Context, baseURL, alertName string) ([]alertmanagerAlert, error) {
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/") + "/api/v2/alerts")
+	if err != nil {
+		return nil, err
+	}
+	q := u.Query()
+	q.Set("filter", fmt.Sprintf(`alertname="%s"`, alertName))
+	q.Set("active", "true")
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status querying alerts from %s: %s", baseURL, resp.Status)
+	}
+
+	var alerts []alertmanagerAlert
+	if err := json.NewDecoder(resp.Body).Decode(&alerts); err != nil {
+		return nil, fmt.Errorf("could not parse alertmanager response: %w", err)
+	}
+	return alerts, nil
+}
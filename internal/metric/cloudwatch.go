@@ -0,0 +1,122 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metric
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	optimizev1beta2 "github.com/thestormforge/optimize-controller/v2/api/v1beta2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// captureCloudWatchMetric evaluates m.Query over [startTime, completionTime] as a CloudWatch
+// GetMetricData query. The query is expected in "namespace/metricName/statistic" form, e.g.
+// "AWS/RDS/CPUUtilization/Average"; statistic defaults to "Average" if omitted. Credentials are
+// read from the Secret referenced by m.SecretRef (keys "aws-access-key-id"/"aws-secret-access-key"),
+// falling back to the SDK's normal credential chain (environment, shared config, instance role).
+func captureCloudWatchMetric(ctx context.Context, c client.Client, ns string, m *optimizev1beta2.Metric, startTime, completionTime time.Time) (float64, float64, error) {
+	namespace, metricName, statistic, err := parseCloudWatchQuery(m.Query)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	accessKeyID, err := secretValue(ctx, c, ns, m.SecretRef, "aws-access-key-id")
+	if err != nil {
+		return 0, 0, err
+	}
+	secretAccessKey, err := secretValue(ctx, c, ns, m.SecretRef, "aws-secret-access-key")
+	if err != nil {
+		return 0, 0, err
+	}
+
+	opts := session.Options{SharedConfigState: session.SharedConfigEnable}
+	if accessKeyID != "" && secretAccessKey != "" {
+		opts.Config.Credentials = credentials.NewStaticCredentials(accessKeyID, secretAccessKey, "")
+	}
+	sess, err := session.NewSessionWithOptions(opts)
+	if err != nil {
+		return 0, 0, err
+	}
+	svc := cloudwatch.New(sess)
+
+	out, err := svc.GetMetricDataWithContext(ctx, &cloudwatch.GetMetricDataInput{
+		StartTime: aws.Time(startTime),
+		EndTime:   aws.Time(completionTime),
+		MetricDataQueries: []*cloudwatch.MetricDataQuery{{
+			Id: aws.String("m1"),
+			MetricStat: &cloudwatch.MetricStat{
+				Metric: &cloudwatch.Metric{
+					Namespace:  aws.String(namespace),
+					MetricName: aws.String(metricName),
+				},
+				Period: aws.Int64(cloudWatchPeriod(completionTime.Sub(startTime))),
+				Stat:   aws.String(statistic),
+			},
+		}},
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if len(out.MetricDataResults) != 1 || len(out.MetricDataResults[0].Values) == 0 {
+		return 0, 0, fmt.Errorf("no data points returned for query: %s", m.Query)
+	}
+
+	return *out.MetricDataResults[0].Values[0], math.NaN(), nil
+}
+
+// validSubMinuteCloudWatchPeriods are the only periods under a minute GetMetricData accepts.
+var validSubMinuteCloudWatchPeriods = []int64{1, 5, 10, 30, 60}
+
+// cloudWatchPeriod snaps d to a period GetMetricData will accept: periods under a minute must be
+// exactly one of validSubMinuteCloudWatchPeriods, and periods of a minute or longer must be an
+// exact multiple of 60. A trial duration is effectively never an exact multiple of 60, so this
+// rounds up to the next valid period rather than sending the raw duration and letting the request
+// fail validation.
+func cloudWatchPeriod(d time.Duration) int64 {
+	secs := int64(math.Ceil(d.Seconds()))
+	if secs <= 60 {
+		for _, p := range validSubMinuteCloudWatchPeriods {
+			if secs <= p {
+				return p
+			}
+		}
+	}
+	return ((secs + 59) / 60) * 60
+}
+
+// parseCloudWatchQuery splits a "namespace/metricName[/statistic]" query into its parts.
+func parseCloudWatchQuery(query string) (namespace, metricName, statistic string, err error) {
+	parts := strings.Split(query, "/")
+	if len(parts) < 2 {
+		return "", "", "", fmt.Errorf("invalid CloudWatch query %q, expected namespace/metricName[/statistic]", query)
+	}
+
+	statistic = "Average"
+	if len(parts) > 2 {
+		statistic = parts[2]
+	}
+	return parts[0], parts[1], statistic, nil
+}
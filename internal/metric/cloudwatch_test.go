@@ -0,0 +1,68 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metric
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCloudWatchPeriod(t *testing.T) {
+	cases := []struct {
+		desc     string
+		duration time.Duration
+		expected int64
+	}{
+		{
+			desc:     "sub-second rounds up to one second",
+			duration: 500 * time.Millisecond,
+			expected: 1,
+		},
+		{
+			desc:     "exact sub-minute value",
+			duration: 30 * time.Second,
+			expected: 30,
+		},
+		{
+			desc:     "sub-minute value rounds up to next valid period",
+			duration: 20 * time.Second,
+			expected: 30,
+		},
+		{
+			desc:     "exactly one minute",
+			duration: 60 * time.Second,
+			expected: 60,
+		},
+		{
+			desc:     "typical trial duration rounds up to a multiple of 60",
+			duration: 185 * time.Second,
+			expected: 240,
+		},
+		{
+			desc:     "exact multiple of 60 is unchanged",
+			duration: 300 * time.Second,
+			expected: 300,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			assert.Equal(t, c.expected, cloudWatchPeriod(c.duration))
+		})
+	}
+}
@@ -26,30 +26,35 @@ import (
 	optimizev1beta2 "github.com/thestormforge/optimize-controller/v2/api/v1beta2"
 	"github.com/thestormforge/optimize-controller/v2/internal/template"
 	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-// CaptureMetric captures a point-in-time metric value and it's error rate.
-func CaptureMetric(ctx context.Context, log logr.Logger, trial *optimizev1beta2.Trial, metric *optimizev1beta2.Metric, target runtime.Object) (float64, float64, error) {
+// CaptureMetric captures a point-in-time metric value and it's error rate. The actual capture is
+// dispatched to the Capturer registered for the metric's type (see RegisterCapturer), so third-party
+// metric sources can plug in without changes here.
+func CaptureMetric(ctx context.Context, log logr.Logger, c client.Client, trial *optimizev1beta2.Trial, metric *optimizev1beta2.Metric, target runtime.Object) (float64, float64, error) {
 	// Execute the queries as Go templates
 	var err error
 	if metric.Query, metric.ErrorQuery, err = template.New().RenderMetricQueries(metric, trial, target); err != nil {
 		return 0, 0, err
 	}
 
-	// Capture the value based on the metric type
-	switch metric.Type {
-	case optimizev1beta2.MetricKubernetes, "":
-		value, err := strconv.ParseFloat(metric.Query, 64)
-		return value, math.NaN(), err
-	case optimizev1beta2.MetricPrometheus:
-		return capturePrometheusMetric(ctx, log, metric, trial.Status.CompletionTime.Time)
-	case optimizev1beta2.MetricDatadog:
-		return captureDatadogMetric(metric, trial.Status.StartTime.Time, trial.Status.CompletionTime.Time)
-	case optimizev1beta2.MetricJSONPath:
-		return captureJSONPathMetric(metric)
-	case optimizev1beta2.MetricNewRelic:
-		return captureNewRelicMetric(metric, trial.Status.StartTime.Time, trial.Status.CompletionTime.Time)
-	default:
-		return 0, 0, fmt.Errorf("unknown metric type: %s", metric.Type)
+	t := metric.Type
+	if t == "" {
+		t = optimizev1beta2.MetricKubernetes
 	}
+
+	capturer, ok := capturers[t]
+	if !ok {
+		return 0, 0, fmt.Errorf("unknown metric type: %s", t)
+	}
+	return capturer.Capture(ctx, log, c, trial, metric, target)
+}
+
+// captureBuiltinMetric evaluates the rendered query directly as the metric value, used for the
+// Kubernetes built-in metric type where the Go template itself computes the value (e.g. from the
+// target object) rather than querying an external source.
+func captureBuiltinMetric(m *optimizev1beta2.Metric) (float64, float64, error) {
+	value, err := strconv.ParseFloat(m.Query, 64)
+	return value, math.NaN(), err
 }
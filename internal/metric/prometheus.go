@@ -20,6 +20,12 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -46,12 +52,11 @@ func (e *CaptureError) Error() string {
 }
 
 func capturePrometheusMetric(ctx context.Context, log logr.Logger, m *optimizev1beta2.Metric, completionTime time.Time) (value float64, valueError float64, err error) {
-	// Get the Prometheus API
-	c, err := prom.NewClient(prom.Config{Address: m.URL})
+	// Get the (possibly already cached) Prometheus API
+	promAPI, err := prometheusClients.get(m.URL)
 	if err != nil {
 		return 0, 0, err
 	}
-	promAPI := promv1.NewAPI(c)
 
 	// Make sure Prometheus is ready
 	lastScrapeEndTime, err := checkReady(ctx, promAPI, completionTime)
@@ -83,20 +88,195 @@ func capturePrometheusMetric(ctx context.Context, log logr.Logger, m *optimizev1
 	}
 
 	// Execute the error query (if configured)
-	if m.ErrorQuery != "" {
+	switch {
+	case m.ErrorQuery != "":
 		valueError, err = queryScalar(ctx, promAPI, m.ErrorQuery, completionTime)
 		if err != nil {
 			return 0, 0, err
 		}
+	default:
+		// No explicit error query: if this looks like a `histogram_quantile(...)` call, derive the
+		// standard deviation from the underlying bucket distribution instead of always reporting 0.
+		// A failure here (e.g. the series isn't actually bucketed, or uses classic rather than
+		// native histograms in a way we can't introspect) is not fatal -- it just leaves valueError
+		// at its zero value, same as if no error query had been configured at all.
+		if bucketQuery, ok := histogramBucketQuery(m.Query); ok {
+			if stddev, serr := queryHistogramStddev(ctx, promAPI, bucketQuery, completionTime); serr == nil {
+				valueError = stddev
+			}
+		}
 	}
 
 	return value, valueError, nil
 }
 
+// histogramQuantileRE extracts the bucket distribution expression (the second argument) out of a
+// `histogram_quantile(phi, <bucket expression>)` call.
+var histogramQuantileRE = regexp.MustCompile(`(?s)^\s*histogram_quantile\(\s*[^,]+,\s*(.+)\)\s*$`)
+
+// histogramBucketQuery returns the bucket distribution expression of a `histogram_quantile(...)`
+// query, e.g. "sum(rate(foo_bucket[5m])) by (le)" out of
+// "histogram_quantile(0.99, sum(rate(foo_bucket[5m])) by (le))".
+func histogramBucketQuery(q string) (string, bool) {
+	m := histogramQuantileRE.FindStringSubmatch(q)
+	if m == nil {
+		return "", false
+	}
+	return strings.TrimSpace(m[1]), true
+}
+
+// queryHistogramStddev evaluates a bucket distribution query (one sample per "le" bucket boundary,
+// as produced by the classic Prometheus histogram pattern) and derives the standard deviation of the
+// underlying distribution: pᵢ is each bucket's share of the total count, midᵢ is the midpoint between
+// its "le" boundary and the previous one, and stddev = sqrt(∑pᵢ·midᵢ² - (∑pᵢ·midᵢ)²).
+func queryHistogramStddev(ctx context.Context, api promv1.API, bucketQuery string, t time.Time) (float64, error) {
+	v, _, err := api.Query(ctx, bucketQuery, t)
+	if err != nil {
+		return 0, err
+	}
+
+	vec, ok := v.(*model.Vector)
+	if !ok || len(*vec) == 0 {
+		return 0, fmt.Errorf("expected a vector of \"le\" buckets, got %s", v.Type())
+	}
+
+	type bucket struct {
+		le    float64
+		count float64
+	}
+	buckets := make([]bucket, 0, len(*vec))
+	for _, s := range *vec {
+		le, err := strconv.ParseFloat(string(s.Metric["le"]), 64)
+		if err != nil || math.IsInf(le, 1) {
+			continue
+		}
+		buckets = append(buckets, bucket{le: le, count: float64(s.Value)})
+	}
+	if len(buckets) == 0 {
+		return 0, fmt.Errorf("no finite \"le\" buckets in result")
+	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i].le < buckets[j].le })
+
+	total := buckets[len(buckets)-1].count
+	if total <= 0 {
+		return 0, fmt.Errorf("empty histogram")
+	}
+
+	var mean, meanSq float64
+	prevLe, prevCount := 0.0, 0.0
+	for _, b := range buckets {
+		p := (b.count - prevCount) / total
+		mid := (prevLe + b.le) / 2
+		mean += p * mid
+		meanSq += p * mid * mid
+		prevLe, prevCount = b.le, b.count
+	}
+
+	variance := meanSq - mean*mean
+	if variance < 0 {
+		variance = 0
+	}
+	return math.Sqrt(variance), nil
+}
+
 // Choose lower then normal default scrape parameters
 // TODO We could use `api.Config` to get the actual values (global defaults and per-target settings)
 const scrapeInterval = 5 * time.Second // Prometheus default is 1m
 
+// promTransport is shared by every cached Prometheus client so TCP connections are pooled
+// across metric captures instead of being re-established for every trial.
+var promTransport = &http.Transport{
+	MaxIdleConns:          100,
+	MaxIdleConnsPerHost:   10,
+	IdleConnTimeout:       90 * time.Second,
+	TLSHandshakeTimeout:   10 * time.Second,
+	ResponseHeaderTimeout: 30 * time.Second,
+}
+
+// promAPICache caches promv1.API clients by address so repeated captures against the same
+// Prometheus instance reuse a single pooled client instead of constructing a new one (and
+// its own connection) for every trial. It is bounded to avoid unbounded growth in clusters
+// that (mis)configure a distinct, transient address per metric.
+type promAPICache struct {
+	mu      sync.Mutex
+	clients map[string]promv1.API
+	order   []string // least-recently-used first
+	maxSize int
+}
+
+var prometheusClients = &promAPICache{clients: make(map[string]promv1.API), maxSize: 64}
+
+func (c *promAPICache) get(address string) (promv1.API, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if api, ok := c.clients[address]; ok {
+		c.touch(address)
+		return api, nil
+	}
+
+	client, err := prom.NewClient(prom.Config{Address: address, RoundTripper: promTransport})
+	if err != nil {
+		return nil, err
+	}
+
+	api := promv1.NewAPI(client)
+	c.clients[address] = api
+	c.order = append(c.order, address)
+	if len(c.order) > c.maxSize {
+		delete(c.clients, c.order[0])
+		c.order = c.order[1:]
+	}
+
+	return api, nil
+}
+
+func (c *promAPICache) touch(address string) {
+	for i, a := range c.order {
+		if a == address {
+			c.order = append(c.order[:i], c.order[i+1:]...)
+			break
+		}
+	}
+	c.order = append(c.order, address)
+}
+
+// scrapeTargetState is the last observed LastScrape and derived scrape interval for a
+// single Prometheus scrape target, keyed by its ScrapeURL.
+type scrapeTargetState struct {
+	lastScrape time.Time
+	interval   time.Duration
+}
+
+// scrapeTracker derives each target's actual scrape interval from successive observations
+// of its LastScrape, rather than assuming every target shares the same fixed interval.
+type scrapeTracker struct {
+	mu    sync.Mutex
+	state map[string]scrapeTargetState
+}
+
+var targetScrapeIntervals = &scrapeTracker{state: make(map[string]scrapeTargetState)}
+
+// observe records lastScrape for key and returns the target's scrape interval: the time
+// since the previous observed LastScrape, or scrapeInterval if there is no prior
+// observation (or the target hasn't scraped again since).
+func (s *scrapeTracker) observe(key string, lastScrape time.Time) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	interval := scrapeInterval
+	if prev, ok := s.state[key]; ok {
+		if lastScrape.After(prev.lastScrape) {
+			interval = lastScrape.Sub(prev.lastScrape)
+		} else {
+			interval = prev.interval
+		}
+	}
+
+	s.state[key] = scrapeTargetState{lastScrape: lastScrape, interval: interval}
+	return interval
+}
+
 func checkReady(ctx context.Context, api promv1.API, t time.Time) (time.Time, error) {
 	targets, err := api.Targets(ctx)
 	if err != nil {
@@ -114,12 +294,18 @@ func checkReady(ctx context.Context, api promv1.API, t time.Time) (time.Time, er
 			}
 		}
 
+		interval := targetScrapeIntervals.observe(target.ScrapeURL, target.LastScrape)
+
 		// Ensure we have done an additional scrape since completion time
-		if target.LastScrape.Before(t.Add(scrapeInterval)) {
+		if target.LastScrape.Before(t.Add(interval)) {
+			retryAfter := time.Until(target.LastScrape.Add(2 * interval))
+			if retryAfter < 0 {
+				retryAfter = 0
+			}
 			return t, &CaptureError{
 				Message:    "waiting for final scrape",
 				Address:    target.ScrapeURL,
-				RetryAfter: scrapeInterval,
+				RetryAfter: retryAfter,
 			}
 		}
 
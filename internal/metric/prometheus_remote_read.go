@@ -0,0 +1,88 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metric
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	prom "github.com/prometheus/client_golang/api"
+	promv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	optimizev1beta2 "github.com/thestormforge/optimize-controller/v2/api/v1beta2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// bearerTokenRoundTripper injects an Authorization header into every request, used to reach
+// remote storage backends (Thanos, Cortex, Mimir, ...) that sit behind auth unlike a typical
+// in-cluster Prometheus.
+type bearerTokenRoundTripper struct {
+	token string
+	next  http.RoundTripper
+}
+
+func (t *bearerTokenRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.token != "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("Authorization", "Bearer "+t.token)
+	}
+	return t.next.RoundTrip(req)
+}
+
+// capturePrometheusRemoteReadMetric evaluates m.Query against a long-term Prometheus remote storage
+// backend exposed at m.URL, the same way the "prometheus" metric type queries an in-cluster server.
+// Unlike MetricPrometheus, it skips the scrape target readiness check: remote storage backends (e.g.
+// Thanos, Cortex, Mimir) answer PromQL over HTTP but don't expose the scrape target status the
+// readiness check relies on, so callers are expected to account for their own ingestion delay (e.g.
+// querying a completionTime in the past) instead.
+//
+// This queries the same PromQL HTTP API as the "prometheus" metric type rather than the
+// remote_read wire protocol (which requires the prompb protobuf types and snappy compression that
+// aren't vendored here) -- in practice every common long-term storage backend fronts its remote-read
+// data with a PromQL-compatible query endpoint, so this is the integration point users actually need.
+// Credentials, if the backend requires a bearer token, are read from the Secret referenced by
+// m.SecretRef (key "token").
+func capturePrometheusRemoteReadMetric(ctx context.Context, c client.Client, ns string, m *optimizev1beta2.Metric, completionTime time.Time) (float64, float64, error) {
+	token, err := secretValue(ctx, c, ns, m.SecretRef, "token")
+	if err != nil {
+		return 0, 0, err
+	}
+
+	promClient, err := prom.NewClient(prom.Config{
+		Address:      m.URL,
+		RoundTripper: &bearerTokenRoundTripper{token: token, next: promTransport},
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	promAPI := promv1.NewAPI(promClient)
+
+	value, err := queryScalar(ctx, promAPI, m.Query, completionTime)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var valueError float64
+	if m.ErrorQuery != "" {
+		valueError, err = queryScalar(ctx, promAPI, m.ErrorQuery, completionTime)
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+
+	return value, valueError, nil
+}
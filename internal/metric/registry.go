@@ -0,0 +1,108 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metric
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	optimizev1beta2 "github.com/thestormforge/optimize-controller/v2/api/v1beta2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Capturer captures a point-in-time metric value (and its error rate) for a trial. The metric's
+// Query and ErrorQuery have already been rendered as Go templates by the time Capture is called, so
+// implementations only need to translate the rendered query into an equivalent time-window lookup
+// at trial.Status.CompletionTime. The client, if non-nil, can be used to resolve credentials from
+// the Secret referenced by metric.SecretRef.
+type Capturer interface {
+	Capture(ctx context.Context, log logr.Logger, c client.Client, trial *optimizev1beta2.Trial, metric *optimizev1beta2.Metric, target runtime.Object) (value float64, valueError float64, err error)
+}
+
+// CapturerFunc adapts a function to a Capturer, the same way http.HandlerFunc adapts a function to
+// an http.Handler.
+type CapturerFunc func(ctx context.Context, log logr.Logger, c client.Client, trial *optimizev1beta2.Trial, metric *optimizev1beta2.Metric, target runtime.Object) (float64, float64, error)
+
+func (f CapturerFunc) Capture(ctx context.Context, log logr.Logger, c client.Client, trial *optimizev1beta2.Trial, metric *optimizev1beta2.Metric, target runtime.Object) (float64, float64, error) {
+	return f(ctx, log, c, trial, metric, target)
+}
+
+// capturers is the registry of Capturer implementations keyed by metric type. Third-party metric
+// sources can add themselves via RegisterCapturer from an init function.
+var capturers = make(map[optimizev1beta2.MetricType]Capturer)
+
+// RegisterCapturer associates a Capturer with a metric type, overwriting any Capturer previously
+// registered for that type.
+func RegisterCapturer(t optimizev1beta2.MetricType, c Capturer) {
+	capturers[t] = c
+}
+
+func init() {
+	RegisterCapturer(optimizev1beta2.MetricKubernetes, CapturerFunc(
+		func(_ context.Context, _ logr.Logger, _ client.Client, _ *optimizev1beta2.Trial, m *optimizev1beta2.Metric, _ runtime.Object) (float64, float64, error) {
+			return captureBuiltinMetric(m)
+		}))
+	RegisterCapturer(optimizev1beta2.MetricPrometheus, CapturerFunc(
+		func(ctx context.Context, log logr.Logger, _ client.Client, trial *optimizev1beta2.Trial, m *optimizev1beta2.Metric, _ runtime.Object) (float64, float64, error) {
+			return capturePrometheusMetric(ctx, log, m, trial.Status.CompletionTime.Time)
+		}))
+	RegisterCapturer(optimizev1beta2.MetricJSONPath, CapturerFunc(
+		func(_ context.Context, _ logr.Logger, _ client.Client, _ *optimizev1beta2.Trial, m *optimizev1beta2.Metric, _ runtime.Object) (float64, float64, error) {
+			return captureJSONPathMetric(m)
+		}))
+	RegisterCapturer(optimizev1beta2.MetricDatadog, CapturerFunc(
+		func(ctx context.Context, _ logr.Logger, c client.Client, trial *optimizev1beta2.Trial, m *optimizev1beta2.Metric, _ runtime.Object) (float64, float64, error) {
+			return captureDatadogMetric(ctx, c, trial.Namespace, m, trial.Status.StartTime.Time, trial.Status.CompletionTime.Time)
+		}))
+	RegisterCapturer(optimizev1beta2.MetricNewRelic, CapturerFunc(
+		func(_ context.Context, _ logr.Logger, _ client.Client, trial *optimizev1beta2.Trial, m *optimizev1beta2.Metric, _ runtime.Object) (float64, float64, error) {
+			return captureNewRelicMetric(m, trial.Status.StartTime.Time, trial.Status.CompletionTime.Time)
+		}))
+	RegisterCapturer(optimizev1beta2.MetricCloudWatch, CapturerFunc(
+		func(ctx context.Context, _ logr.Logger, c client.Client, trial *optimizev1beta2.Trial, m *optimizev1beta2.Metric, _ runtime.Object) (float64, float64, error) {
+			return captureCloudWatchMetric(ctx, c, trial.Namespace, m, trial.Status.StartTime.Time, trial.Status.CompletionTime.Time)
+		}))
+	RegisterCapturer(optimizev1beta2.MetricStatsD, CapturerFunc(
+		func(ctx context.Context, _ logr.Logger, c client.Client, trial *optimizev1beta2.Trial, m *optimizev1beta2.Metric, _ runtime.Object) (float64, float64, error) {
+			return captureStatsDMetric(ctx, c, trial.Namespace, m)
+		}))
+	RegisterCapturer(optimizev1beta2.MetricPrometheusAlert, CapturerFunc(
+		func(ctx context.Context, _ logr.Logger, _ client.Client, _ *optimizev1beta2.Trial, m *optimizev1beta2.Metric, _ runtime.Object) (float64, float64, error) {
+			return captureAlertMetric(ctx, m)
+		}))
+	RegisterCapturer(optimizev1beta2.MetricPrometheusRemoteRead, CapturerFunc(
+		func(ctx context.Context, _ logr.Logger, c client.Client, trial *optimizev1beta2.Trial, m *optimizev1beta2.Metric, _ runtime.Object) (float64, float64, error) {
+			return capturePrometheusRemoteReadMetric(ctx, c, trial.Namespace, m, trial.Status.CompletionTime.Time)
+		}))
+}
+
+// secretValue resolves key from the Secret referenced by ref in namespace ns. It returns an empty
+// string (not an error) when ref is nil or c is nil, so callers can fall back to an environment
+// variable the same way the pre-SecretRef backends (Datadog, New Relic) already do.
+func secretValue(ctx context.Context, c client.Client, ns string, ref *corev1.LocalObjectReference, key string) (string, error) {
+	if ref == nil || c == nil {
+		return "", nil
+	}
+
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: ns, Name: ref.Name}, secret); err != nil {
+		return "", err
+	}
+	return string(secret.Data[key]), nil
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metric
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+
+	optimizev1beta2 "github.com/thestormforge/optimize-controller/v2/api/v1beta2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// captureStatsDMetric looks up m.Query (a StatsD metric name) from a statsd-exporter style HTTP
+// endpoint at m.URL. StatsD itself is a write-only UDP protocol with no way to query a historical
+// value back out, so this assumes a read-capable aggregator (e.g. a Prometheus statsd_exporter, or
+// anything exposing the same "/metrics"-as-JSON shape) is sitting in front of it; m.URL should point
+// at that aggregator, not at the StatsD ingest port. Credentials, if the aggregator requires a
+// bearer token, are read from the Secret referenced by m.SecretRef (key "token").
+func captureStatsDMetric(ctx context.Context, c client.Client, ns string, m *optimizev1beta2.Metric) (float64, float64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, m.URL, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	token, err := secretValue(ctx, c, ns, m.SecretRef, "token")
+	if err != nil {
+		return 0, 0, err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, 0, fmt.Errorf("unexpected status fetching %s: %s", m.URL, resp.Status)
+	}
+
+	var gauges map[string]float64
+	if err := json.NewDecoder(resp.Body).Decode(&gauges); err != nil {
+		return 0, 0, fmt.Errorf("could not parse statsd aggregator response: %w", err)
+	}
+
+	value, ok := gauges[m.Query]
+	if !ok {
+		return 0, 0, fmt.Errorf("no such metric: %s", m.Query)
+	}
+	return value, math.NaN(), nil
+}
@@ -0,0 +1,100 @@
+/*
+ Copyright 2021 GramLabs, Inc.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// fetchTimeout bounds how long a single discovery document fetch is allowed to take.
+const fetchTimeout = 5 * time.Second
+
+// Metadata is the subset of the RFC 8414 OAuth 2.0 Authorization Server Metadata document that the
+// client has corresponding configuration fields for.
+type Metadata struct {
+	Issuer                      string `json:"issuer"`
+	AuthorizationEndpoint       string `json:"authorization_endpoint,omitempty"`
+	TokenEndpoint               string `json:"token_endpoint,omitempty"`
+	RevocationEndpoint          string `json:"revocation_endpoint,omitempty"`
+	RegistrationEndpoint        string `json:"registration_endpoint,omitempty"`
+	DeviceAuthorizationEndpoint string `json:"device_authorization_endpoint,omitempty"`
+	JSONWebKeySetURI            string `json:"jwks_uri,omitempty"`
+}
+
+var (
+	cacheMu sync.Mutex
+	cache   = make(map[string]*Metadata)
+)
+
+// Fetch retrieves and parses the authorization server metadata document for issuer, trying the
+// "oauth-authorization-server" well-known name first (RFC 8414) and falling back to
+// "openid-configuration" for servers that only publish OpenID Connect discovery. The result is
+// cached in memory for the lifetime of the process, keyed by issuer, so repeated calls (e.g. once
+// per CLI invocation that touches the same server) do not each incur a network round trip.
+func Fetch(ctx context.Context, issuer string) (*Metadata, error) {
+	cacheMu.Lock()
+	md, ok := cache[issuer]
+	cacheMu.Unlock()
+	if ok {
+		return md, nil
+	}
+
+	md, err := fetchDocument(ctx, WellKnownURI(issuer, "oauth-authorization-server"))
+	if err != nil {
+		md, err = fetchDocument(ctx, WellKnownURI(issuer, "openid-configuration"))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	cacheMu.Lock()
+	cache[issuer] = md
+	cacheMu.Unlock()
+	return md, nil
+}
+
+func fetchDocument(ctx context.Context, u string) (*Metadata, error) {
+	ctx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("discovery: unexpected status fetching %s: %s", u, resp.Status)
+	}
+
+	md := &Metadata{}
+	if err := json.NewDecoder(resp.Body).Decode(md); err != nil {
+		return nil, fmt.Errorf("discovery: could not parse %s: %w", u, err)
+	}
+	return md, nil
+}
@@ -0,0 +1,260 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package patch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	optimizev1beta2 "github.com/thestormforge/optimize-controller/v2/api/v1beta2"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// manifestWorkGVK is the OCM type a ManifestWorkApplier wraps patches in; it is handled as an
+// unstructured object (like every other patch target in this package) since this controller does not
+// otherwise depend on OCM's generated clients.
+var manifestWorkGVK = schema.GroupVersionKind{Group: "work.open-cluster-management.io", Version: "v1", Kind: "ManifestWork"}
+
+// placementDecisionGVK is the OCM type used to resolve a Placement/PlacementRule's selected clusters.
+var placementDecisionGVK = schema.GroupVersionKind{Group: "cluster.open-cluster-management.io", Version: "v1beta1", Kind: "PlacementDecision"}
+
+// placementLabel is the label OCM places on every PlacementDecision it generates for a Placement or
+// PlacementRule, naming the placement it decided for.
+const placementLabel = "cluster.open-cluster-management.io/placement"
+
+// PatchApplier applies a rendered patch operation to its target, hiding whether the target lives on the
+// cluster the controller itself is running on, or is delivered to one or more OCM managed clusters
+// selected by PatchOperation.PlacementRef.
+type PatchApplier interface {
+	// Apply submits p's patch, creating or updating whatever is necessary for it to take effect
+	Apply(ctx context.Context, t *optimizev1beta2.Trial, p *optimizev1beta2.PatchOperation) error
+	// Ready reports whether p's patch has taken effect everywhere it was delivered
+	Ready(ctx context.Context, t *optimizev1beta2.Trial, p *optimizev1beta2.PatchOperation) (bool, error)
+}
+
+// ApplierFor selects the PatchApplier implementation appropriate for p
+func ApplierFor(c client.Client, p *optimizev1beta2.PatchOperation) PatchApplier {
+	if p.PlacementRef != nil {
+		return &ManifestWorkApplier{Client: c}
+	}
+	return &LocalClientApplier{Client: c}
+}
+
+// LocalClientApplier applies a patch operation directly against the cluster the controller is running
+// on; this is the long-standing (and still default) behavior of the patch reconciler.
+type LocalClientApplier struct {
+	Client client.Client
+}
+
+// Apply patches TargetRef directly.
+// RBAC: We assume that we have "patch" permission from a customer defined role so we do not limit what types we can patch
+func (a *LocalClientApplier) Apply(ctx context.Context, t *optimizev1beta2.Trial, p *optimizev1beta2.PatchOperation) error {
+	u := &unstructured.Unstructured{}
+	u.SetName(p.TargetRef.Name)
+	u.SetNamespace(p.TargetRef.Namespace)
+	u.SetGroupVersionKind(p.TargetRef.GroupVersionKind())
+	return a.Client.Patch(ctx, u, client.RawPatch(p.PatchType, p.Data))
+}
+
+// Ready always reports true: a local patch takes effect atomically as part of Apply, there is nothing
+// further to wait for here (readiness of the patched workload itself is handled separately by the ready
+// reconciler's readiness checks).
+func (a *LocalClientApplier) Ready(ctx context.Context, t *optimizev1beta2.Trial, p *optimizev1beta2.PatchOperation) (bool, error) {
+	return true, nil
+}
+
+// ManifestWorkApplier delivers a patch operation to one or more managed clusters by wrapping it in a
+// ManifestWork in each cluster's namespace on the hub, as selected by p.PlacementRef's PlacementDecision.
+// This is what allows a single experiment to tune a workload that is deployed across multiple managed
+// clusters, which LocalClientApplier cannot do since the controller's own client only ever talks to the
+// cluster it is running in.
+//
+// Only strategic merge and JSON merge patch types are supported: a ManifestWork manifest is a (possibly
+// partial) declarative object applied with server-side apply, which a JSON patch's imperative operations
+// cannot be translated into.
+type ManifestWorkApplier struct {
+	Client client.Client
+}
+
+func (a *ManifestWorkApplier) Apply(ctx context.Context, t *optimizev1beta2.Trial, p *optimizev1beta2.PatchOperation) error {
+	manifest, err := manifestFor(p)
+	if err != nil {
+		return err
+	}
+
+	clusters, err := a.placedClusters(ctx, t, p)
+	if err != nil {
+		return err
+	}
+
+	for _, cluster := range clusters {
+		mw := newManifestWork(cluster, manifestWorkName(t, p), manifest, p)
+
+		existing := &unstructured.Unstructured{}
+		existing.SetGroupVersionKind(manifestWorkGVK)
+		key := client.ObjectKey{Namespace: cluster, Name: mw.GetName()}
+		switch err := a.Client.Get(ctx, key, existing); {
+		case apierrors.IsNotFound(err):
+			if err := a.Client.Create(ctx, mw); err != nil {
+				return err
+			}
+		case err != nil:
+			return err
+		default:
+			mw.SetResourceVersion(existing.GetResourceVersion())
+			if err := a.Client.Update(ctx, mw); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Ready reports whether every ManifestWork created by Apply has an "Applied" status condition, the same
+// signal OCM's AppliedManifestWork mirrors back from the spoke cluster to the hub.
+func (a *ManifestWorkApplier) Ready(ctx context.Context, t *optimizev1beta2.Trial, p *optimizev1beta2.PatchOperation) (bool, error) {
+	clusters, err := a.placedClusters(ctx, t, p)
+	if err != nil {
+		return false, err
+	}
+	if len(clusters) == 0 {
+		return false, nil
+	}
+
+	for _, cluster := range clusters {
+		mw := &unstructured.Unstructured{}
+		mw.SetGroupVersionKind(manifestWorkGVK)
+		key := client.ObjectKey{Namespace: cluster, Name: manifestWorkName(t, p)}
+		if err := a.Client.Get(ctx, key, mw); err != nil {
+			return false, err
+		}
+		if !manifestWorkApplied(mw) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// placedClusters resolves p.PlacementRef to the set of managed clusters OCM decided on, by reading the
+// PlacementDecision(s) it generated for that placement.
+func (a *ManifestWorkApplier) placedClusters(ctx context.Context, t *optimizev1beta2.Trial, p *optimizev1beta2.PatchOperation) ([]string, error) {
+	decisions := &unstructured.UnstructuredList{}
+	decisions.SetGroupVersionKind(placementDecisionGVK)
+	if err := a.Client.List(ctx, decisions, client.InNamespace(t.Namespace), client.MatchingLabels{placementLabel: p.PlacementRef.Name}); err != nil {
+		return nil, err
+	}
+
+	var clusters []string
+	for i := range decisions.Items {
+		values, found, err := unstructured.NestedSlice(decisions.Items[i].Object, "status", "decisions")
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			continue
+		}
+		for _, v := range values {
+			decision, ok := v.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if clusterName, ok := decision["clusterName"].(string); ok && clusterName != "" {
+				clusters = append(clusters, clusterName)
+			}
+		}
+	}
+	return clusters, nil
+}
+
+// manifestFor renders p's patch data into the full (or partial) manifest a ManifestWork applies with
+// server-side apply, adding the identifying fields (apiVersion/kind/name/namespace) that a strategic
+// merge or JSON merge patch does not itself carry.
+func manifestFor(p *optimizev1beta2.PatchOperation) (map[string]interface{}, error) {
+	if p.PatchType == types.JSONPatchType {
+		return nil, fmt.Errorf("JSON patch type is not supported by ManifestWorkApplier, use strategic merge or JSON merge instead")
+	}
+
+	manifest := make(map[string]interface{})
+	if err := json.Unmarshal(p.Data, &manifest); err != nil {
+		return nil, err
+	}
+
+	manifest["apiVersion"] = p.TargetRef.APIVersion
+	manifest["kind"] = p.TargetRef.Kind
+	metadata, _ := manifest["metadata"].(map[string]interface{})
+	if metadata == nil {
+		metadata = make(map[string]interface{})
+	}
+	metadata["name"] = p.TargetRef.Name
+	metadata["namespace"] = p.TargetRef.Namespace
+	manifest["metadata"] = metadata
+
+	return manifest, nil
+}
+
+// newManifestWork builds the ManifestWork that delivers manifest to cluster using server-side apply.
+func newManifestWork(cluster, name string, manifest map[string]interface{}, p *optimizev1beta2.PatchOperation) *unstructured.Unstructured {
+	mw := &unstructured.Unstructured{}
+	mw.SetGroupVersionKind(manifestWorkGVK)
+	mw.SetNamespace(cluster)
+	mw.SetName(name)
+
+	_ = unstructured.SetNestedSlice(mw.Object, []interface{}{manifest}, "spec", "workload", "manifests")
+	_ = unstructured.SetNestedSlice(mw.Object, []interface{}{
+		map[string]interface{}{
+			"resourceIdentifier": map[string]interface{}{
+				"group":     p.TargetRef.GroupVersionKind().Group,
+				"resource":  strings.ToLower(p.TargetRef.Kind) + "s",
+				"namespace": p.TargetRef.Namespace,
+				"name":      p.TargetRef.Name,
+			},
+			"updateStrategy": map[string]interface{}{"type": "ServerSideApply"},
+		},
+	}, "spec", "manifestConfigs")
+
+	return mw
+}
+
+// manifestWorkApplied reports whether mw's status carries an "Applied" condition in the "True" state.
+func manifestWorkApplied(mw *unstructured.Unstructured) bool {
+	conditions, found, err := unstructured.NestedSlice(mw.Object, "status", "conditions")
+	if err != nil || !found {
+		return false
+	}
+	for _, v := range conditions {
+		condition, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if condition["type"] == "Applied" && condition["status"] == "True" {
+			return true
+		}
+	}
+	return false
+}
+
+// manifestWorkName deterministically names the ManifestWork delivering p's patch, so Apply and Ready
+// agree on what to look up without needing to persist anything new on the trial.
+func manifestWorkName(t *optimizev1beta2.Trial, p *optimizev1beta2.PatchOperation) string {
+	return fmt.Sprintf("%s-%s-%s", t.Name, strings.ToLower(p.TargetRef.Kind), p.TargetRef.Name)
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package patch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	optimizev1beta2 "github.com/thestormforge/optimize-controller/v2/api/v1beta2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestManifestFor(t *testing.T) {
+	p := &optimizev1beta2.PatchOperation{
+		TargetRef: corev1.ObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "default", Name: "deploy"},
+		PatchType: types.StrategicMergePatchType,
+		Data:      []byte(`{"spec":{"replicas":3}}`),
+	}
+
+	manifest, err := manifestFor(p)
+	assert.NoError(t, err)
+	assert.Equal(t, "apps/v1", manifest["apiVersion"])
+	assert.Equal(t, "Deployment", manifest["kind"])
+	assert.Equal(t, "deploy", manifest["metadata"].(map[string]interface{})["name"])
+	assert.Equal(t, "default", manifest["metadata"].(map[string]interface{})["namespace"])
+}
+
+func TestManifestFor_JSONPatchUnsupported(t *testing.T) {
+	p := &optimizev1beta2.PatchOperation{
+		TargetRef: corev1.ObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "default", Name: "deploy"},
+		PatchType: types.JSONPatchType,
+		Data:      []byte(`[{"op":"replace","path":"/spec/replicas","value":3}]`),
+	}
+
+	_, err := manifestFor(p)
+	assert.Error(t, err)
+}
+
+func TestManifestWorkApplied(t *testing.T) {
+	cases := []struct {
+		desc       string
+		conditions []interface{}
+		applied    bool
+	}{
+		{
+			desc: "applied",
+			conditions: []interface{}{
+				map[string]interface{}{"type": "Applied", "status": "True"},
+			},
+			applied: true,
+		},
+		{
+			desc: "not-applied",
+			conditions: []interface{}{
+				map[string]interface{}{"type": "Applied", "status": "False"},
+			},
+			applied: false,
+		},
+		{
+			desc:       "no-conditions",
+			conditions: nil,
+			applied:    false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			mw := &unstructured.Unstructured{Object: map[string]interface{}{}}
+			if c.conditions != nil {
+				_ = unstructured.SetNestedSlice(mw.Object, c.conditions, "status", "conditions")
+			}
+			assert.Equal(t, c.applied, manifestWorkApplied(mw))
+		})
+	}
+}
+
+func TestManifestWorkName(t *testing.T) {
+	trial := &optimizev1beta2.Trial{}
+	trial.Name = "trial-1"
+	p := &optimizev1beta2.PatchOperation{
+		TargetRef: corev1.ObjectReference{Kind: "Deployment", Name: "deploy"},
+	}
+	assert.Equal(t, "trial-1-deployment-deploy", manifestWorkName(trial, p))
+}
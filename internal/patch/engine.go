@@ -0,0 +1,151 @@
+/*
+Copyright 2020 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package patch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	"github.com/google/go-jsonnet"
+	optimizev1beta2 "github.com/thestormforge/optimize-controller/v2/api/v1beta2"
+)
+
+// Interpreter renders a patch template's body into a JSON patch document using a
+// template engine other than the default Go template evaluation handled by
+// template.Engine.RenderPatch.
+type Interpreter interface {
+	// Render evaluates the patch template for the supplied trial and returns the
+	// resulting JSON patch document.
+	Render(trial *optimizev1beta2.Trial, p *optimizev1beta2.PatchTemplate) ([]byte, error)
+}
+
+// interpreters is the registry of non-default template engines, keyed by the
+// `engine` field on the patch template.
+var interpreters = map[optimizev1beta2.TemplateEngine]Interpreter{
+	optimizev1beta2.EngineJsonnet: jsonnetInterpreter{},
+	optimizev1beta2.EngineHelm:    helmInterpreter{},
+	optimizev1beta2.EngineCue:     cueInterpreter{},
+}
+
+// InterpreterFor returns the interpreter registered for the patch template's engine, or
+// false if the engine is the default (Go template) and should go through the normal
+// template.Engine rendering path.
+func InterpreterFor(engine optimizev1beta2.TemplateEngine) (Interpreter, bool) {
+	if engine == "" || engine == optimizev1beta2.EngineGoTemplate {
+		return nil, false
+	}
+	i, ok := interpreters[engine]
+	return i, ok
+}
+
+// trialExtVar builds the object passed to Jsonnet as `std.extVar("trial")` and used to
+// seed Helm `--set-json` values: the trial's assignments keyed by parameter name plus
+// basic experiment/trial metadata.
+func trialExtVar(trial *optimizev1beta2.Trial) ([]byte, error) {
+	values := make(map[string]int64, len(trial.Spec.Assignments))
+	for _, a := range trial.Spec.Assignments {
+		values[a.Name] = a.Value
+	}
+
+	return json.Marshal(map[string]interface{}{
+		"name":        trial.Name,
+		"namespace":   trial.Namespace,
+		"assignments": values,
+	})
+}
+
+// jsonnetInterpreter evaluates the patch body as a Jsonnet program, exposing the trial
+// assignments and metadata via `std.extVar("trial")`.
+type jsonnetInterpreter struct{}
+
+func (jsonnetInterpreter) Render(trial *optimizev1beta2.Trial, p *optimizev1beta2.PatchTemplate) ([]byte, error) {
+	extVar, err := trialExtVar(trial)
+	if err != nil {
+		return nil, err
+	}
+
+	vm := jsonnet.MakeVM()
+	vm.ExtVar("trial", string(extVar))
+
+	out, err := vm.EvaluateAnonymousSnippet(p.TargetRef.String()+".jsonnet", p.Patch)
+	if err != nil {
+		return nil, fmt.Errorf("unable to evaluate jsonnet patch: %w", err)
+	}
+
+	return []byte(out), nil
+}
+
+// helmInterpreter renders the patch body as a Helm values template against the chart
+// referenced by the patch template's Chart field, selecting a single rendered manifest
+// to use as the patch.
+type helmInterpreter struct{}
+
+func (helmInterpreter) Render(trial *optimizev1beta2.Trial, p *optimizev1beta2.PatchTemplate) ([]byte, error) {
+	if p.Chart == "" {
+		return nil, fmt.Errorf("helm engine requires a chart reference")
+	}
+
+	extVar, err := trialExtVar(trial)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command("helm", "template", "trial", p.Chart,
+		"--values", "-",
+		"--set-json", "trial="+string(extVar),
+		"--show-only", "templates/patch.yaml",
+	)
+	cmd.Stdin = bytes.NewBufferString(p.Patch)
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("unable to render helm chart %q: %w", p.Chart, err)
+	}
+
+	return out, nil
+}
+
+// cueInterpreter evaluates the patch body as a CUE file, binding each parameter assignment
+// as a top-level field before exporting the result to JSON.
+type cueInterpreter struct{}
+
+func (cueInterpreter) Render(trial *optimizev1beta2.Trial, p *optimizev1beta2.PatchTemplate) ([]byte, error) {
+	ctx := cuecontext.New()
+
+	v := ctx.CompileString(p.Patch, cue.Filename(p.TargetRef.String()+".cue"))
+	if err := v.Err(); err != nil {
+		return nil, fmt.Errorf("unable to evaluate cue patch: %w", err)
+	}
+
+	for _, a := range trial.Spec.Assignments {
+		v = v.FillPath(cue.ParsePath(a.Name), a.Value)
+		if err := v.Err(); err != nil {
+			return nil, fmt.Errorf("unable to bind parameter %q to cue patch: %w", a.Name, err)
+		}
+	}
+
+	out, err := v.MarshalJSON()
+	if err != nil {
+		return nil, fmt.Errorf("unable to marshal cue patch: %w", err)
+	}
+
+	return out, nil
+}
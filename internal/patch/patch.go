@@ -33,8 +33,15 @@ const defaultAttemptsRemaining = 3
 
 // RenderTemplate determines the patch target and renders the patch template
 func RenderTemplate(te *template.Engine, t *optimizev1beta2.Trial, p *optimizev1beta2.PatchTemplate) (*corev1.ObjectReference, []byte, error) {
-	// Render the actual patch data
-	data, err := te.RenderPatch(p, t)
+	// Render the actual patch data, dispatching to an alternate template engine
+	// (e.g. Jsonnet, Helm) when one is configured on the patch template
+	var data []byte
+	var err error
+	if interp, ok := InterpreterFor(p.Engine); ok {
+		data, err = interp.Render(t, p)
+	} else {
+		data, err = te.RenderPatch(p, t)
+	}
 	if err != nil {
 		return nil, nil, err
 	}
@@ -97,12 +104,11 @@ func CreatePatchOperation(t *optimizev1beta2.Trial, p *optimizev1beta2.PatchTemp
 		return nil, fmt.Errorf("unknown patch type: %s", p.Type)
 	}
 
-	// If the patch is for the trial job itself, it cannot be applied (since the job won't exist until well after patches are applied)
+	// If the patch is for the trial job itself, it cannot be applied through the API (since the job won't
+	// exist until well after patches are applied); it is instead applied directly against the generated
+	// job template, strategic merge, JSON merge, and JSON patch are all supported there
 	if trial.IsTrialJobReference(t, &po.TargetRef) {
 		po.AttemptsRemaining = 0
-		if po.PatchType != types.StrategicMergePatchType {
-			return nil, fmt.Errorf("trial job patch must be a strategic merge patch")
-		}
 	}
 
 	return po, nil
@@ -142,7 +142,7 @@ metadata:
 			trial: trial,
 			patchTemplate: &optimizev1beta2.PatchTemplate{
 				Type:  optimizev1beta2.PatchJSON,
-				Patch: patchSpec,
+				Patch: jsonPatch,
 				TargetRef: &corev1.ObjectReference{
 					Kind:       "Job",
 					APIVersion: "batch/v1",
@@ -150,7 +150,7 @@ metadata:
 					Namespace:  trial.Namespace,
 				},
 			},
-			expectedPOError: true,
+			attemptsRemaining: 0,
 		},
 		{
 			desc:  "patchTrial - strategic merge",
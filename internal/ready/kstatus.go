@@ -0,0 +1,162 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ready
+
+import (
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+// kstatus computes a kstatus-style readiness verdict for obj, dispatching on its kind and falling back to a
+// generic `status.conditions[Ready]=True` probe for kinds it does not specifically recognize. Unlike the
+// other special conditions, a single "kstatus" check adapts itself to the target's kind instead of requiring
+// a specific check (e.g. "job-complete" or "pvc-bound") to be selected up front.
+func (r *ReadinessChecker) kstatus(obj *unstructured.Unstructured) (string, corev1.ConditionStatus, error) {
+	// A resource that is being deleted is never "ready"; report it distinctly from "not ready yet" so callers
+	// can tell the two states apart if they care to
+	if !obj.GetDeletionTimestamp().IsZero() {
+		return "terminating", corev1.ConditionFalse, nil
+	}
+
+	switch obj.GetObjectKind().GroupVersionKind().GroupKind() {
+
+	case appsv1.SchemeGroupVersion.WithKind("Deployment").GroupKind(),
+		appsv1.SchemeGroupVersion.WithKind("StatefulSet").GroupKind(),
+		appsv1.SchemeGroupVersion.WithKind("DaemonSet").GroupKind():
+		return r.kstatusRollout(obj)
+
+	case corev1.SchemeGroupVersion.WithKind("Pod").GroupKind():
+		return r.kstatusPod(obj)
+
+	case batchv1.SchemeGroupVersion.WithKind("Job").GroupKind():
+		return r.jobComplete(obj)
+
+	case corev1.SchemeGroupVersion.WithKind("PersistentVolumeClaim").GroupKind():
+		return r.pvcBound(obj)
+
+	case corev1.SchemeGroupVersion.WithKind("Service").GroupKind():
+		return r.serviceReady(obj)
+
+	default:
+		return r.unstructuredConditionStatus(obj, "Ready")
+	}
+}
+
+// kstatusRollout checks a Deployment/StatefulSet/DaemonSet the way kstatus does: the controller must have
+// observed the latest generation and rolled every replica to the new, ready revision; for a Deployment a
+// stalled rollout (Progressing=False, Reason=ProgressDeadlineExceeded) is treated as a hard failure rather
+// than "not ready yet", since it will never resolve on its own.
+func (r *ReadinessChecker) kstatusRollout(obj *unstructured.Unstructured) (string, corev1.ConditionStatus, error) {
+	content := obj.UnstructuredContent()
+
+	observedGeneration, _, err := unstructured.NestedInt64(content, "status", "observedGeneration")
+	if err != nil {
+		return "", corev1.ConditionFalse, err
+	}
+	if observedGeneration < obj.GetGeneration() {
+		return "waiting for the controller to observe the latest generation", corev1.ConditionFalse, nil
+	}
+
+	if obj.GetKind() == "Deployment" {
+		if msg, stalled := progressDeadlineExceeded(obj); stalled {
+			return "", corev1.ConditionFalse, &ReadinessError{error: "rollout exceeded its progress deadline", Reason: "ProgressDeadlineExceeded", Message: msg}
+		}
+	}
+
+	replicas, found, err := unstructured.NestedInt64(content, "spec", "replicas")
+	if err != nil {
+		return "", corev1.ConditionFalse, err
+	}
+	if !found {
+		replicas = 1
+	}
+
+	updatedReplicas, _, err := unstructured.NestedInt64(content, "status", "updatedReplicas")
+	if err != nil {
+		return "", corev1.ConditionFalse, err
+	}
+	if updatedReplicas < replicas {
+		return fmt.Sprintf("%d of %d replicas updated", updatedReplicas, replicas), corev1.ConditionFalse, nil
+	}
+
+	readyReplicas, _, err := unstructured.NestedInt64(content, "status", "readyReplicas")
+	if err != nil {
+		return "", corev1.ConditionFalse, err
+	}
+	if readyReplicas < replicas {
+		return fmt.Sprintf("%d of %d replicas ready", readyReplicas, replicas), corev1.ConditionFalse, nil
+	}
+
+	return "", corev1.ConditionTrue, nil
+}
+
+// kstatusPod checks a Pod's phase and, if it is still running, its "Ready" condition.
+func (r *ReadinessChecker) kstatusPod(obj *unstructured.Unstructured) (string, corev1.ConditionStatus, error) {
+	pod := &corev1.Pod{}
+	if err := scheme.Scheme.Convert(obj, pod, nil); err != nil {
+		return "", corev1.ConditionFalse, fmt.Errorf("failed to convert %T to %T: %v", obj, pod, err)
+	}
+
+	switch pod.Status.Phase {
+	case corev1.PodSucceeded:
+		return "", corev1.ConditionTrue, nil
+	case corev1.PodFailed:
+		return "", corev1.ConditionFalse, &ReadinessError{error: "pod failed", Message: pod.Status.Message}
+	}
+
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodReady {
+			return c.Message, c.Status, nil
+		}
+	}
+
+	return "", corev1.ConditionFalse, nil
+}
+
+// progressDeadlineExceeded reports whether obj has a "Progressing" condition with a status of "False" and a
+// reason of "ProgressDeadlineExceeded", the signal a Deployment controller uses to flag a rollout that has
+// stopped making progress.
+func progressDeadlineExceeded(obj *unstructured.Unstructured) (string, bool) {
+	s, ok := obj.UnstructuredContent()["status"].(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+
+	cl, ok := s["conditions"].([]interface{})
+	if !ok {
+		return "", false
+	}
+
+	for i := range cl {
+		cm, ok := cl[i].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if cm["type"] != "Progressing" || cm["status"] != string(corev1.ConditionFalse) || cm["reason"] != "ProgressDeadlineExceeded" {
+			continue
+		}
+		msg, _ := cm["message"].(string)
+		return msg, true
+	}
+
+	return "", false
+}
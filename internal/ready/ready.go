@@ -19,15 +19,19 @@ package ready
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 
+	optimizev1beta2 "github.com/thestormforge/optimize-controller/v2/api/v1beta2"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/scale/scheme/extensionsv1beta1"
+	"k8s.io/client-go/util/jsonpath"
 	"k8s.io/kubectl/pkg/polymorphichelpers"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -50,6 +54,22 @@ const (
 	// of the target object. The name of the status field and the expected value (indicating a ready state) should
 	// be appended to this constant, e.g. `"stormforge.io/status-phase-running"` to check for a running pod.
 	ConditionTypeStatus = "stormforge.io/status-"
+	// ConditionTypeJobComplete is a special condition type whose status is determined by checking for a "Complete"
+	// condition on a batch Job; a "Failed" condition is treated as a hard failure rather than "not ready yet".
+	ConditionTypeJobComplete = "stormforge.io/job-complete"
+	// ConditionTypePVCBound is a special condition type whose status is determined by checking that a
+	// PersistentVolumeClaim's phase is "Bound".
+	ConditionTypePVCBound = "stormforge.io/pvc-bound"
+	// ConditionTypeServiceReady is a special condition type whose status is determined by checking that a
+	// Service of type LoadBalancer has been assigned an address; Services of any other type are always ready.
+	ConditionTypeServiceReady = "stormforge.io/service-ready"
+	// ConditionTypeCRDEstablished is a special condition type whose status is determined by checking that a
+	// CustomResourceDefinition has both its "Established" and "NamesAccepted" conditions set to "True".
+	ConditionTypeCRDEstablished = "stormforge.io/crd-established"
+	// ConditionTypeKstatus is a special condition type that picks an appropriate readiness computation based
+	// on the kind of the target object, following the same rules as Helm/kstatus; this allows a single check
+	// to be used in place of selecting a kind-specific condition (e.g. "job-complete" or "pvc-bound").
+	ConditionTypeKstatus = "stormforge.io/kstatus"
 )
 
 // ReadinessChecker is used to check the conditions of runtime objects
@@ -96,6 +116,16 @@ func (r *ReadinessChecker) CheckConditions(ctx context.Context, obj *unstructure
 			msg, s, err = r.rolloutStatus(obj)
 		case ConditionTypeAppReady:
 			msg, s, err = r.appReady(ctx, obj)
+		case ConditionTypeJobComplete:
+			msg, s, err = r.jobComplete(obj)
+		case ConditionTypePVCBound:
+			msg, s, err = r.pvcBound(obj)
+		case ConditionTypeServiceReady:
+			msg, s, err = r.serviceReady(obj)
+		case ConditionTypeCRDEstablished:
+			msg, s, err = r.crdEstablished(obj)
+		case ConditionTypeKstatus:
+			msg, s, err = r.kstatus(obj)
 		default:
 			if strings.HasPrefix(c, ConditionTypeStatus) {
 				msg, s, err = r.statusField(obj, c)
@@ -125,6 +155,63 @@ func (r *ReadinessChecker) CheckConditions(ctx context.Context, obj *unstructure
 	return "", true, nil
 }
 
+// CheckExpressions evaluates a list of JSONPath-based readiness expressions against obj, stopping at
+// the first one that does not hold (or errors). A path that does not match anything on obj is treated
+// as "not ready yet" rather than as a hard failure, matching the semantics of CheckConditions.
+func (r *ReadinessChecker) CheckExpressions(obj *unstructured.Unstructured, expressions []optimizev1beta2.ReadinessExpression) (string, bool, error) {
+	for i := range expressions {
+		e := &expressions[i]
+
+		ok, err := evaluateReadinessExpression(obj, e)
+		if err != nil {
+			return "", false, err
+		}
+		if !ok {
+			return fmt.Sprintf("expression '%s %s %s' not satisfied", e.Path, e.Operator, e.Value), false, nil
+		}
+	}
+	return "", true, nil
+}
+
+// evaluateReadinessExpression evaluates a single ReadinessExpression's Path against obj and compares
+// the result to Value using Operator.
+func evaluateReadinessExpression(obj *unstructured.Unstructured, e *optimizev1beta2.ReadinessExpression) (bool, error) {
+	jp := jsonpath.New("readinessExpression").AllowMissingKeys(true)
+	if err := jp.Parse(e.Path); err != nil {
+		return false, fmt.Errorf("invalid readiness expression path '%s': %w", e.Path, err)
+	}
+
+	results, err := jp.FindResults(obj.UnstructuredContent())
+	if err != nil {
+		return false, err
+	}
+	if len(results) != 1 || len(results[0]) != 1 {
+		// Nothing matched yet (e.g. the field hasn't been populated) -- not ready, not a hard failure
+		return false, nil
+	}
+
+	actual := fmt.Sprintf("%v", results[0][0].Interface())
+
+	switch e.Operator {
+	case optimizev1beta2.ReadinessExpressionEqual:
+		return actual == e.Value, nil
+	case optimizev1beta2.ReadinessExpressionNotEqual:
+		return actual != e.Value, nil
+	case optimizev1beta2.ReadinessExpressionLessThan, optimizev1beta2.ReadinessExpressionGreaterThan:
+		af, aerr := strconv.ParseFloat(actual, 64)
+		vf, verr := strconv.ParseFloat(e.Value, 64)
+		if aerr != nil || verr != nil {
+			return false, fmt.Errorf("readiness expression '%s' requires numeric values, got '%s' and '%s'", e.Path, actual, e.Value)
+		}
+		if e.Operator == optimizev1beta2.ReadinessExpressionLessThan {
+			return af < vf, nil
+		}
+		return af > vf, nil
+	default:
+		return false, fmt.Errorf("unknown readiness expression operator '%s'", e.Operator)
+	}
+}
+
 // alwaysTrue does not actually check any status and just returns true
 func (r *ReadinessChecker) alwaysTrue(obj *unstructured.Unstructured) (string, corev1.ConditionStatus, error) {
 	_ = obj.GroupVersionKind() // Just to be consistent with everyone else
@@ -232,6 +319,111 @@ func (r *ReadinessChecker) rolloutStatus(obj *unstructured.Unstructured) (string
 	return msg, corev1.ConditionFalse, err
 }
 
+// jobComplete checks a batch Job for a "Complete" condition, treating a "Failed" condition as a hard
+// failure since a failed Job is never going to become ready on its own.
+func (r *ReadinessChecker) jobComplete(obj *unstructured.Unstructured) (string, corev1.ConditionStatus, error) {
+	job := &batchv1.Job{}
+	if err := scheme.Scheme.Convert(obj, job, nil); err != nil {
+		return "", corev1.ConditionFalse, fmt.Errorf("failed to convert %T to %T: %v", obj, job, err)
+	}
+
+	for _, c := range job.Status.Conditions {
+		switch {
+		case c.Type == batchv1.JobComplete && c.Status == corev1.ConditionTrue:
+			return c.Message, corev1.ConditionTrue, nil
+		case c.Type == batchv1.JobFailed && c.Status == corev1.ConditionTrue:
+			return "", corev1.ConditionFalse, &ReadinessError{error: "job failed", Reason: c.Reason, Message: c.Message}
+		}
+	}
+
+	return "", corev1.ConditionFalse, nil
+}
+
+// pvcBound checks that a PersistentVolumeClaim has reached the "Bound" phase.
+func (r *ReadinessChecker) pvcBound(obj *unstructured.Unstructured) (string, corev1.ConditionStatus, error) {
+	pvc := &corev1.PersistentVolumeClaim{}
+	if err := scheme.Scheme.Convert(obj, pvc, nil); err != nil {
+		return "", corev1.ConditionFalse, fmt.Errorf("failed to convert %T to %T: %v", obj, pvc, err)
+	}
+
+	if pvc.Status.Phase == corev1.ClaimBound {
+		return "", corev1.ConditionTrue, nil
+	}
+
+	return string(pvc.Status.Phase), corev1.ConditionFalse, nil
+}
+
+// serviceReady checks that a LoadBalancer Service has been assigned an address; Services of any other
+// type have nothing to wait on and are always considered ready.
+func (r *ReadinessChecker) serviceReady(obj *unstructured.Unstructured) (string, corev1.ConditionStatus, error) {
+	svc := &corev1.Service{}
+	if err := scheme.Scheme.Convert(obj, svc, nil); err != nil {
+		return "", corev1.ConditionFalse, fmt.Errorf("failed to convert %T to %T: %v", obj, svc, err)
+	}
+
+	if svc.Spec.Type != corev1.ServiceTypeLoadBalancer {
+		return "", corev1.ConditionTrue, nil
+	}
+
+	if len(svc.Status.LoadBalancer.Ingress) > 0 {
+		return "", corev1.ConditionTrue, nil
+	}
+
+	return "waiting for load balancer ingress", corev1.ConditionFalse, nil
+}
+
+// crdEstablished checks that a CustomResourceDefinition has both reached the "Established" condition and
+// had its names accepted; apiextensions types are not part of the client-go scheme used elsewhere in this
+// file, so the conditions are read directly off the unstructured content instead of a converted type.
+func (r *ReadinessChecker) crdEstablished(obj *unstructured.Unstructured) (string, corev1.ConditionStatus, error) {
+	established, establishedMsg := unstructuredCondition(obj, "Established")
+	if established != corev1.ConditionTrue {
+		return establishedMsg, established, nil
+	}
+
+	namesAccepted, namesAcceptedMsg := unstructuredCondition(obj, "NamesAccepted")
+	if namesAccepted != corev1.ConditionTrue {
+		return namesAcceptedMsg, namesAccepted, nil
+	}
+
+	return "", corev1.ConditionTrue, nil
+}
+
+// unstructuredCondition returns the status and message of a single named condition from an unstructured
+// object's `status.conditions` list, treating a missing condition as "Unknown".
+func unstructuredCondition(obj *unstructured.Unstructured, conditionType string) (corev1.ConditionStatus, string) {
+	s, ok := obj.UnstructuredContent()["status"].(map[string]interface{})
+	if !ok {
+		return corev1.ConditionUnknown, ""
+	}
+
+	cl, ok := s["conditions"].([]interface{})
+	if !ok {
+		return corev1.ConditionUnknown, ""
+	}
+
+	for i := range cl {
+		cm, ok := cl[i].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if cm["type"] != conditionType {
+			continue
+		}
+		msg, _ := cm["message"].(string)
+		switch cm["status"] {
+		case string(corev1.ConditionTrue):
+			return corev1.ConditionTrue, msg
+		case string(corev1.ConditionFalse):
+			return corev1.ConditionFalse, msg
+		default:
+			return corev1.ConditionUnknown, msg
+		}
+	}
+
+	return corev1.ConditionUnknown, ""
+}
+
 // podReady attempts to locate the pods associated with the specified object and
 func (r *ReadinessChecker) podReady(ctx context.Context, obj *unstructured.Unstructured) (string, corev1.ConditionStatus, error) {
 	// Get the list of pods for the object
@@ -21,6 +21,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	optimizev1beta2 "github.com/thestormforge/optimize-controller/v2/api/v1beta2"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -231,3 +232,220 @@ func TestReadinessChecker_CheckConditions(t *testing.T) {
 		})
 	}
 }
+
+func TestReadinessChecker_CheckExpressions(t *testing.T) {
+	cases := []struct {
+		desc        string
+		obj         *unstructured.Unstructured
+		expressions []optimizev1beta2.ReadinessExpression
+		ready       bool
+	}{
+		{
+			desc: "equal",
+			obj:  withStatus(map[string]interface{}{"phase": "Running"}),
+			expressions: []optimizev1beta2.ReadinessExpression{
+				{Path: "{.status.phase}", Operator: optimizev1beta2.ReadinessExpressionEqual, Value: "Running"},
+			},
+			ready: true,
+		},
+		{
+			desc: "not-equal-yet",
+			obj:  withStatus(map[string]interface{}{"phase": "Pending"}),
+			expressions: []optimizev1beta2.ReadinessExpression{
+				{Path: "{.status.phase}", Operator: optimizev1beta2.ReadinessExpressionEqual, Value: "Running"},
+			},
+			ready: false,
+		},
+		{
+			desc: "greater-than",
+			obj:  withStatus(map[string]interface{}{"readyReplicas": int64(3)}),
+			expressions: []optimizev1beta2.ReadinessExpression{
+				{Path: "{.status.readyReplicas}", Operator: optimizev1beta2.ReadinessExpressionGreaterThan, Value: "2"},
+			},
+			ready: true,
+		},
+		{
+			desc: "missing-path",
+			obj:  withStatus(map[string]interface{}{}),
+			expressions: []optimizev1beta2.ReadinessExpression{
+				{Path: "{.status.readyReplicas}", Operator: optimizev1beta2.ReadinessExpressionGreaterThan, Value: "2"},
+			},
+			ready: false,
+		},
+	}
+
+	rc := &ReadinessChecker{}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			_, ready, err := rc.CheckExpressions(c.obj, c.expressions)
+			assert.NoError(t, err)
+			assert.Equal(t, c.ready, ready)
+		})
+	}
+}
+
+func TestReadinessChecker_crdEstablished(t *testing.T) {
+	cases := []struct {
+		desc       string
+		conditions []interface{}
+		ready      bool
+	}{
+		{
+			desc:  "established-and-accepted",
+			ready: true,
+			conditions: []interface{}{
+				map[string]interface{}{"type": "Established", "status": "True"},
+				map[string]interface{}{"type": "NamesAccepted", "status": "True"},
+			},
+		},
+		{
+			desc:  "not-established",
+			ready: false,
+			conditions: []interface{}{
+				map[string]interface{}{"type": "NamesAccepted", "status": "True"},
+			},
+		},
+		{
+			desc:  "names-not-accepted",
+			ready: false,
+			conditions: []interface{}{
+				map[string]interface{}{"type": "Established", "status": "True"},
+				map[string]interface{}{"type": "NamesAccepted", "status": "False"},
+			},
+		},
+	}
+
+	rc := &ReadinessChecker{}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			obj := withStatus(map[string]interface{}{"conditions": c.conditions})
+			_, ready, err := rc.CheckConditions(context.TODO(), obj, []string{ConditionTypeCRDEstablished})
+			assert.NoError(t, err)
+			assert.Equal(t, c.ready, ready)
+		})
+	}
+}
+
+func TestReadinessChecker_kstatus(t *testing.T) {
+	cases := []struct {
+		desc  string
+		obj   *unstructured.Unstructured
+		ready bool
+		err   error
+	}{
+		{
+			desc:  "deployment-current",
+			ready: true,
+			obj: withKind("apps/v1", "Deployment", map[string]interface{}{
+				"spec":   map[string]interface{}{"replicas": int64(3)},
+				"status": map[string]interface{}{"observedGeneration": int64(1), "updatedReplicas": int64(3), "readyReplicas": int64(3)},
+			}),
+		},
+		{
+			desc:  "deployment-rolling-out",
+			ready: false,
+			obj: withKind("apps/v1", "Deployment", map[string]interface{}{
+				"spec":   map[string]interface{}{"replicas": int64(3)},
+				"status": map[string]interface{}{"observedGeneration": int64(1), "updatedReplicas": int64(1), "readyReplicas": int64(1)},
+			}),
+		},
+		{
+			desc:  "deployment-stalled",
+			ready: false,
+			err:   &ReadinessError{error: "rollout exceeded its progress deadline", Reason: "ProgressDeadlineExceeded"},
+			obj: withKind("apps/v1", "Deployment", map[string]interface{}{
+				"spec": map[string]interface{}{"replicas": int64(3)},
+				"status": map[string]interface{}{
+					"observedGeneration": int64(1),
+					"conditions": []interface{}{
+						map[string]interface{}{"type": "Progressing", "status": "False", "reason": "ProgressDeadlineExceeded"},
+					},
+				},
+			}),
+		},
+		{
+			desc:  "statefulset-not-observed",
+			ready: false,
+			obj: func() *unstructured.Unstructured {
+				u := withKind("apps/v1", "StatefulSet", map[string]interface{}{
+					"spec":   map[string]interface{}{"replicas": int64(1)},
+					"status": map[string]interface{}{"observedGeneration": int64(0)},
+				})
+				u.SetGeneration(1)
+				return u
+			}(),
+		},
+		{
+			desc:  "job-complete",
+			ready: true,
+			obj: withKind("batch/v1", "Job", map[string]interface{}{
+				"status": map[string]interface{}{
+					"conditions": []interface{}{
+						map[string]interface{}{"type": "Complete", "status": "True"},
+					},
+				},
+			}),
+		},
+		{
+			desc:  "pvc-bound",
+			ready: true,
+			obj: withKind("v1", "PersistentVolumeClaim", map[string]interface{}{
+				"status": map[string]interface{}{"phase": "Bound"},
+			}),
+		},
+		{
+			desc:  "service-loadbalancer-pending",
+			ready: false,
+			obj: withKind("v1", "Service", map[string]interface{}{
+				"spec":   map[string]interface{}{"type": "LoadBalancer"},
+				"status": map[string]interface{}{},
+			}),
+		},
+		{
+			desc:  "generic-custom-resource-ready",
+			ready: true,
+			obj: withKind("example.com/v1", "Widget", map[string]interface{}{
+				"status": map[string]interface{}{
+					"conditions": []interface{}{
+						map[string]interface{}{"type": "Ready", "status": "True"},
+					},
+				},
+			}),
+		},
+		{
+			desc:  "terminating",
+			ready: false,
+			obj: func() *unstructured.Unstructured {
+				u := withKind("v1", "PersistentVolumeClaim", map[string]interface{}{
+					"status": map[string]interface{}{"phase": "Bound"},
+				})
+				now := metav1.Now()
+				u.SetDeletionTimestamp(&now)
+				return u
+			}(),
+		},
+	}
+
+	rc := &ReadinessChecker{}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			_, ready, err := rc.CheckConditions(context.TODO(), c.obj, []string{ConditionTypeKstatus})
+			assert.Equal(t, c.ready, ready)
+			if c.err != nil {
+				assert.EqualError(t, err, c.err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func withKind(apiVersion, kind string, obj map[string]interface{}) *unstructured.Unstructured {
+	obj["apiVersion"] = apiVersion
+	obj["kind"] = kind
+	return &unstructured.Unstructured{Object: obj}
+}
+
+func withStatus(status map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{"status": status}}
+}
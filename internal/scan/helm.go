@@ -0,0 +1,32 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scan
+
+import (
+	"github.com/thestormforge/konjure/pkg/filters"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+)
+
+// ExcludeHelmHooks is a kio.Filter that drops objects annotated as Helm lifecycle hooks (e.g. the
+// pre-install/post-install Jobs a chart uses to run migrations or smoke tests), so they aren't
+// mistaken for long-running workloads during application parameter discovery. A Helm chart added
+// as an application resource is already expanded into plain resources upstream (Konjure shells out
+// to `helm template`), this just keeps hook-only objects out of the resource map those resources
+// are scanned from.
+var ExcludeHelmHooks kio.Filter = &filters.ResourceMetaFilter{
+	AnnotationSelector: "!helm.sh/hook",
+}
@@ -31,9 +31,12 @@ import (
 )
 
 // APIApplicationToClusterApplication converts an API (optimize-go) representation
-// of an Application into an in cluster ( api/apps/v1alpha1 ) representation.
-func APIApplicationToClusterApplication(app applications.Application, scenario applications.Scenario) (*optimizeappsv1alpha1.Application, error) {
-	if err := validateAPIApplication(app, scenario); err != nil {
+// of an Application, together with all of its scenarios, into an in cluster
+// ( api/apps/v1alpha1 ) representation. Configuration and objectives are merged
+// across scenarios (without duplication) into the top-level Application, while each
+// scenario's load generator (StormForger, Locust, or Custom) is preserved individually.
+func APIApplicationToClusterApplication(app applications.Application, scenarios []applications.Scenario) (*optimizeappsv1alpha1.Application, error) {
+	if err := validateAPIApplication(app, scenarios); err != nil {
 		return nil, err
 	}
 
@@ -51,30 +54,73 @@ func APIApplicationToClusterApplication(app applications.Application, scenario a
 
 	baseApp.Resources = resources
 
-	params, err := apiParameters(scenario)
+	params, err := apiParameters(scenarios)
 	if err != nil {
 		return nil, err
 	}
 
-	baseApp.Configuration = params
+	baseApp.Parameters = params
 
-	objectives, err := apiObjectives(scenario)
+	objectives, err := apiObjectives(scenarios)
 	if err != nil {
 		return nil, err
 	}
 
 	baseApp.Objectives = objectives
 
-	scenarios, err := apiScenarios(scenario)
+	clusterScenarios, err := apiScenarios(scenarios)
 	if err != nil {
 		return nil, err
 	}
 
-	baseApp.Scenarios = scenarios
+	baseApp.Scenarios = clusterScenarios
 
 	return baseApp, nil
 }
 
+// ClusterApplicationToAPIApplication converts an in cluster ( api/apps/v1alpha1 )
+// representation of an Application into its API (optimize-go) representation, along with
+// one API scenario per cluster scenario. It is the inverse of
+// APIApplicationToClusterApplication: since the cluster representation only keeps a single,
+// already-merged Configuration and Objectives list, those are attached to every returned
+// scenario, which is exactly what APIApplicationToClusterApplication expects to merge
+// (and deduplicate) back together.
+func ClusterApplicationToAPIApplication(app *optimizeappsv1alpha1.Application) (applications.Application, []applications.Scenario, error) {
+	if app == nil {
+		return applications.Application{}, nil, fmt.Errorf("invalid application, nil")
+	}
+
+	// The API type tags the application name "name" at the top level, not nested
+	// under "metadata" the way ObjectMeta does, so it is carried across separately.
+	nameData, err := json.Marshal(struct {
+		Name string `json:"name"`
+	}{Name: app.Name})
+	if err != nil {
+		return applications.Application{}, nil, err
+	}
+
+	apiApp := applications.Application{}
+	if err := json.Unmarshal(nameData, &apiApp); err != nil {
+		return applications.Application{}, nil, err
+	}
+
+	resourceData, err := json.Marshal(app.Resources)
+	if err != nil {
+		return applications.Application{}, nil, err
+	}
+
+	if err := json.Unmarshal(resourceData, &apiApp.Resources); err != nil {
+		return applications.Application{}, nil, err
+	}
+
+	scenarios, err := clusterScenarios(app.Scenarios, app.Parameters, app.Objectives)
+	if err != nil {
+		return applications.Application{}, nil, err
+	}
+
+	return apiApp, scenarios, nil
+}
+
 // ClusterExperimentToAPITemplate converts an Application into an in cluster
 // ( api/apps/v1alpha1 ) representation into an API (optimize-go) representation.
 func ClusterExperimentToAPITemplate(exp *optimizev1beta2.Experiment) (*applications.Template, error) {
@@ -228,7 +274,7 @@ func combineParamAndBaseline(params []experimentsv1alpha1.Parameter, baselines [
 	return combined, nil
 }
 
-func validateAPIApplication(app applications.Application, scenario applications.Scenario) error {
+func validateAPIApplication(app applications.Application, scenarios []applications.Scenario) error {
 	if app.Name == "" {
 		return fmt.Errorf("invalid application, missing name")
 	}
@@ -237,11 +283,21 @@ func validateAPIApplication(app applications.Application, scenario applications.
 		return fmt.Errorf("invalid application, no resources specified")
 	}
 
-	if len(scenario.Objective) == 0 {
+	if len(scenarios) == 0 {
+		return fmt.Errorf("invalid application, no scenarios specified")
+	}
+
+	var objectives, configuration int
+	for _, scenario := range scenarios {
+		objectives += len(scenario.Objective)
+		configuration += len(scenario.Configuration)
+	}
+
+	if objectives == 0 {
 		return fmt.Errorf("invalid scenario, no objectives specified")
 	}
 
-	if len(scenario.Configuration) == 0 {
+	if configuration == 0 {
 		return fmt.Errorf("invalid scenario, no configuration specified")
 	}
 
@@ -263,48 +319,196 @@ func apiResources(app applications.Application) (konjure.Resources, error) {
 	return resources, nil
 }
 
-func apiParameters(scenario applications.Scenario) ([]optimizeappsv1alpha1.Parameter, error) {
-	// Parameters
-	rawParams, err := json.Marshal(scenario.Configuration)
-	if err != nil {
-		return nil, err
-	}
-
+// apiParameters merges the configuration of every scenario into a single, deduplicated
+// list suitable for the top-level Application.Parameters.
+func apiParameters(scenarios []applications.Scenario) ([]optimizeappsv1alpha1.Parameter, error) {
 	params := []optimizeappsv1alpha1.Parameter{}
-	if err := json.Unmarshal(rawParams, &params); err != nil {
-		return nil, err
+	seen := make(map[string]bool)
+
+	for _, scenario := range scenarios {
+		rawParams, err := json.Marshal(scenario.Configuration)
+		if err != nil {
+			return nil, err
+		}
+
+		var scenarioParams []optimizeappsv1alpha1.Parameter
+		if err := json.Unmarshal(rawParams, &scenarioParams); err != nil {
+			return nil, err
+		}
+
+		for _, p := range scenarioParams {
+			key, err := json.Marshal(p)
+			if err != nil {
+				return nil, err
+			}
+			if seen[string(key)] {
+				continue
+			}
+			seen[string(key)] = true
+			params = append(params, p)
+		}
 	}
 
 	return params, nil
 }
 
-func apiObjectives(scenario applications.Scenario) ([]optimizeappsv1alpha1.Objective, error) {
-	rawObjectives, err := json.Marshal(scenario.Objective)
-	if err != nil {
-		return nil, err
-	}
-
+// apiObjectives merges the goals of every scenario's objective into a single,
+// deduplicated Objective suitable for the top-level Application.Objectives.
+func apiObjectives(scenarios []applications.Scenario) ([]optimizeappsv1alpha1.Objective, error) {
 	goals := []optimizeappsv1alpha1.Goal{}
-	if err := json.Unmarshal(rawObjectives, &goals); err != nil {
-		return nil, err
+	seen := make(map[string]bool)
+
+	for _, scenario := range scenarios {
+		rawObjectives, err := json.Marshal(scenario.Objective)
+		if err != nil {
+			return nil, err
+		}
+
+		var scenarioGoals []optimizeappsv1alpha1.Goal
+		if err := json.Unmarshal(rawObjectives, &scenarioGoals); err != nil {
+			return nil, err
+		}
+
+		for _, g := range scenarioGoals {
+			key, err := json.Marshal(g)
+			if err != nil {
+				return nil, err
+			}
+			if seen[string(key)] {
+				continue
+			}
+			seen[string(key)] = true
+			goals = append(goals, g)
+		}
 	}
 
-	objectives := []optimizeappsv1alpha1.Objective{{Goals: goals}}
+	return []optimizeappsv1alpha1.Objective{{Goals: goals}}, nil
+}
+
+// scenarioLoadGeneratorKeys are the JSON keys, in priority order, a scenario's load generator
+// may be found under on the API side. "stormforgePerf" is current; "stormforger" is accepted
+// for backwards compatibility with older API payloads.
+var scenarioLoadGeneratorKeys = []string{"stormforgePerf", "stormforger"}
+
+// apiScenarios converts each API scenario into its cluster representation, preserving the
+// load generator discriminator (StormForger, Locust, or Custom). The API and cluster types
+// don't share a JSON tag for the StormForger case (the API uses "stormforgePerf" where the
+// cluster type uses "stormforger"), so a single marshal/unmarshal pass would silently drop
+// it; the raw fields are located by name instead.
+func apiScenarios(scenarios []applications.Scenario) ([]optimizeappsv1alpha1.Scenario, error) {
+	result := make([]optimizeappsv1alpha1.Scenario, 0, len(scenarios))
+
+	for _, scenario := range scenarios {
+		data, err := json.Marshal(scenario)
+		if err != nil {
+			return nil, err
+		}
+
+		var raw map[string]json.RawMessage
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return nil, err
+		}
+
+		appScenario := optimizeappsv1alpha1.Scenario{Name: scenario.Name}
+
+		for _, key := range scenarioLoadGeneratorKeys {
+			msg, ok := raw[key]
+			if !ok {
+				continue
+			}
+			appScenario.StormForger = &optimizeappsv1alpha1.StormForgerScenario{}
+			if err := json.Unmarshal(msg, appScenario.StormForger); err != nil {
+				return nil, err
+			}
+			break
+		}
+
+		if msg, ok := raw["locust"]; ok {
+			appScenario.Locust = &optimizeappsv1alpha1.LocustScenario{}
+			if err := json.Unmarshal(msg, appScenario.Locust); err != nil {
+				return nil, err
+			}
+		}
+
+		if msg, ok := raw["custom"]; ok {
+			appScenario.Custom = &optimizeappsv1alpha1.CustomScenario{}
+			if err := json.Unmarshal(msg, appScenario.Custom); err != nil {
+				return nil, err
+			}
+		}
 
-	return objectives, nil
+		result = append(result, appScenario)
+	}
+
+	return result, nil
 }
 
-func apiScenarios(scenario applications.Scenario) ([]optimizeappsv1alpha1.Scenario, error) {
-	data, err := json.Marshal(scenario)
+// clusterScenarios is the inverse of apiScenarios: it converts each cluster scenario into
+// its API representation, mapping the load generator discriminator back onto the
+// "stormforgePerf" JSON key, and attaching the (already merged) configuration and
+// objectives so that re-converting with apiScenarios/apiParameters/apiObjectives
+// reproduces them exactly.
+func clusterScenarios(scenarios []optimizeappsv1alpha1.Scenario, configuration []optimizeappsv1alpha1.Parameter, objectives []optimizeappsv1alpha1.Objective) ([]applications.Scenario, error) {
+	configData, err := json.Marshal(configuration)
 	if err != nil {
 		return nil, err
 	}
 
-	appScenario := optimizeappsv1alpha1.Scenario{}
-
-	if err = json.Unmarshal(data, &appScenario); err != nil {
+	var goals []optimizeappsv1alpha1.Goal
+	for _, o := range objectives {
+		goals = append(goals, o.Goals...)
+	}
+	goalData, err := json.Marshal(goals)
+	if err != nil {
 		return nil, err
 	}
 
-	return []optimizeappsv1alpha1.Scenario{appScenario}, nil
+	result := make([]applications.Scenario, 0, len(scenarios))
+	for _, scenario := range scenarios {
+		nameData, err := json.Marshal(scenario.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		raw := map[string]json.RawMessage{
+			"name":          nameData,
+			"configuration": configData,
+			"objective":     goalData,
+		}
+
+		switch {
+		case scenario.StormForger != nil:
+			data, err := json.Marshal(scenario.StormForger)
+			if err != nil {
+				return nil, err
+			}
+			raw["stormforgePerf"] = data
+		case scenario.Locust != nil:
+			data, err := json.Marshal(scenario.Locust)
+			if err != nil {
+				return nil, err
+			}
+			raw["locust"] = data
+		case scenario.Custom != nil:
+			data, err := json.Marshal(scenario.Custom)
+			if err != nil {
+				return nil, err
+			}
+			raw["custom"] = data
+		}
+
+		data, err := json.Marshal(raw)
+		if err != nil {
+			return nil, err
+		}
+
+		apiScenario := applications.Scenario{}
+		if err := json.Unmarshal(data, &apiScenario); err != nil {
+			return nil, err
+		}
+
+		result = append(result, apiScenario)
+	}
+
+	return result, nil
 }
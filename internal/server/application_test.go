@@ -19,14 +19,18 @@ package server
 import (
 	"encoding/json"
 	"fmt"
+	"math/rand"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/thestormforge/konjure/pkg/konjure"
+	optimizeappsv1alpha1 "github.com/thestormforge/optimize-controller/v2/api/apps/v1alpha1"
 	optimizev1beta2 "github.com/thestormforge/optimize-controller/v2/api/v1beta2"
 	"github.com/thestormforge/optimize-go/pkg/api"
 	applications "github.com/thestormforge/optimize-go/pkg/api/applications/v2"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
@@ -290,7 +294,7 @@ func TestAPIParameters(t *testing.T) {
 			err := json.Unmarshal(scenarioData, &sd)
 			assert.NoError(t, err)
 
-			res, err := apiParameters(sd)
+			res, err := apiParameters([]applications.Scenario{sd})
 			assert.NoError(t, err)
 			assert.Equal(t, 2, len(res))
 			assert.NotNil(t, res[0].ContainerResources)
@@ -314,7 +318,7 @@ func TestAPIObjectives(t *testing.T) {
 			err := json.Unmarshal(scenarioData, &sd)
 			assert.NoError(t, err)
 
-			res, err := apiObjectives(sd)
+			res, err := apiObjectives([]applications.Scenario{sd})
 			assert.NoError(t, err)
 			assert.Equal(t, 1, len(res))
 			assert.Equal(t, 2, len(res[0].Goals))
@@ -337,10 +341,10 @@ func TestAPIScenarios(t *testing.T) {
 			err := json.Unmarshal(scenarioData, &sd)
 			assert.NoError(t, err)
 
-			res, err := apiScenarios(sd)
+			res, err := apiScenarios([]applications.Scenario{sd})
 			assert.NoError(t, err)
 			require.Equal(t, 1, len(res))
-			assert.NotNil(t, res[0].StormForge)
+			assert.NotNil(t, res[0].StormForger)
 		})
 	}
 }
@@ -364,7 +368,7 @@ func TestAPIApplicationToClusterApplication(t *testing.T) {
 			err = json.Unmarshal(scenarioData, &sd)
 			assert.NoError(t, err)
 
-			res, err := APIApplicationToClusterApplication(ad, sd)
+			res, err := APIApplicationToClusterApplication(ad, []applications.Scenario{sd})
 			assert.NoError(t, err)
 			assert.NotNil(t, res.Name)
 			// Uncomment when we figure out what to do
@@ -441,3 +445,80 @@ var appData = []byte(`
   ],
   "scenarioCount": 1
 }`)
+
+// TestClusterApplicationToAPIApplicationRoundTrip fuzzes a handful of cluster
+// Applications (varying the number and kind of scenarios) through
+// ClusterApplicationToAPIApplication and back through
+// APIApplicationToClusterApplication, asserting the result is structurally identical
+// to the original. This exercises the scenario-type discriminator mapping and the
+// parameter/objective merge-without-duplication logic in both directions at once.
+func TestClusterApplicationToAPIApplicationRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+
+	var resources konjure.Resources
+	require.NoError(t, json.Unmarshal([]byte(`[{"kubernetes":{"namespace":"engineering","selector":"app.kubernetes.io/name=app-1"}}]`), &resources))
+
+	for i := 0; i < 20; i++ {
+		t.Run(fmt.Sprintf("iteration %d", i), func(t *testing.T) {
+			original := &optimizeappsv1alpha1.Application{
+				ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("app-%d", i)},
+				Resources:  resources,
+				Parameters: randParameters(rnd),
+				Objectives: []optimizeappsv1alpha1.Objective{{Goals: randGoals(rnd)}},
+				Scenarios:  randScenarios(rnd),
+			}
+
+			apiApp, apiScenarioList, err := ClusterApplicationToAPIApplication(original)
+			require.NoError(t, err)
+
+			roundTripped, err := APIApplicationToClusterApplication(apiApp, apiScenarioList)
+			require.NoError(t, err)
+
+			assert.Equal(t, original, roundTripped)
+		})
+	}
+}
+
+func randParameters(rnd *rand.Rand) []optimizeappsv1alpha1.Parameter {
+	n := 1 + rnd.Intn(3)
+	params := make([]optimizeappsv1alpha1.Parameter, 0, n)
+	for i := 0; i < n; i++ {
+		if rnd.Intn(2) == 0 {
+			params = append(params, optimizeappsv1alpha1.Parameter{
+				ContainerResources: &optimizeappsv1alpha1.ContainerResources{Selector: fmt.Sprintf("selector-%d", i)},
+			})
+		} else {
+			params = append(params, optimizeappsv1alpha1.Parameter{
+				Replicas: &optimizeappsv1alpha1.Replicas{Selector: fmt.Sprintf("selector-%d", i)},
+			})
+		}
+	}
+	return params
+}
+
+func randGoals(rnd *rand.Rand) []optimizeappsv1alpha1.Goal {
+	n := 1 + rnd.Intn(3)
+	goals := make([]optimizeappsv1alpha1.Goal, 0, n)
+	for i := 0; i < n; i++ {
+		goals = append(goals, optimizeappsv1alpha1.Goal{Name: fmt.Sprintf("goal-%d", i)})
+	}
+	return goals
+}
+
+func randScenarios(rnd *rand.Rand) []optimizeappsv1alpha1.Scenario {
+	n := 1 + rnd.Intn(3)
+	scenarios := make([]optimizeappsv1alpha1.Scenario, 0, n)
+	for i := 0; i < n; i++ {
+		scenario := optimizeappsv1alpha1.Scenario{Name: fmt.Sprintf("scenario-%d", i)}
+		switch rnd.Intn(3) {
+		case 0:
+			scenario.StormForger = &optimizeappsv1alpha1.StormForgerScenario{TestCase: fmt.Sprintf("org/case-%d", i)}
+		case 1:
+			scenario.Locust = &optimizeappsv1alpha1.LocustScenario{Locustfile: fmt.Sprintf("locustfile-%d.py", i)}
+		case 2:
+			scenario.Custom = &optimizeappsv1alpha1.CustomScenario{Image: fmt.Sprintf("image-%d", i)}
+		}
+		scenarios = append(scenarios, scenario)
+	}
+	return scenarios
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package bundle computes install order and effective values for a SetupBundle's releases. It
+// intentionally stops at planning: it does not install releases itself, parallelize independent
+// releases, or implement missingFileHandler-style tolerance for absent values sources, all of which
+// are left for follow-up work once a single sequential pass has proven out the approach.
+package bundle
+
+import (
+	"fmt"
+
+	optimizev1beta2 "github.com/thestormforge/optimize-controller/v2/api/v1beta2"
+)
+
+// Plan topologically sorts a SetupBundle's releases according to their Needs, returning the order
+// releases should be installed in; uninstallation should use the reverse of this order. An error is
+// returned if a release names an unknown dependency or the dependencies form a cycle.
+func Plan(b *optimizev1beta2.SetupBundle) ([]optimizev1beta2.BundleRelease, error) {
+	if b == nil {
+		return nil, nil
+	}
+
+	byName := make(map[string]optimizev1beta2.BundleRelease, len(b.Releases))
+	for _, rel := range b.Releases {
+		byName[rel.Name] = rel
+	}
+
+	var (
+		ordered  []optimizev1beta2.BundleRelease
+		visited  = make(map[string]bool, len(b.Releases))
+		visiting = make(map[string]bool, len(b.Releases))
+	)
+
+	var visit func(name string) error
+	visit = func(name string) error {
+		if visited[name] {
+			return nil
+		}
+		if visiting[name] {
+			return fmt.Errorf("setup bundle has a dependency cycle involving release %q", name)
+		}
+
+		visiting[name] = true
+		for _, need := range byName[name].Needs {
+			if _, ok := byName[need]; !ok {
+				return fmt.Errorf("setup bundle release %q needs unknown release %q", name, need)
+			}
+			if err := visit(need); err != nil {
+				return err
+			}
+		}
+		visiting[name] = false
+
+		visited[name] = true
+		ordered = append(ordered, byName[name])
+		return nil
+	}
+
+	for _, rel := range b.Releases {
+		if err := visit(rel.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	return ordered, nil
+}
+
+// Values returns the effective Helm values for a release: its own Values followed by the currently
+// selected environment's overrides (if any), so environment entries take precedence when merged.
+func Values(b *optimizev1beta2.SetupBundle, rel *optimizev1beta2.BundleRelease) []optimizev1beta2.HelmValue {
+	values := append([]optimizev1beta2.HelmValue{}, rel.Values...)
+	if b.Environment == "" {
+		return values
+	}
+	return append(values, b.Environments[b.Environment]...)
+}
+
+// Namespace returns the namespace a release should be installed into: its own Namespace if set,
+// otherwise the trial's namespace.
+func Namespace(rel *optimizev1beta2.BundleRelease, trialNamespace string) string {
+	if rel.Namespace != "" {
+		return rel.Namespace
+	}
+	return trialNamespace
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bundle
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	optimizev1beta2 "github.com/thestormforge/optimize-controller/v2/api/v1beta2"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestPlan(t *testing.T) {
+	cases := []struct {
+		desc    string
+		bundle  *optimizev1beta2.SetupBundle
+		order   []string
+		wantErr bool
+	}{
+		{
+			desc:   "nil bundle",
+			bundle: nil,
+			order:  nil,
+		},
+		{
+			desc: "already ordered",
+			bundle: &optimizev1beta2.SetupBundle{
+				Releases: []optimizev1beta2.BundleRelease{
+					{Name: "db"},
+					{Name: "app", Needs: []string{"db"}},
+				},
+			},
+			order: []string{"db", "app"},
+		},
+		{
+			desc: "needs reorders",
+			bundle: &optimizev1beta2.SetupBundle{
+				Releases: []optimizev1beta2.BundleRelease{
+					{Name: "app", Needs: []string{"db", "cache"}},
+					{Name: "db"},
+					{Name: "cache"},
+				},
+			},
+			order: []string{"db", "cache", "app"},
+		},
+		{
+			desc: "unknown dependency",
+			bundle: &optimizev1beta2.SetupBundle{
+				Releases: []optimizev1beta2.BundleRelease{
+					{Name: "app", Needs: []string{"missing"}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			desc: "cycle",
+			bundle: &optimizev1beta2.SetupBundle{
+				Releases: []optimizev1beta2.BundleRelease{
+					{Name: "a", Needs: []string{"b"}},
+					{Name: "b", Needs: []string{"a"}},
+				},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			ordered, err := Plan(c.bundle)
+			if c.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+
+			var names []string
+			for _, rel := range ordered {
+				names = append(names, rel.Name)
+			}
+			assert.Equal(t, c.order, names)
+		})
+	}
+}
+
+func TestValues(t *testing.T) {
+	b := &optimizev1beta2.SetupBundle{
+		Environments: map[string][]optimizev1beta2.HelmValue{
+			"prod": {{Name: "replicas", Value: intstr.FromInt(3)}},
+		},
+		Environment: "prod",
+	}
+	rel := &optimizev1beta2.BundleRelease{
+		Values: []optimizev1beta2.HelmValue{{Name: "image.tag", Value: intstr.FromString("v1")}},
+	}
+
+	values := Values(b, rel)
+	assert.Len(t, values, 2)
+	assert.Equal(t, "image.tag", values[0].Name)
+	assert.Equal(t, "replicas", values[1].Name)
+}
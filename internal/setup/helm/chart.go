@@ -0,0 +1,113 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	optimizev1beta2 "github.com/thestormforge/optimize-controller/v2/api/v1beta2"
+	corev1 "k8s.io/api/core/v1"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/cli"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ErrKeylessVerificationUnsupported is returned when a SetupTask requests keyless (sigstore/cosign)
+// chart verification; the Helm SDK only knows how to verify against a local keyring, so keyless
+// verification needs a separate integration and is not implemented yet.
+var ErrKeylessVerificationUnsupported = errors.New("keyless chart verification is not supported yet")
+
+// LoadChart locates, verifies (if configured), and loads the chart referenced by a SetupTask. Charts
+// are downloaded from the task's Helm repository (classic chart repo, or an "oci://" registry)
+// unless a HelmChartRef pins them to an exact OCI digest, in which case that digest is what gets
+// pulled regardless of HelmChartVersion.
+func LoadChart(ctx context.Context, r client.Reader, t *optimizev1beta2.Trial, task *optimizev1beta2.SetupTask, settings *cli.EnvSettings) (*chart.Chart, error) {
+	copts := action.ChartPathOptions{
+		RepoURL: task.HelmRepository,
+		Version: task.HelmChartVersion,
+	}
+	name := task.HelmChart
+
+	if task.HelmChartRef != nil {
+		name = fmt.Sprintf("oci://%s/%s", task.HelmChartRef.Registry, task.HelmChartRef.Repository)
+		copts.Version = task.HelmChartVersion
+		if task.HelmChartRef.Digest != "" {
+			name = fmt.Sprintf("%s@%s", name, task.HelmChartRef.Digest)
+			copts.Version = ""
+		}
+	}
+
+	if task.HelmChartVerification != nil {
+		if task.HelmChartVerification.Keyless {
+			return nil, ErrKeylessVerificationUnsupported
+		}
+
+		keyring, cleanup, err := writeKeyring(ctx, r, t.Namespace, task.HelmChartVerification.PublicKeyRef)
+		if err != nil {
+			return nil, err
+		}
+		defer cleanup()
+
+		copts.Verify = true
+		copts.Keyring = keyring
+	}
+
+	path, err := copts.LocateChart(name, settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate chart '%s': %w", name, err)
+	}
+
+	return loader.Load(path)
+}
+
+// writeKeyring copies the keyring referenced by a SecretKeySelector to a temporary file, since the
+// Helm SDK only accepts a keyring as a path on disk.
+func writeKeyring(ctx context.Context, r client.Reader, namespace string, ref *corev1.SecretKeySelector) (path string, cleanup func(), err error) {
+	if ref == nil {
+		return "", nil, fmt.Errorf("helmChartVerification.publicKeyRef is required unless keyless verification is used")
+	}
+
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: namespace, Name: ref.Name}, secret); err != nil {
+		return "", nil, fmt.Errorf("failed to fetch keyring secret '%s': %w", ref.Name, err)
+	}
+
+	keyring, ok := secret.Data[ref.Key]
+	if !ok {
+		return "", nil, fmt.Errorf("secret '%s' has no key '%s'", ref.Name, ref.Key)
+	}
+
+	f, err := ioutil.TempFile("", "helm-keyring-*.gpg")
+	if err != nil {
+		return "", nil, err
+	}
+	defer f.Close()
+
+	if _, err := f.Write(keyring); err != nil {
+		os.Remove(f.Name())
+		return "", nil, err
+	}
+
+	return f.Name(), func() { os.Remove(f.Name()) }, nil
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helm
+
+import (
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"helm.sh/helm/v3/pkg/action"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// restClientGetter adapts an already constructed *rest.Config to the genericclioptions.RESTClientGetter
+// interface the Helm action API expects, the same way the operator-sdk helm-operator adapts the
+// manager's in-cluster config instead of reading kubeconfig files from disk.
+type restClientGetter struct {
+	cfg       *rest.Config
+	namespace string
+}
+
+func (g *restClientGetter) ToRESTConfig() (*rest.Config, error) {
+	return g.cfg, nil
+}
+
+func (g *restClientGetter) ToDiscoveryClient() (discovery.CachedDiscoveryInterface, error) {
+	dc, err := discovery.NewDiscoveryClientForConfig(g.cfg)
+	if err != nil {
+		return nil, err
+	}
+	return memory.NewMemCacheClient(dc), nil
+}
+
+func (g *restClientGetter) ToRESTMapper() (meta.RESTMapper, error) {
+	dc, err := g.ToDiscoveryClient()
+	if err != nil {
+		return nil, err
+	}
+	return restmapper.NewDeferredDiscoveryRESTMapper(dc), nil
+}
+
+func (g *restClientGetter) ToRawKubeConfigLoader() clientcmd.ClientConfig {
+	overrides := &clientcmd.ConfigOverrides{Context: clientcmd.APIConfig{}.CurrentContext}
+	overrides.Context.Namespace = g.namespace
+	return clientcmd.NewDefaultClientConfig(clientcmd.APIConfig{}, overrides)
+}
+
+var _ genericclioptions.RESTClientGetter = &restClientGetter{}
+
+// NewActionConfiguration builds a Helm action.Configuration for namespace using the manager's own
+// REST config, so in-process releases run with the controller's own permissions instead of requiring
+// a separate setupServiceAccountName with broad setup RBAC.
+func NewActionConfiguration(cfg *rest.Config, namespace string, log logr.Logger) (*action.Configuration, error) {
+	actionConfig := new(action.Configuration)
+	getter := &restClientGetter{cfg: cfg, namespace: namespace}
+
+	debugLog := func(format string, v ...interface{}) {
+		log.V(1).Info(fmt.Sprintf(format, v...))
+	}
+
+	if err := actionConfig.Init(getter, namespace, "secrets", debugLog); err != nil {
+		return nil, err
+	}
+
+	return actionConfig, nil
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package helm wraps the Helm v3 action API so a SetupTask with HelmChart set can be reconciled
+// in-process, without shelling out to a setup image. It is modeled on the release manager from the
+// operator-sdk helm-operator: a release's lifecycle (install, upgrade, uninstall) is driven entirely
+// by comparing the chart/values a caller wants against whatever is currently deployed.
+package helm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	optimizev1beta2 "github.com/thestormforge/optimize-controller/v2/api/v1beta2"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+	driver "helm.sh/helm/v3/pkg/storage/driver"
+)
+
+// ReleaseManager installs, upgrades, and uninstalls a single Helm release on behalf of a trial's
+// setup task. Implementations must be safe to retry: a caller that sees a failure (for example
+// because the process was interrupted mid-apply) is expected to call Sync or ReconcileRelease again
+// on the next attempt rather than treat the release as unrecoverable.
+type ReleaseManager interface {
+	// Sync reconciles the release for a single create or delete of a setup task: installing (or
+	// upgrading) the release for "create", and uninstalling it for "delete". It is the primary entry
+	// point used by the trial controller.
+	Sync(ctx context.Context, mode string) (*release.Release, error)
+	// InstallRelease installs the chart, upgrading in place if a release by the same name already
+	// exists (for example because a previous attempt installed it but failed to report success).
+	InstallRelease(ctx context.Context) (*release.Release, error)
+	// UninstallRelease removes the release, tolerating the case where it was never installed.
+	UninstallRelease(ctx context.Context) (*release.UninstallReleaseResponse, error)
+	// ReconcileRelease re-applies the currently deployed release's manifest, repairing any resources
+	// that were manually changed or deleted without going through Helm.
+	ReconcileRelease(ctx context.Context) (*release.Release, error)
+}
+
+// manager is the default ReleaseManager implementation.
+type manager struct {
+	cfg         *action.Configuration
+	releaseName string
+	namespace   string
+	chart       *chart.Chart
+	values      map[string]interface{}
+}
+
+// NewReleaseManager returns a ReleaseManager for the named release. The supplied action.Configuration
+// should already be initialized (via Init) against namespace.
+func NewReleaseManager(cfg *action.Configuration, namespace, releaseName string, chrt *chart.Chart, values map[string]interface{}) ReleaseManager {
+	return &manager{cfg: cfg, namespace: namespace, releaseName: releaseName, chart: chrt, values: values}
+}
+
+// ReleaseName derives the release name for a trial's setup task, matching the naming the setup job
+// used when it asked the Konjure HelmGenerator to perform the same install.
+func ReleaseName(t *optimizev1beta2.Trial, task *optimizev1beta2.SetupTask) string {
+	return t.Name + "-" + task.Name
+}
+
+func (m *manager) Sync(ctx context.Context, mode string) (*release.Release, error) {
+	switch mode {
+	case "create":
+		return m.InstallRelease(ctx)
+	case "delete":
+		_, err := m.UninstallRelease(ctx)
+		return nil, err
+	default:
+		return nil, fmt.Errorf("unknown setup task mode: %s", mode)
+	}
+}
+
+func (m *manager) InstallRelease(ctx context.Context) (*release.Release, error) {
+	_, err := m.cfg.Releases.Deployed(m.releaseName)
+	switch {
+	case errors.Is(err, driver.ErrReleaseNotFound):
+		install := action.NewInstall(m.cfg)
+		install.ReleaseName = m.releaseName
+		install.Namespace = m.namespace
+		return install.RunWithContext(ctx, m.chart, m.values)
+	case err != nil:
+		return nil, fmt.Errorf("failed to look up release %s: %w", m.releaseName, err)
+	default:
+		upgrade := action.NewUpgrade(m.cfg)
+		return upgrade.RunWithContext(ctx, m.releaseName, m.chart, m.values)
+	}
+}
+
+func (m *manager) UninstallRelease(ctx context.Context) (*release.UninstallReleaseResponse, error) {
+	_, err := m.cfg.Releases.Deployed(m.releaseName)
+	if errors.Is(err, driver.ErrReleaseNotFound) {
+		return &release.UninstallReleaseResponse{}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to look up release %s: %w", m.releaseName, err)
+	}
+
+	uninstall := action.NewUninstall(m.cfg)
+	return uninstall.Run(m.releaseName)
+}
+
+func (m *manager) ReconcileRelease(ctx context.Context) (*release.Release, error) {
+	rel, err := m.cfg.Releases.Deployed(m.releaseName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up release %s: %w", m.releaseName, err)
+	}
+
+	resources, err := m.cfg.KubeClient.Build(strings.NewReader(rel.Manifest), false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse manifest for release %s: %w", m.releaseName, err)
+	}
+
+	// Re-apply the last deployed manifest against itself so anything that drifted (was deleted or
+	// edited outside of Helm) is put back the way the release expects it to be.
+	if _, err := m.cfg.KubeClient.Update(resources, resources, false); err != nil {
+		return nil, fmt.Errorf("failed to reconcile release %s: %w", m.releaseName, err)
+	}
+
+	return rel, nil
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	optimizev1beta2 "github.com/thestormforge/optimize-controller/v2/api/v1beta2"
+	"github.com/thestormforge/optimize-controller/v2/internal/template"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// Values evaluates a SetupTask's HelmValues and HelmValuesFrom against the trial, producing the map
+// that would previously have been serialized into the HELM_CONFIG environment variable for the
+// Konjure HelmGenerator to consume.
+func Values(ctx context.Context, r client.Reader, t *optimizev1beta2.Trial, task *optimizev1beta2.SetupTask) (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+	te := template.New()
+
+	for i := range task.HelmValues {
+		hv := &task.HelmValues[i]
+
+		var v interface{}
+		if hv.ValueFrom != nil {
+			switch {
+			case hv.ValueFrom.ParameterRef != nil:
+				a, ok := t.GetAssignment(hv.ValueFrom.ParameterRef.Name)
+				if !ok {
+					return nil, fmt.Errorf("invalid parameter reference '%s' for Helm value '%s'", hv.ValueFrom.ParameterRef.Name, hv.Name)
+				}
+				if a.Type == intstr.String {
+					v = a.StrVal
+				} else {
+					v = a.IntVal
+				}
+			case hv.ValueFrom.ConfigMapKeyRef != nil:
+				ref := hv.ValueFrom.ConfigMapKeyRef
+				cm := &corev1.ConfigMap{}
+				if err := r.Get(ctx, client.ObjectKey{Namespace: t.Namespace, Name: ref.Name}, cm); err != nil {
+					return nil, fmt.Errorf("failed to fetch config map '%s' for Helm value '%s': %w", ref.Name, hv.Name, err)
+				}
+				s, ok := cm.Data[ref.Key]
+				if !ok {
+					return nil, fmt.Errorf("config map '%s' has no key '%s' for Helm value '%s'", ref.Name, ref.Key, hv.Name)
+				}
+				v = s
+			case hv.ValueFrom.SecretKeyRef != nil:
+				ref := hv.ValueFrom.SecretKeyRef
+				secret := &corev1.Secret{}
+				if err := r.Get(ctx, client.ObjectKey{Namespace: t.Namespace, Name: ref.Name}, secret); err != nil {
+					return nil, fmt.Errorf("failed to fetch secret '%s' for Helm value '%s': %w", ref.Name, hv.Name, err)
+				}
+				b, ok := secret.Data[ref.Key]
+				if !ok {
+					return nil, fmt.Errorf("secret '%s' has no key '%s' for Helm value '%s'", ref.Name, ref.Key, hv.Name)
+				}
+				v = string(b)
+			default:
+				return nil, fmt.Errorf("unknown source for Helm value '%s'", hv.Name)
+			}
+		} else {
+			rendered, err := te.RenderHelmValue(hv, t)
+			if err != nil {
+				return nil, err
+			}
+			v = rendered
+		}
+
+		if hv.ForceString {
+			v = fmt.Sprintf("%v", v)
+		}
+
+		setValue(values, hv.Name, v)
+	}
+
+	for _, hvf := range task.HelmValuesFrom {
+		switch {
+		case hvf.ConfigMap != nil:
+			cm := &corev1.ConfigMap{}
+			if err := r.Get(ctx, client.ObjectKey{Namespace: t.Namespace, Name: hvf.ConfigMap.Name}, cm); err != nil {
+				return nil, err
+			}
+
+			for k, v := range cm.Data {
+				if !strings.HasSuffix(k, "values.yaml") {
+					continue
+				}
+
+				var fileValues map[string]interface{}
+				if err := yaml.Unmarshal([]byte(v), &fileValues); err != nil {
+					return nil, fmt.Errorf("failed to parse '%s' from config map '%s': %w", k, hvf.ConfigMap.Name, err)
+				}
+
+				mergeValues(values, fileValues)
+			}
+
+		case hvf.Secret != nil:
+			secret := &corev1.Secret{}
+			if err := r.Get(ctx, client.ObjectKey{Namespace: t.Namespace, Name: hvf.Secret.Name}, secret); err != nil {
+				return nil, err
+			}
+
+			for k, v := range secret.Data {
+				if !strings.HasSuffix(k, "values.yaml") {
+					continue
+				}
+
+				var fileValues map[string]interface{}
+				if err := yaml.Unmarshal(v, &fileValues); err != nil {
+					return nil, fmt.Errorf("failed to parse '%s' from secret '%s': %w", k, hvf.Secret.Name, err)
+				}
+
+				mergeValues(values, fileValues)
+			}
+		}
+	}
+
+	return values, nil
+}
+
+// setValue assigns v at the dotted path name within values, creating intermediate maps as necessary;
+// this mirrors the path semantics of `helm install --set`.
+func setValue(values map[string]interface{}, name string, v interface{}) {
+	keys := strings.Split(name, ".")
+	m := values
+	for _, k := range keys[:len(keys)-1] {
+		next, ok := m[k].(map[string]interface{})
+		if !ok {
+			next = make(map[string]interface{})
+			m[k] = next
+		}
+		m = next
+	}
+	m[keys[len(keys)-1]] = v
+}
+
+// mergeValues recursively merges src into dst, with src taking precedence on conflicting leaf values.
+func mergeValues(dst, src map[string]interface{}) {
+	for k, v := range src {
+		if srcMap, ok := v.(map[string]interface{}); ok {
+			if dstMap, ok := dst[k].(map[string]interface{}); ok {
+				mergeValues(dstMap, srcMap)
+				continue
+			}
+		}
+		dst[k] = v
+	}
+}
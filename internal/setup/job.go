@@ -17,18 +17,12 @@ limitations under the License.
 package setup
 
 import (
-	"encoding/base64"
 	"fmt"
 	"os"
-	"path"
 
 	optimizev1beta2 "github.com/thestormforge/optimize-controller/v2/api/v1beta2"
-	"github.com/thestormforge/optimize-controller/v2/internal/template"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/util/intstr"
-	"sigs.k8s.io/yaml"
 )
 
 // This is overwritten during builds to point to the actual image
@@ -47,8 +41,9 @@ var (
 // ":latest". To address this we always explicitly specify the pull policy corresponding to the image.
 // Finally, when using digests, the default of "IfNotPresent" is acceptable as it is unambiguous.
 
-// NewJob returns a new setup job for either create or delete
-func NewJob(t *optimizev1beta2.Trial, mode string) (*batchv1.Job, error) {
+// NewJob returns a new setup job for either create or delete. The namespace is optional and is only used
+// to resolve the Pod Security Admission profile to enforce when the trial does not specify one explicitly.
+func NewJob(t *optimizev1beta2.Trial, mode string, ns *corev1.Namespace) (*batchv1.Job, error) {
 	job := &batchv1.Job{}
 	job.Namespace = t.Namespace
 	job.Name = fmt.Sprintf("%s-%s", t.Name, mode)
@@ -85,6 +80,13 @@ func NewJob(t *optimizev1beta2.Trial, mode string) (*batchv1.Job, error) {
 		if (mode == ModeCreate && task.SkipCreate) || (mode == ModeDelete && task.SkipDelete) {
 			continue
 		}
+
+		// Helm chart tasks are reconciled in-process by the setup controller via internal/setup/helm,
+		// they no longer need a container in the setup job
+		if task.HelmChart != "" {
+			continue
+		}
+
 		c := corev1.Container{
 			Name:  fmt.Sprintf("%s-%s", job.Name, task.Name),
 			Image: task.Image,
@@ -128,85 +130,6 @@ func NewJob(t *optimizev1beta2.Trial, mode string) (*batchv1.Job, error) {
 		// Add the configured volume mounts
 		c.VolumeMounts = append(c.VolumeMounts, task.VolumeMounts...)
 
-		// For Helm installs, serialize a Konjure configuration
-		helmConfig := newHelmGeneratorConfig(&task)
-		if helmConfig != nil {
-			te := template.New()
-
-			// Helm Values
-			for _, hv := range task.HelmValues {
-				hgv := helmGeneratorValue{
-					Name:        hv.Name,
-					ForceString: hv.ForceString,
-				}
-
-				if hv.ValueFrom != nil {
-					// Evaluate the external value source
-					switch {
-					case hv.ValueFrom.ParameterRef != nil:
-						v, ok := t.GetAssignment(hv.ValueFrom.ParameterRef.Name)
-						if !ok {
-							return nil, fmt.Errorf("invalid parameter reference '%s' for Helm value '%s'", hv.ValueFrom.ParameterRef.Name, hv.Name)
-						}
-						if v.Type == intstr.String {
-							hgv.Value = v.StrVal
-						} else {
-							hgv.Value = v.IntVal
-						}
-
-					default:
-						return nil, fmt.Errorf("unknown source for Helm value '%s'", hv.Name)
-					}
-				} else {
-					// If there is no external source, evaluate the value field as a template
-					v, err := te.RenderHelmValue(&hv, t)
-					if err != nil {
-						return nil, err
-					}
-					hgv.Value = v
-				}
-
-				helmConfig.Values = append(helmConfig.Values, hgv)
-			}
-
-			// Helm Values From
-			for _, hvf := range task.HelmValuesFrom {
-				if hvf.ConfigMap != nil {
-					hgv := helmGeneratorValue{
-						File: path.Join("/workspace", "helm-values", hvf.ConfigMap.Name, "*values.yaml"),
-					}
-					vm := corev1.VolumeMount{
-						Name:      hvf.ConfigMap.Name,
-						MountPath: path.Dir(hgv.File),
-						ReadOnly:  true,
-					}
-
-					if _, ok := volumes[vm.Name]; !ok {
-						vs := corev1.VolumeSource{
-							ConfigMap: &corev1.ConfigMapVolumeSource{
-								LocalObjectReference: corev1.LocalObjectReference{Name: hvf.ConfigMap.Name},
-							},
-						}
-						volumes[vm.Name] = &corev1.Volume{Name: vm.Name, VolumeSource: vs}
-					}
-					c.VolumeMounts = append(c.VolumeMounts, vm)
-					helmConfig.Values = append(helmConfig.Values, hgv)
-				}
-			}
-
-			if task.HelmRepository != "" {
-				helmConfig.Repo = task.HelmRepository
-			}
-
-			// Record the base64 encoded YAML representation in the environment
-			b, err := yaml.Marshal(helmConfig)
-			if err != nil {
-				return nil, err
-			}
-
-			c.Env = append(c.Env, corev1.EnvVar{Name: "HELM_CONFIG", Value: base64.StdEncoding.EncodeToString(b)})
-		}
-
 		job.Spec.Template.Spec.Containers = append(job.Spec.Template.Spec.Containers, c)
 	}
 
@@ -215,40 +138,13 @@ func NewJob(t *optimizev1beta2.Trial, mode string) (*batchv1.Job, error) {
 		job.Spec.Template.Spec.Volumes = append(job.Spec.Template.Spec.Volumes, *v)
 	}
 
-	return job, nil
-}
-
-type helmGeneratorValue struct {
-	File        string      `json:"file,omitempty"`
-	Name        string      `json:"name,omitempty"`
-	Value       interface{} `json:"value,omitempty"`
-	ForceString bool        `json:"forceString,omitempty"`
-}
-
-type helmGeneratorConfig struct {
-	metav1.TypeMeta   `json:",inline"`
-	metav1.ObjectMeta `json:"metadata,omitempty"`
-	ReleaseName       string               `json:"releaseName"`
-	Chart             string               `json:"chart"`
-	Version           string               `json:"version"`
-	Repo              string               `json:"repo,omitempty"`
-	Values            []helmGeneratorValue `json:"values"`
-}
-
-func newHelmGeneratorConfig(task *optimizev1beta2.SetupTask) *helmGeneratorConfig {
-	if task.HelmChart == "" {
-		return nil
+	// Harden the job for the Pod Security Admission profile enforced on the target namespace (or
+	// explicitly requested on the trial), rejecting anything the API server would otherwise refuse
+	profile := ResolvePodSecurityProfile(t.Spec.PodSecurityProfile, ns)
+	if err := ValidateSetupVolumes(t.Spec.SetupVolumes, profile); err != nil {
+		return nil, err
 	}
+	ApplyPodSecurityProfile(job, profile)
 
-	cfg := &helmGeneratorConfig{
-		ReleaseName: task.Name,
-		Chart:       task.HelmChart,
-		Version:     task.HelmChartVersion,
-	}
-
-	cfg.APIVersion = "konjure.carbonrelay.com/v1beta1"
-	cfg.Kind = "HelmGenerator"
-	cfg.Name = task.Name
-
-	return cfg
+	return job, nil
 }
@@ -0,0 +1,206 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package setup
+
+import (
+	"fmt"
+
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// PodSecurityProfile identifies the Pod Security Admission profile a Job's Pod template should be hardened
+// against. The values correspond directly to the upstream "privileged", "baseline", and "restricted" profiles.
+type PodSecurityProfile string
+
+const (
+	// PodSecurityProfilePrivileged leaves the Job untouched, it is only appropriate for namespaces that do
+	// not enforce Pod Security Admission at all.
+	PodSecurityProfilePrivileged PodSecurityProfile = "privileged"
+	// PodSecurityProfileBaseline hardens the Job just enough to satisfy the "baseline" profile (no
+	// privileged containers, no host namespaces, no host path volumes).
+	PodSecurityProfileBaseline PodSecurityProfile = "baseline"
+	// PodSecurityProfileRestricted additionally requires a non-root user, a default seccomp profile, and
+	// containers that drop all capabilities and cannot escalate privileges.
+	PodSecurityProfileRestricted PodSecurityProfile = "restricted"
+)
+
+// PodSecurityEnforceLabel is the well known namespace label used by the Pod Security Admission controller
+// to select the profile that is enforced for pods created in that namespace.
+const PodSecurityEnforceLabel = "pod-security.kubernetes.io/enforce"
+
+// ResolvePodSecurityProfile determines the profile that should be applied to a trial's Jobs. An explicit
+// value (typically `Trial.Spec.PodSecurityProfile`) always wins, followed by the namespace's enforce label,
+// and finally falling back to the "baseline" profile.
+func ResolvePodSecurityProfile(explicit string, namespace *corev1.Namespace) PodSecurityProfile {
+	if p := PodSecurityProfile(explicit); isValidPodSecurityProfile(p) {
+		return p
+	}
+
+	if namespace != nil {
+		if p := PodSecurityProfile(namespace.Labels[PodSecurityEnforceLabel]); isValidPodSecurityProfile(p) {
+			return p
+		}
+	}
+
+	return PodSecurityProfileBaseline
+}
+
+func isValidPodSecurityProfile(p PodSecurityProfile) bool {
+	switch p {
+	case PodSecurityProfilePrivileged, PodSecurityProfileBaseline, PodSecurityProfileRestricted:
+		return true
+	default:
+		return false
+	}
+}
+
+// ApplyPodSecurityProfile hardens the pod and container security contexts of a Job to satisfy the supplied
+// profile. Fields that are already set (e.g. by a user supplied job template or setup task) are left alone
+// so this can safely be applied as a final, cross-cutting step over an otherwise complete Job.
+func ApplyPodSecurityProfile(job *batchv1.Job, profile PodSecurityProfile) {
+	if profile == PodSecurityProfilePrivileged {
+		return
+	}
+
+	pod := &job.Spec.Template.Spec
+	if pod.SecurityContext == nil {
+		pod.SecurityContext = &corev1.PodSecurityContext{}
+	}
+	applyPodSecurityContext(pod.SecurityContext, profile)
+
+	for i := range pod.Containers {
+		applyContainerSecurityContext(&pod.Containers[i], profile)
+	}
+	for i := range pod.InitContainers {
+		applyContainerSecurityContext(&pod.InitContainers[i], profile)
+	}
+}
+
+func applyPodSecurityContext(sc *corev1.PodSecurityContext, profile PodSecurityProfile) {
+	if sc.RunAsNonRoot == nil {
+		runAsNonRoot := true
+		sc.RunAsNonRoot = &runAsNonRoot
+	}
+
+	if profile == PodSecurityProfileRestricted && sc.SeccompProfile == nil {
+		sc.SeccompProfile = &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault}
+	}
+}
+
+func applyContainerSecurityContext(c *corev1.Container, profile PodSecurityProfile) {
+	if c.SecurityContext == nil {
+		c.SecurityContext = &corev1.SecurityContext{}
+	}
+	sc := c.SecurityContext
+
+	if sc.Privileged == nil {
+		privileged := false
+		sc.Privileged = &privileged
+	}
+	if sc.AllowPrivilegeEscalation == nil {
+		allowPrivilegeEscalation := false
+		sc.AllowPrivilegeEscalation = &allowPrivilegeEscalation
+	}
+
+	if profile != PodSecurityProfileRestricted {
+		return
+	}
+
+	if sc.RunAsNonRoot == nil {
+		runAsNonRoot := true
+		sc.RunAsNonRoot = &runAsNonRoot
+	}
+	if sc.SeccompProfile == nil {
+		sc.SeccompProfile = &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault}
+	}
+	if sc.Capabilities == nil {
+		sc.Capabilities = &corev1.Capabilities{}
+	}
+	if len(sc.Capabilities.Drop) == 0 {
+		sc.Capabilities.Drop = []corev1.Capability{"ALL"}
+	}
+}
+
+// ValidateJobTemplate checks a user supplied job template for settings that Pod Security Admission would
+// reject under the given profile, returning an error describing the first violation found.
+func ValidateJobTemplate(jobTemplate *batchv1beta1.JobTemplateSpec, profile PodSecurityProfile) error {
+	if jobTemplate == nil || profile == PodSecurityProfilePrivileged {
+		return nil
+	}
+
+	pod := &jobTemplate.Spec.Template.Spec
+	if pod.HostNetwork || pod.HostPID || pod.HostIPC {
+		return fmt.Errorf("jobTemplate uses a host namespace which is not allowed by the %q Pod Security profile", profile)
+	}
+
+	for _, v := range pod.Volumes {
+		if v.HostPath != nil {
+			return fmt.Errorf("jobTemplate volume %q uses hostPath which is not allowed by the %q Pod Security profile", v.Name, profile)
+		}
+	}
+
+	containers := append(append([]corev1.Container{}, pod.InitContainers...), pod.Containers...)
+	for _, c := range containers {
+		if err := validateContainerSecurityContext(c, profile); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ValidateSetupVolumes checks the trial's setup volumes for settings that Pod Security Admission would
+// reject under the given profile.
+func ValidateSetupVolumes(volumes []corev1.Volume, profile PodSecurityProfile) error {
+	if profile == PodSecurityProfilePrivileged {
+		return nil
+	}
+
+	for _, v := range volumes {
+		if v.HostPath != nil {
+			return fmt.Errorf("setup volume %q uses hostPath which is not allowed by the %q Pod Security profile", v.Name, profile)
+		}
+	}
+
+	return nil
+}
+
+func validateContainerSecurityContext(c corev1.Container, profile PodSecurityProfile) error {
+	sc := c.SecurityContext
+	if sc == nil {
+		return nil
+	}
+
+	if sc.Privileged != nil && *sc.Privileged {
+		return fmt.Errorf("container %q is privileged which is not allowed by the %q Pod Security profile", c.Name, profile)
+	}
+
+	if profile != PodSecurityProfileRestricted {
+		return nil
+	}
+
+	if sc.AllowPrivilegeEscalation != nil && *sc.AllowPrivilegeEscalation {
+		return fmt.Errorf("container %q allows privilege escalation which is not allowed by the %q Pod Security profile", c.Name, profile)
+	}
+	if sc.RunAsNonRoot != nil && !*sc.RunAsNonRoot {
+		return fmt.Errorf("container %q must not run as root under the %q Pod Security profile", c.Name, profile)
+	}
+
+	return nil
+}
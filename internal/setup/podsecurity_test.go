@@ -0,0 +1,92 @@
+/*
+Copyright 2023 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package setup_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thestormforge/optimize-controller/v2/internal/setup"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestResolvePodSecurityProfile(t *testing.T) {
+	ns := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{setup.PodSecurityEnforceLabel: "restricted"},
+		},
+	}
+
+	assert.Equal(t, setup.PodSecurityProfileRestricted, setup.ResolvePodSecurityProfile("", ns))
+	assert.Equal(t, setup.PodSecurityProfilePrivileged, setup.ResolvePodSecurityProfile("privileged", ns))
+	assert.Equal(t, setup.PodSecurityProfileBaseline, setup.ResolvePodSecurityProfile("", nil))
+	assert.Equal(t, setup.PodSecurityProfileBaseline, setup.ResolvePodSecurityProfile("not-a-profile", nil))
+}
+
+func TestApplyPodSecurityProfile(t *testing.T) {
+	job := &batchv1.Job{
+		Spec: batchv1.JobSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "main"}},
+				},
+			},
+		},
+	}
+
+	setup.ApplyPodSecurityProfile(job, setup.PodSecurityProfileRestricted)
+
+	pod := job.Spec.Template.Spec
+	assert.True(t, *pod.SecurityContext.RunAsNonRoot)
+	assert.Equal(t, corev1.SeccompProfileTypeRuntimeDefault, pod.SecurityContext.SeccompProfile.Type)
+
+	c := pod.Containers[0]
+	assert.False(t, *c.SecurityContext.AllowPrivilegeEscalation)
+	assert.False(t, *c.SecurityContext.Privileged)
+	assert.Equal(t, []corev1.Capability{"ALL"}, c.SecurityContext.Capabilities.Drop)
+}
+
+func TestValidateJobTemplate(t *testing.T) {
+	privileged := true
+	jobTemplate := &batchv1beta1.JobTemplateSpec{
+		Spec: batchv1.JobSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					HostNetwork: true,
+					Containers: []corev1.Container{
+						{Name: "main", SecurityContext: &corev1.SecurityContext{Privileged: &privileged}},
+					},
+				},
+			},
+		},
+	}
+
+	assert.NoError(t, setup.ValidateJobTemplate(jobTemplate, setup.PodSecurityProfilePrivileged))
+	assert.Error(t, setup.ValidateJobTemplate(jobTemplate, setup.PodSecurityProfileBaseline))
+}
+
+func TestValidateSetupVolumes(t *testing.T) {
+	volumes := []corev1.Volume{
+		{Name: "hostdata", VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/data"}}},
+	}
+
+	assert.NoError(t, setup.ValidateSetupVolumes(volumes, setup.PodSecurityProfilePrivileged))
+	assert.Error(t, setup.ValidateSetupVolumes(volumes, setup.PodSecurityProfileBaseline))
+}
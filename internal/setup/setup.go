@@ -24,6 +24,7 @@ import (
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
 const (
@@ -45,6 +46,10 @@ func UpdateStatus(t *optimizev1beta2.Trial, probeTime *metav1.Time) bool {
 		needsCreate = needsCreate || !task.SkipCreate
 		needsDelete = needsDelete || !task.SkipDelete
 	}
+	if t.Spec.SetupBundle != nil && len(t.Spec.SetupBundle.Releases) > 0 {
+		needsCreate = true
+		needsDelete = true
+	}
 
 	// Short circuit, there are no setup tasks
 	if !needsCreate && !needsDelete {
@@ -144,6 +149,39 @@ func GetConditionStatus(j *batchv1.Job) (corev1.ConditionStatus, string) {
 	return corev1.ConditionFalse, ""
 }
 
+// GetTaskRunConditionStatus returns condition True for a finished Tekton TaskRun or condition False
+// for a TaskRun still in progress. This mirrors `GetConditionStatus`, but reads the TaskRun's
+// `Succeeded` duck-type condition instead of a Job's `Complete`/`Failed` conditions, allowing the
+// controller to poll a TaskRun in place of a Job when the Tekton build backend is used.
+func GetTaskRunConditionStatus(tr *unstructured.Unstructured) (corev1.ConditionStatus, string) {
+	conditions, _, _ := unstructured.NestedSlice(tr.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if condition["type"] != "Succeeded" {
+			continue
+		}
+
+		status, _, _ := unstructured.NestedString(condition, "status")
+		switch corev1.ConditionStatus(status) {
+		case corev1.ConditionTrue:
+			return corev1.ConditionTrue, ""
+		case corev1.ConditionFalse:
+			reason, _, _ := unstructured.NestedString(condition, "reason")
+			message, _, _ := unstructured.NestedString(condition, "message")
+			if message == "" {
+				message = fmt.Sprintf("TaskRun failed with reason '%s'", reason)
+			}
+			return corev1.ConditionTrue, message
+		}
+	}
+
+	return corev1.ConditionFalse, ""
+}
+
 // AppendAssignmentEnv appends an environment variable for each trial assignment
 func AppendAssignmentEnv(t *optimizev1beta2.Trial, env []corev1.EnvVar) []corev1.EnvVar {
 	for _, a := range t.Spec.Assignments {
@@ -158,8 +196,13 @@ func AppendAssignmentEnv(t *optimizev1beta2.Trial, env []corev1.EnvVar) []corev1
 func AppendPrometheusEnv(t *optimizev1beta2.Trial, env []corev1.EnvVar) []corev1.EnvVar {
 	for i := range t.Spec.SetupTasks {
 		if IsPrometheusSetupTask(&t.Spec.SetupTasks[i]) {
-			url := fmt.Sprintf("http://optimize-%s-prometheus:9091/metrics/job/%s/instance/%s", t.Namespace, "trialRun", t.Name)
-			return append(env, corev1.EnvVar{Name: "PUSHGATEWAY_URL", Value: url})
+			host := fmt.Sprintf("optimize-%s-prometheus", t.Namespace)
+			pushGatewayURL := fmt.Sprintf("http://%s:9091/metrics/job/%s/instance/%s", host, "trialRun", t.Name)
+			remoteWriteURL := fmt.Sprintf("http://%s:9090/api/v1/write", host)
+			return append(env,
+				corev1.EnvVar{Name: "PUSHGATEWAY_URL", Value: pushGatewayURL},
+				corev1.EnvVar{Name: "K6_PROMETHEUS_RW_SERVER_URL", Value: remoteWriteURL},
+			)
 		}
 	}
 
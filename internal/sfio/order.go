@@ -31,6 +31,16 @@ func init() {
 	addFieldOrder(&optimizev1beta2.PatchTemplate{}, 400)
 	addFieldOrder(&optimizev1beta2.Metric{}, 500)
 	addFieldOrder(&optimizeappsv1alpha1.Application{}, 600)
+	addFieldOrder(&tektonPipelineRunSpec{}, 700)
+}
+
+// tektonPipelineRunSpec mirrors the field order of a Tekton `PipelineRun` spec; it exists
+// purely to seed the YAML field order since this project does not vendor the Tekton API types.
+type tektonPipelineRunSpec struct {
+	ServiceAccountName string        `json:"serviceAccountName,omitempty"`
+	PipelineRef        interface{}   `json:"pipelineRef,omitempty"`
+	Params             []interface{} `json:"params,omitempty"`
+	Workspaces         []interface{} `json:"workspaces,omitempty"`
 }
 
 // addFieldOrder use reflection to try and make the YAML sort order match the Go struct field order.
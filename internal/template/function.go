@@ -18,7 +18,6 @@ package template
 
 import (
 	"fmt"
-	"strconv"
 	"strings"
 	"text/template"
 	"time"
@@ -26,6 +25,7 @@ import (
 	"github.com/Masterminds/sprig"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
 )
 
 // FuncMap returns the functions used for template evaluation
@@ -38,7 +38,11 @@ func FuncMap() template.FuncMap {
 		"duration":          duration,
 		"percent":           percent,
 		"resourceRequests":  resourceRequests,
+		"weightedResources": weightedResources,
 		"indexResource":     indexResource,
+		"percentile":        percentile,
+		"histogramQuantile": histogramQuantile,
+		"hdrPercentile":     hdrPercentile,
 		"cpuUtilization":    cpuUtilization,
 		"memoryUtilization": memoryUtilization,
 		"cpuRequests":       cpuRequests,
@@ -71,27 +75,152 @@ func percent(value int32, percent int32) string {
 	return fmt.Sprintf("%d", int64(float64(value)*(float64(percent)/100.0)))
 }
 
-// resourceRequests uses a map of resource types to weights to calculate a weighted sum of the resource requests
+// resourceRequests uses a map of resource types to weights to calculate a weighted sum of the
+// resource requests. It is a backwards compatible wrapper around weightedResources using the
+// "requests" source and no usage or fallback options.
 func resourceRequests(pods corev1.PodList, weights string) (float64, error) {
-	var totalResources float64
-	parsedWeights := make(map[string]float64)
+	return weightedResources(pods, weights, nil)
+}
 
+// parseResourceWeights parses a comma-separated "type=quantity" weight string (e.g.
+// "cpu=0.5,memory=1Gi,ephemeral-storage=100Mi") into a map of resource type to weight.
+func parseResourceWeights(weights string) (map[corev1.ResourceName]resource.Quantity, error) {
+	parsedWeights := make(map[corev1.ResourceName]resource.Quantity)
 	for _, singleEntry := range strings.Split(weights, ",") {
-		parsedEntry := strings.Split(singleEntry, "=")
-		weight, err := strconv.ParseFloat(parsedEntry[1], 64)
+		parsedEntry := strings.SplitN(singleEntry, "=", 2)
+		if len(parsedEntry) != 2 {
+			return nil, fmt.Errorf("unable to parse weight %q", singleEntry)
+		}
+		weight, err := resource.ParseQuantity(parsedEntry[1])
 		if err != nil {
-			return 0.0, fmt.Errorf("unable to parse weight for %s", parsedEntry[0])
+			return nil, fmt.Errorf("unable to parse weight for %s: %w", parsedEntry[0], err)
+		}
+		parsedWeights[corev1.ResourceName(parsedEntry[0])] = weight
+	}
+	return parsedWeights, nil
+}
+
+// containerResourceQuantity looks up a single resource type from a container's requests or
+// limits, falling back to the supplied fallback source (if any) when the primary source has no
+// entry for that resource. It returns false if neither source has an entry.
+func containerResourceQuantity(container corev1.Container, resourceType corev1.ResourceName, source, fallback string) (resource.Quantity, bool) {
+	var primary corev1.ResourceList
+	switch source {
+	case "limits":
+		primary = container.Resources.Limits
+	default:
+		primary = container.Resources.Requests
+	}
+
+	if q, ok := primary[resourceType]; ok {
+		return q, true
+	}
+
+	switch fallback {
+	case "limits":
+		if q, ok := container.Resources.Limits[resourceType]; ok {
+			return q, true
+		}
+	case "requests":
+		if q, ok := container.Resources.Requests[resourceType]; ok {
+			return q, true
+		}
+	}
+
+	return resource.Quantity{}, false
+}
+
+// containerUsageKey uniquely identifies a container within a PodMetricsList for indexContainerUsage.
+func containerUsageKey(namespace, podName, containerName string) string {
+	return namespace + "/" + podName + "/" + containerName
+}
+
+// indexContainerUsage builds a lookup of container usage (as reported by a metrics.k8s.io
+// PodMetricsList) keyed by namespace/pod/container.
+func indexContainerUsage(podMetrics *metricsv1beta1.PodMetricsList) map[string]corev1.ResourceList {
+	usage := make(map[string]corev1.ResourceList)
+	if podMetrics == nil {
+		return usage
+	}
+	for _, pod := range podMetrics.Items {
+		for _, c := range pod.Containers {
+			usage[containerUsageKey(pod.Namespace, pod.Name, c.Name)] = c.Usage
 		}
-		parsedWeights[parsedEntry[0]] = weight
 	}
+	return usage
+}
+
+// weightedResources sums a weighted combination of container resource quantities across a pod
+// list, the way resourceRequests always has, but supports selecting the source of the resource
+// quantity summed for each container and a fallback for containers missing an entry.
+//
+// Recognized opts:
+//   - "source": one of "requests" (default), "limits", "usage", or "max" (the larger of requests
+//     and usage)
+//   - "podMetrics": a *metricsv1beta1.PodMetricsList, required when source is "usage" or "max"
+//   - "fallback": one of "zero" (default) or "limits", used when a container has no entry for a
+//     weighted resource type under the selected source
+//   - "includeInitContainers": bool, default false
+func weightedResources(pods corev1.PodList, weights string, opts map[string]interface{}) (float64, error) {
+	parsedWeights, err := parseResourceWeights(weights)
+	if err != nil {
+		return 0.0, err
+	}
+
+	source, _ := opts["source"].(string)
+	if source == "" {
+		source = "requests"
+	}
+	fallback, _ := opts["fallback"].(string)
+	if fallback == "" {
+		fallback = "zero"
+	}
+	includeInitContainers, _ := opts["includeInitContainers"].(bool)
+	podMetrics, _ := opts["podMetrics"].(*metricsv1beta1.PodMetricsList)
+	usageIndex := indexContainerUsage(podMetrics)
+
+	var totalResources float64
+
 	for _, pod := range pods.Items {
-		for _, container := range pod.Spec.Containers {
+		containers := pod.Spec.Containers
+		if includeInitContainers {
+			containers = append(append([]corev1.Container{}, pod.Spec.InitContainers...), containers...)
+		}
+
+		for _, container := range containers {
+			usage := usageIndex[containerUsageKey(pod.Namespace, pod.Name, container.Name)]
+
 			for resourceType, weight := range parsedWeights {
-				resourceValue := container.Resources.Requests[corev1.ResourceName(resourceType)]
-				totalResources += weight * float64(resourceValue.MilliValue())
+				var value float64
+				var found bool
+
+				switch source {
+				case "usage":
+					if q, ok := usage[resourceType]; ok {
+						value, found = q.AsApproximateFloat64(), true
+					}
+				case "max":
+					if q, ok := containerResourceQuantity(container, resourceType, "requests", fallback); ok {
+						value, found = q.AsApproximateFloat64(), true
+					}
+					if q, ok := usage[resourceType]; ok && q.AsApproximateFloat64() > value {
+						value, found = q.AsApproximateFloat64(), true
+					}
+				default:
+					if q, ok := containerResourceQuantity(container, resourceType, source, fallback); ok {
+						value, found = q.AsApproximateFloat64(), true
+					}
+				}
+
+				if !found {
+					continue
+				}
+
+				totalResources += weight.AsApproximateFloat64() * value
 			}
 		}
 	}
+
 	return totalResources, nil
 }
 
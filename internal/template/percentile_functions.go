@@ -0,0 +1,291 @@
+/*
+Copyright 2020 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package template
+
+import (
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// hdrSubBucketsPerPowerOfTwo is the number of log-linear subbuckets used for each power of two
+// covered by hdrPercentile, bounding its memory use regardless of how many raw samples it sees.
+const hdrSubBucketsPerPowerOfTwo = 8
+
+// percentile evaluates path against data, coerces the matched values to float64, and returns the
+// linearly interpolated q-th percentile (0.0-1.0). Empty input or a JSONPath parse/match error is
+// treated the same way sum is: it returns 0 rather than an error.
+func percentile(data interface{}, path string, q float64) float64 {
+	values := jsonPathFloats(data, path)
+	if len(values) == 0 {
+		return 0
+	}
+
+	sort.Float64s(values)
+	return interpolatedPercentile(values, q)
+}
+
+// interpolatedPercentile returns the linearly interpolated q-th percentile (0.0-1.0) of an
+// already sorted, non-empty slice.
+func interpolatedPercentile(sorted []float64, q float64) float64 {
+	switch {
+	case len(sorted) == 1 || q <= 0:
+		return sorted[0]
+	case q >= 1:
+		return sorted[len(sorted)-1]
+	}
+
+	rank := q * float64(len(sorted)-1)
+	lo, hi := int(math.Floor(rank)), int(math.Ceil(rank))
+	if lo == hi {
+		return sorted[lo]
+	}
+
+	frac := rank - float64(lo)
+	return sorted[lo] + frac*(sorted[hi]-sorted[lo])
+}
+
+// histogramQuantile evaluates path against data to obtain a list of Prometheus-shaped bucket
+// objects (each exposing a "le" and a cumulative "count", as a map or a struct), and implements
+// the standard Prometheus quantile recurrence over them: the bucket where the cumulative count
+// crosses q*total is found, then the result is linearly interpolated between the previous "le"
+// and that bucket's "le" using (rank-prevCount)/(count-prevCount). The +Inf bucket, if reached,
+// returns the previous finite "le" rather than interpolating into infinity. Empty input or a
+// parse/match error returns 0, matching sum.
+func histogramQuantile(data interface{}, path string, q float64) float64 {
+	type bucket struct{ le, count float64 }
+
+	var buckets []bucket
+	for _, row := range jsonPathRaw(data, path) {
+		le, count, ok := bucketLeCount(row)
+		if !ok {
+			continue
+		}
+		buckets = append(buckets, bucket{le: le, count: count})
+	}
+	if len(buckets) == 0 {
+		return 0
+	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i].le < buckets[j].le })
+
+	total := buckets[len(buckets)-1].count
+	if total <= 0 {
+		return 0
+	}
+
+	rank := q * total
+	prevLe, prevCount := 0.0, 0.0
+	for _, b := range buckets {
+		if math.IsInf(b.le, 1) {
+			return prevLe
+		}
+		if b.count >= rank {
+			if b.count == prevCount {
+				return b.le
+			}
+			frac := (rank - prevCount) / (b.count - prevCount)
+			return prevLe + frac*(b.le-prevLe)
+		}
+		prevLe, prevCount = b.le, b.count
+	}
+	return prevLe
+}
+
+// bucketLeCount extracts the "le" and "count" fields from a single histogram bucket, which may be
+// a map (as produced by decoding JSON) or a struct (discovered by field name or json tag, case
+// insensitive).
+func bucketLeCount(v interface{}) (le, count float64, ok bool) {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+
+	switch rv.Kind() {
+	case reflect.Map:
+		leVal, leOK := mapFloat(rv, "le")
+		countVal, countOK := mapFloat(rv, "count")
+		return leVal, countVal, leOK && countOK
+	case reflect.Struct:
+		leVal, leOK := structFloat(rv, "le")
+		countVal, countOK := structFloat(rv, "count")
+		return leVal, countVal, leOK && countOK
+	default:
+		return 0, 0, false
+	}
+}
+
+func mapFloat(rv reflect.Value, key string) (float64, bool) {
+	for _, k := range rv.MapKeys() {
+		if !strings.EqualFold(reflect.ValueOf(k.Interface()).String(), key) {
+			continue
+		}
+		return valueToFloat64(rv.MapIndex(k).Interface())
+	}
+	return 0, false
+}
+
+func structFloat(rv reflect.Value, name string) (float64, bool) {
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tagName := strings.Split(f.Tag.Get("json"), ",")[0]
+		if !strings.EqualFold(f.Name, name) && !strings.EqualFold(tagName, name) {
+			continue
+		}
+		return valueToFloat64(rv.Field(i).Interface())
+	}
+	return 0, false
+}
+
+// hdrPercentile is like percentile, but accumulates the matched values into a log-linear sparse
+// histogram (base 2, hdrSubBucketsPerPowerOfTwo subbuckets per power of two) before extracting the
+// quantile, giving a stable p99/p99.9 with bounded memory when path matches tens of thousands of
+// raw samples.
+func hdrPercentile(data interface{}, path string, q float64) float64 {
+	values := jsonPathFloats(data, path)
+	if len(values) == 0 {
+		return 0
+	}
+
+	counts := make(map[int]int, len(values))
+	total := 0
+	for _, v := range values {
+		if v <= 0 {
+			continue
+		}
+		counts[hdrBucketIndex(v)]++
+		total++
+	}
+	if total == 0 {
+		return 0
+	}
+
+	indices := make([]int, 0, len(counts))
+	for idx := range counts {
+		indices = append(indices, idx)
+	}
+	sort.Ints(indices)
+
+	rank := int(math.Ceil(q * float64(total)))
+	if rank < 1 {
+		rank = 1
+	}
+
+	cumulative := 0
+	for _, idx := range indices {
+		cumulative += counts[idx]
+		if cumulative >= rank {
+			return hdrBucketValue(idx)
+		}
+	}
+	return hdrBucketValue(indices[len(indices)-1])
+}
+
+// hdrBucketIndex returns the subbucket a value falls into: the power of two containing it,
+// subdivided into hdrSubBucketsPerPowerOfTwo equal (in ratio) slices.
+func hdrBucketIndex(v float64) int {
+	exp := int(math.Floor(math.Log2(v)))
+	frac := v / math.Pow(2, float64(exp)) // in [1, 2)
+	sub := int(math.Floor((frac - 1) * hdrSubBucketsPerPowerOfTwo))
+	if sub >= hdrSubBucketsPerPowerOfTwo {
+		sub = hdrSubBucketsPerPowerOfTwo - 1
+	}
+	return exp*hdrSubBucketsPerPowerOfTwo + sub
+}
+
+// hdrBucketValue returns the midpoint of the value range represented by a hdrBucketIndex result.
+func hdrBucketValue(idx int) float64 {
+	exp, sub := floorDivMod(idx, hdrSubBucketsPerPowerOfTwo)
+	base := math.Pow(2, float64(exp))
+	lower := base * (1 + float64(sub)/hdrSubBucketsPerPowerOfTwo)
+	upper := base * (1 + float64(sub+1)/hdrSubBucketsPerPowerOfTwo)
+	return (lower + upper) / 2
+}
+
+// floorDivMod is like a/b, a%b but rounds toward negative infinity instead of toward zero, so it
+// behaves consistently for the negative indices produced by hdrBucketIndex on values below 1.
+func floorDivMod(a, b int) (q, r int) {
+	q, r = a/b, a%b
+	if r < 0 {
+		q--
+		r += b
+	}
+	return q, r
+}
+
+// jsonPathFloats evaluates path against data and coerces every matched value to a float64,
+// silently dropping values that can't be converted. A parse or match error returns nil.
+func jsonPathFloats(data interface{}, path string) []float64 {
+	var out []float64
+	for _, v := range jsonPathRaw(data, path) {
+		if f, ok := valueToFloat64(v); ok {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// jsonPathRaw evaluates path against data and returns the raw matched values. A parse or match
+// error returns nil rather than an error, the same way sum treats bad input.
+func jsonPathRaw(data interface{}, path string) []interface{} {
+	jp := jsonpath.New("percentile")
+	if err := jp.Parse(path); err != nil {
+		return nil
+	}
+
+	results, err := jp.FindResults(data)
+	if err != nil {
+		return nil
+	}
+
+	var out []interface{}
+	for _, row := range results {
+		for _, v := range row {
+			out = append(out, v.Interface())
+		}
+	}
+	return out
+}
+
+// valueToFloat64 coerces a JSONPath match to a float64, handling resource.Quantity (scaled the
+// way resourceRequests used to scale its own values, via MilliValue) in addition to the numeric
+// and string kinds text/template normally produces.
+func valueToFloat64(v interface{}) (float64, bool) {
+	switch t := v.(type) {
+	case resource.Quantity:
+		return float64(t.MilliValue()) / 1000.0, true
+	case *resource.Quantity:
+		return float64(t.MilliValue()) / 1000.0, true
+	case string:
+		f, err := strconv.ParseFloat(t, 64)
+		return f, err == nil
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	default:
+		return 0, false
+	}
+}
@@ -184,7 +184,90 @@ func TestEngine_RenderMetricQueries(t *testing.T) {
 					},
 				},
 			},
-			expectedQuery: "25010",
+			expectedQuery: "25.01",
+		},
+
+		{
+			desc: "function weightedResources with limits fallback",
+			metric: optimizev1beta2.Metric{
+				Name:  "testMetric",
+				Query: `{{weightedResources .Pods "cpu=1" (dict "fallback" "limits")}}`,
+			},
+			target: &corev1.PodList{
+				Items: []corev1.Pod{
+					{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "testpod1",
+							Namespace: "default",
+						},
+						Spec: corev1.PodSpec{
+							InitContainers: []corev1.Container{
+								{
+									Name: "testInitContainer",
+									Resources: corev1.ResourceRequirements{
+										Requests: corev1.ResourceList{
+											corev1.ResourceCPU: resource.MustParse("900m"),
+										},
+									},
+								},
+							},
+							Containers: []corev1.Container{
+								{
+									Name: "testContainer1",
+									Resources: corev1.ResourceRequirements{
+										Limits: corev1.ResourceList{
+											corev1.ResourceCPU: resource.MustParse("500m"),
+										},
+									},
+								},
+								{
+									Name: "testContainer2",
+									Resources: corev1.ResourceRequirements{
+										Requests: corev1.ResourceList{
+											corev1.ResourceCPU: resource.MustParse("300m"),
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedQuery: "0.8",
+		},
+
+		{
+			desc: "function percentile",
+			metric: optimizev1beta2.Metric{
+				Name:  "testMetric",
+				Query: `{{percentile (dict "values" (list 10.0 20.0 30.0 40.0 100.0)) "{.values[*]}" 0.5}}`,
+			},
+			target:        &corev1.Pod{},
+			expectedQuery: "30",
+		},
+
+		{
+			desc: "function histogramQuantile",
+			metric: optimizev1beta2.Metric{
+				Name: "testMetric",
+				Query: `{{histogramQuantile (dict "buckets" (list ` +
+					`(dict "le" 1.0 "count" 3.0) ` +
+					`(dict "le" 2.0 "count" 8.0) ` +
+					`(dict "le" 4.0 "count" 10.0) ` +
+					`(dict "le" "+Inf" "count" 10.0))) "{.buckets[*]}" 0.9}}`,
+			},
+			target:        &corev1.Pod{},
+			expectedQuery: "3",
+		},
+
+		{
+			desc: "function hdrPercentile",
+			metric: optimizev1beta2.Metric{
+				Name:  "testMetric",
+				Query: `{{hdrPercentile (dict "values" (list 100.0 100.0 100.0 100.0 100.0)) "{.values[*]}" 0.99}}`,
+			},
+			target:        &corev1.Pod{},
+			expectedQuery: "100",
 		},
 
 		{
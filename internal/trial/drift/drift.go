@@ -0,0 +1,294 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package drift detects patch targets that have silently reverted away from the values a trial assigned to
+// them, for example because a webhook or a GitOps controller reconciled the live object back to its own
+// desired state in the middle of a trial run. The approach is the same one used by PipeCD's drift detector:
+// diff the values the trial expects against what is actually observed on the cluster, rather than trusting
+// that a patch which was successfully submitted to the API server is still in effect later on.
+package drift
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	optimizev1beta2 "github.com/thestormforge/optimize-controller/v2/api/v1beta2"
+	"github.com/thestormforge/optimize-controller/v2/internal/trial"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Field identifies a single diverged value on a patch target.
+type Field struct {
+	// TargetRef is the object the diverged field was found on
+	TargetRef string `json:"targetRef"`
+	// Path is the JSON pointer path of the diverged field within the target
+	Path string `json:"path"`
+	// Expected is the value the trial's patch assigned to Path
+	Expected string `json:"expected"`
+	// Actual is the value currently observed at Path
+	Actual string `json:"actual"`
+}
+
+// String renders a Field as a single line suitable for a condition message or log entry.
+func (f Field) String() string {
+	return fmt.Sprintf("%s %s: expected %q, got %q", f.TargetRef, f.Path, f.Expected, f.Actual)
+}
+
+// Detector compares a trial's applied patches against the live state of their targets.
+type Detector struct {
+	// Reader is used to fetch the current state of patch targets
+	Reader client.Reader
+	// Cache, if set, remembers the diff last computed for each patch target so a target whose
+	// resourceVersion has not changed since the last check does not need to be re-diffed
+	Cache *Cache
+}
+
+// Check returns the fields (if any) whose live value no longer matches what the trial's patches assigned to
+// them. Patches that have not yet been successfully applied, and patches against the trial run job itself
+// (which is never patched through the API), are not considered.
+func (d *Detector) Check(ctx context.Context, t *optimizev1beta2.Trial) ([]Field, error) {
+	var drift []Field
+	for i := range t.Status.PatchOperations {
+		p := &t.Status.PatchOperations[i]
+		if p.AttemptsRemaining != 0 || trial.IsTrialJobReference(t, &p.TargetRef) {
+			continue
+		}
+
+		expected, err := leafValues(p.PatchType, p.Data)
+		if err != nil {
+			return nil, err
+		}
+		if len(expected) == 0 {
+			continue
+		}
+
+		u := &unstructured.Unstructured{}
+		u.SetGroupVersionKind(p.TargetRef.GroupVersionKind())
+		key := client.ObjectKey{Namespace: p.TargetRef.Namespace, Name: p.TargetRef.Name}
+		if err := d.Reader.Get(ctx, key, u); err != nil {
+			return nil, err
+		}
+
+		cacheKey := cacheKey{gvk: p.TargetRef.GroupVersionKind(), namespace: p.TargetRef.Namespace, name: p.TargetRef.Name, trialUID: t.UID}
+		if d.Cache != nil {
+			if cached, ok := d.Cache.get(cacheKey, u.GetResourceVersion()); ok {
+				drift = append(drift, cached...)
+				continue
+			}
+		}
+
+		targetRef := fmt.Sprintf("%s/%s", p.TargetRef.Kind, p.TargetRef.Name)
+		var targetDrift []Field
+		for _, path := range sortedKeys(expected) {
+			actual, ok := valueAtPointer(u.Object, path)
+			if !ok || !equalValues(expected[path], actual) {
+				targetDrift = append(targetDrift, Field{
+					TargetRef: targetRef,
+					Path:      path,
+					Expected:  fmt.Sprintf("%v", expected[path]),
+					Actual:    fmt.Sprintf("%v", actual),
+				})
+			}
+		}
+
+		if d.Cache != nil {
+			d.Cache.put(cacheKey, u.GetResourceVersion(), targetDrift)
+		}
+		drift = append(drift, targetDrift...)
+	}
+	return drift, nil
+}
+
+// OriginalPatch computes a JSON-Patch that would restore the fields p touches back to the values they held
+// on live immediately before p is applied, for later use as a rollback. The result always uses RFC 6902
+// JSON-Patch regardless of p's own PatchType, since restoring a specific set of previously observed leaf
+// values by path is meaningful no matter how they were originally assigned. Returns a nil patch if p does
+// not assign any values (for example an empty JSON-Patch).
+func OriginalPatch(p *optimizev1beta2.PatchOperation, live *unstructured.Unstructured) ([]byte, error) {
+	touched, err := leafValues(p.PatchType, p.Data)
+	if err != nil {
+		return nil, err
+	}
+	if len(touched) == 0 {
+		return nil, nil
+	}
+
+	type jsonPatchOp struct {
+		Op    string      `json:"op"`
+		Path  string      `json:"path"`
+		Value interface{} `json:"value,omitempty"`
+	}
+
+	ops := make([]jsonPatchOp, 0, len(touched))
+	for _, path := range sortedKeys(touched) {
+		if original, ok := valueAtPointer(live.Object, path); ok {
+			ops = append(ops, jsonPatchOp{Op: "replace", Path: path, Value: original})
+		} else {
+			ops = append(ops, jsonPatchOp{Op: "remove", Path: path})
+		}
+	}
+	return json.Marshal(ops)
+}
+
+// leafValues extracts the set of JSON pointer paths and the values a patch assigns to them.
+func leafValues(patchType types.PatchType, data []byte) (map[string]interface{}, error) {
+	switch patchType {
+	case types.JSONPatchType:
+		return jsonPatchLeafValues(data)
+	default:
+		// Strategic merge and JSON merge patches are both just JSON documents that overlay the target
+		var m map[string]interface{}
+		if err := json.Unmarshal(data, &m); err != nil {
+			return nil, err
+		}
+		values := make(map[string]interface{})
+		flatten("", m, values)
+		return values, nil
+	}
+}
+
+// jsonPatchLeafValues extracts the paths/values set by the "add" and "replace" operations of an RFC 6902
+// JSON patch; "remove" and "test" operations do not assign a value and are ignored.
+func jsonPatchLeafValues(data []byte) (map[string]interface{}, error) {
+	var ops []struct {
+		Op    string      `json:"op"`
+		Path  string      `json:"path"`
+		Value interface{} `json:"value"`
+	}
+	if err := json.Unmarshal(data, &ops); err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]interface{}, len(ops))
+	for _, op := range ops {
+		if op.Op == "add" || op.Op == "replace" {
+			values[op.Path] = op.Value
+		}
+	}
+	return values, nil
+}
+
+// flatten walks a decoded merge patch document, recording a JSON pointer path for every leaf value; arrays
+// are recorded as a single leaf at their own path since a merge patch replaces them wholesale.
+func flatten(prefix string, v interface{}, out map[string]interface{}) {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		out[prefix] = v
+		return
+	}
+	for k, child := range m {
+		path := prefix + "/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(k)
+		flatten(path, child, out)
+	}
+}
+
+// valueAtPointer resolves a JSON pointer (as used by RFC 6902/6901) against a decoded object.
+func valueAtPointer(obj interface{}, pointer string) (interface{}, bool) {
+	cur := obj
+	for _, tok := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		tok = strings.NewReplacer("~1", "/", "~0", "~").Replace(tok)
+
+		switch v := cur.(type) {
+		case map[string]interface{}:
+			next, ok := v[tok]
+			if !ok {
+				return nil, false
+			}
+			cur = next
+		case []interface{}:
+			idx, err := strconv.Atoi(tok)
+			if err != nil || idx < 0 || idx >= len(v) {
+				return nil, false
+			}
+			cur = v[idx]
+		default:
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+// equalValues compares a patch value to an observed value, treating numbers and their string
+// representations as equal (the same flexibility Assignment.Value gets from intstr.IntOrString).
+func equalValues(expected, actual interface{}) bool {
+	return intstr.Parse(fmt.Sprintf("%v", expected)).String() == intstr.Parse(fmt.Sprintf("%v", actual)).String()
+}
+
+// cacheKey identifies a single patch target across reconciles of the same trial.
+type cacheKey struct {
+	gvk       schema.GroupVersionKind
+	namespace string
+	name      string
+	trialUID  types.UID
+}
+
+// cacheEntry is the diff last computed for a cacheKey, tagged with the resourceVersion it was computed
+// against so a later lookup can tell whether the target has changed since.
+type cacheEntry struct {
+	resourceVersion string
+	fields          []Field
+}
+
+// Cache remembers the last diff computed for each patch target so that a Detector being polled on an
+// interval does not need to recompute the diff for a target that has not changed since the last check. A
+// Cache is safe for concurrent use and has no eviction policy, so it should be scoped to the lifetime of a
+// single controller process, not persisted.
+type Cache struct {
+	mu      sync.Mutex
+	entries map[cacheKey]cacheEntry
+}
+
+// NewCache returns an empty Cache.
+func NewCache() *Cache {
+	return &Cache{entries: make(map[cacheKey]cacheEntry)}
+}
+
+func (c *Cache) get(key cacheKey, resourceVersion string) ([]Field, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok || e.resourceVersion != resourceVersion {
+		return nil, false
+	}
+	return e.fields, true
+}
+
+func (c *Cache) put(key cacheKey, resourceVersion string, fields []Field) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[cacheKey]cacheEntry)
+	}
+	c.entries[key] = cacheEntry{resourceVersion: resourceVersion, fields: fields}
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,194 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drift
+
+import (
+	"context"
+	"testing"
+
+	optimizev1beta2 "github.com/thestormforge/optimize-controller/v2/api/v1beta2"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestDetector_Check(t *testing.T) {
+	cases := []struct {
+		desc  string
+		patch optimizev1beta2.PatchOperation
+		obj   *appsv1.Deployment
+		drift bool
+	}{
+		{
+			desc: "strategic-merge-no-drift",
+			patch: optimizev1beta2.PatchOperation{
+				TargetRef: targetRef("deploy"),
+				PatchType: types.StrategicMergePatchType,
+				Data:      []byte(`{"spec":{"replicas":3}}`),
+			},
+			obj:   deployment("deploy", 3),
+			drift: false,
+		},
+		{
+			desc: "strategic-merge-drift",
+			patch: optimizev1beta2.PatchOperation{
+				TargetRef: targetRef("deploy"),
+				PatchType: types.StrategicMergePatchType,
+				Data:      []byte(`{"spec":{"replicas":3}}`),
+			},
+			obj:   deployment("deploy", 1),
+			drift: true,
+		},
+		{
+			desc: "json-patch-no-drift-string-vs-int",
+			patch: optimizev1beta2.PatchOperation{
+				TargetRef: targetRef("deploy"),
+				PatchType: types.JSONPatchType,
+				Data:      []byte(`[{"op":"replace","path":"/spec/replicas","value":"3"}]`),
+			},
+			obj:   deployment("deploy", 3),
+			drift: false,
+		},
+		{
+			desc: "json-merge-no-drift",
+			patch: optimizev1beta2.PatchOperation{
+				TargetRef: targetRef("deploy"),
+				PatchType: types.MergePatchType,
+				Data:      []byte(`{"spec":{"replicas":3}}`),
+			},
+			obj:   deployment("deploy", 3),
+			drift: false,
+		},
+		{
+			desc: "json-merge-drift",
+			patch: optimizev1beta2.PatchOperation{
+				TargetRef: targetRef("deploy"),
+				PatchType: types.MergePatchType,
+				Data:      []byte(`{"spec":{"replicas":3}}`),
+			},
+			obj:   deployment("deploy", 1),
+			drift: true,
+		},
+		{
+			desc: "unapplied-patch-ignored",
+			patch: optimizev1beta2.PatchOperation{
+				TargetRef:         targetRef("deploy"),
+				PatchType:         types.StrategicMergePatchType,
+				Data:              []byte(`{"spec":{"replicas":3}}`),
+				AttemptsRemaining: 1,
+			},
+			obj:   deployment("deploy", 1),
+			drift: false,
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			trial := &optimizev1beta2.Trial{
+				Status: optimizev1beta2.TrialStatus{
+					PatchOperations: []optimizev1beta2.PatchOperation{c.patch},
+				},
+			}
+
+			d := &Detector{Reader: fake.NewFakeClientWithScheme(scheme, c.obj)}
+			fields, err := d.Check(context.TODO(), trial)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got := len(fields) > 0; got != c.drift {
+				t.Errorf("expected drift=%v, got fields=%v", c.drift, fields)
+			}
+		})
+	}
+}
+
+// TestDetector_Check_Cache verifies that a Cache shared across reconciles is keyed on the target's
+// resourceVersion: an unchanged live object reuses the previously computed diff, but a live state that
+// mutates between reconciles (as would happen if something reverted the patch) is re-diffed and its drift
+// is still caught.
+func TestDetector_Check_Cache(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+
+	patch := optimizev1beta2.PatchOperation{
+		TargetRef: targetRef("deploy"),
+		PatchType: types.StrategicMergePatchType,
+		Data:      []byte(`{"spec":{"replicas":3}}`),
+	}
+	trial := &optimizev1beta2.Trial{
+		ObjectMeta: metav1.ObjectMeta{UID: types.UID("trial-uid")},
+		Status:     optimizev1beta2.TrialStatus{PatchOperations: []optimizev1beta2.PatchOperation{patch}},
+	}
+
+	cache := NewCache()
+
+	obj := deploymentWithVersion("deploy", 3, "1")
+	d := &Detector{Reader: fake.NewFakeClientWithScheme(scheme, obj), Cache: cache}
+	fields, err := d.Check(context.TODO(), trial)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(fields) != 0 {
+		t.Fatalf("expected no drift, got %v", fields)
+	}
+
+	// Something reverted the patch target behind our backs; a new resourceVersion means the cached
+	// (drift-free) result from the first check must not be reused
+	obj = deploymentWithVersion("deploy", 1, "2")
+	d = &Detector{Reader: fake.NewFakeClientWithScheme(scheme, obj), Cache: cache}
+	fields, err = d.Check(context.TODO(), trial)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(fields) == 0 {
+		t.Fatal("expected drift to be detected after the live object mutated, cache masked the change")
+	}
+}
+
+func targetRef(name string) corev1.ObjectReference {
+	return corev1.ObjectReference{
+		APIVersion: "apps/v1",
+		Kind:       "Deployment",
+		Namespace:  "default",
+		Name:       name,
+	}
+}
+
+func deployment(name string, replicas int32) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		TypeMeta: metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+		},
+		Spec: appsv1.DeploymentSpec{Replicas: &replicas},
+	}
+}
+
+func deploymentWithVersion(name string, replicas int32, resourceVersion string) *appsv1.Deployment {
+	d := deployment(name, replicas)
+	d.ResourceVersion = resourceVersion
+	return d
+}
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"time"
 
+	jsonpatch "github.com/evanphx/json-patch"
 	optimizev1beta2 "github.com/thestormforge/optimize-controller/v2/api/v1beta2"
 	"github.com/thestormforge/optimize-controller/v2/internal/meta"
 	"github.com/thestormforge/optimize-controller/v2/internal/setup"
@@ -31,8 +32,14 @@ import (
 	"k8s.io/apimachinery/pkg/util/strategicpatch"
 )
 
-// NewJob returns a new trial run job from the template on the trial
-func NewJob(t *optimizev1beta2.Trial) *batchv1.Job {
+// NewJob returns a new trial run job from the template on the trial. The namespace is optional and is only
+// used to resolve the Pod Security Admission profile to enforce when the trial does not specify one explicitly.
+func NewJob(t *optimizev1beta2.Trial, ns *corev1.Namespace) (*batchv1.Job, error) {
+	profile := setup.ResolvePodSecurityProfile(t.Spec.PodSecurityProfile, ns)
+	if err := setup.ValidateJobTemplate(t.Spec.JobTemplate, profile); err != nil {
+		return nil, err
+	}
+
 	job := &batchv1.Job{}
 
 	// Start with the job template
@@ -82,7 +89,11 @@ func NewJob(t *optimizev1beta2.Trial) *batchv1.Job {
 	// Check to see if there is patch for the (as of yet, non-existent) trial job
 	job = patchSelf(t, job)
 
-	return job
+	// Harden the job for the Pod Security Admission profile enforced on the target namespace (or
+	// explicitly requested on the trial), rejecting anything the API server would otherwise refuse
+	setup.ApplyPodSecurityProfile(job, profile)
+
+	return job, nil
 }
 
 func addDefaultContainer(t *optimizev1beta2.Trial, job *batchv1.Job) {
@@ -110,17 +121,40 @@ func patchSelf(t *optimizev1beta2.Trial, job *batchv1.Job) *batchv1.Job {
 	// Look for patch operations that match this trial and apply them
 	for i := range t.Status.PatchOperations {
 		po := &t.Status.PatchOperations[i]
-		if IsTrialJobReference(t, &po.TargetRef) && po.PatchType == types.StrategicMergePatchType {
-			// Ignore errors all the way down, only overwrite the job if everything is successful
-			if original, err := json.Marshal(job); err == nil {
-				j := &batchv1.Job{}
-				if patched, err := strategicpatch.StrategicMergePatch(original, po.Data, j); err == nil {
-					if err := json.Unmarshal(patched, j); err == nil {
-						return j
-					}
-				}
+		if !IsTrialJobReference(t, &po.TargetRef) {
+			continue
+		}
+
+		original, err := json.Marshal(job)
+		if err != nil {
+			ApplyCondition(&t.Status, optimizev1beta2.TrialPatched, corev1.ConditionFalse, "PatchFailed", err.Error(), nil)
+			continue
+		}
+
+		var patched []byte
+		switch po.PatchType {
+		case types.JSONPatchType:
+			var p jsonpatch.Patch
+			if p, err = jsonpatch.DecodePatch(po.Data); err == nil {
+				patched, err = p.Apply(original)
 			}
+		case types.MergePatchType:
+			patched, err = jsonpatch.MergePatch(original, po.Data)
+		default:
+			patched, err = strategicpatch.StrategicMergePatch(original, po.Data, &batchv1.Job{})
+		}
+		if err != nil {
+			ApplyCondition(&t.Status, optimizev1beta2.TrialPatched, corev1.ConditionFalse, "PatchFailed", err.Error(), nil)
+			continue
 		}
+
+		j := &batchv1.Job{}
+		if err := json.Unmarshal(patched, j); err != nil {
+			ApplyCondition(&t.Status, optimizev1beta2.TrialPatched, corev1.ConditionFalse, "PatchFailed", err.Error(), nil)
+			continue
+		}
+
+		return j
 	}
 	return job
 }
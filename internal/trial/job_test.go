@@ -26,6 +26,7 @@ import (
 	batchv1beta1 "k8s.io/api/batch/v1beta1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 func TestNewJob(t *testing.T) {
@@ -93,9 +94,61 @@ func TestNewJob(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(fmt.Sprintf("%q", tc.desc), func(t *testing.T) {
-			job := NewJob(tc.trial)
+			job, err := NewJob(tc.trial, nil)
+			assert.NoError(t, err)
 			assert.NotNil(t, job)
 			assert.Equal(t, len(job.Spec.Template.Spec.Containers), tc.expectedContainers)
 		})
 	}
 }
+
+func TestPatchSelf(t *testing.T) {
+	newTrial := func(patchType types.PatchType, data string) *optimizev1beta2.Trial {
+		return &optimizev1beta2.Trial{
+			ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "default"},
+			Spec: optimizev1beta2.TrialSpec{
+				JobTemplate: &batchv1beta1.JobTemplateSpec{
+					Spec: batchv1.JobSpec{
+						Template: corev1.PodTemplateSpec{
+							Spec: corev1.PodSpec{
+								Containers: []corev1.Container{{Name: "trial-run", Image: "busybox"}},
+							},
+						},
+					},
+				},
+			},
+			Status: optimizev1beta2.TrialStatus{
+				PatchOperations: []optimizev1beta2.PatchOperation{
+					{
+						TargetRef: corev1.ObjectReference{Kind: "Job", APIVersion: "batch/v1", Name: "default", Namespace: "default"},
+						PatchType: patchType,
+						Data:      []byte(data),
+					},
+				},
+			},
+		}
+	}
+
+	t.Run("json patch", func(t *testing.T) {
+		trial := newTrial(types.JSONPatchType, `[{"op":"replace","path":"/spec/template/spec/containers/0/image","value":"busybox:latest"}]`)
+		job, err := NewJob(trial, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "busybox:latest", job.Spec.Template.Spec.Containers[0].Image)
+	})
+
+	t.Run("merge patch", func(t *testing.T) {
+		trial := newTrial(types.MergePatchType, `{"spec":{"template":{"spec":{"containers":[{"name":"trial-run","image":"busybox:latest"}]}}}}`)
+		job, err := NewJob(trial, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "busybox:latest", job.Spec.Template.Spec.Containers[0].Image)
+	})
+
+	t.Run("invalid patch is reported instead of silently ignored", func(t *testing.T) {
+		trial := newTrial(types.JSONPatchType, `not json`)
+		job, err := NewJob(trial, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "busybox", job.Spec.Template.Spec.Containers[0].Image)
+
+		assert.True(t, CheckCondition(&trial.Status, optimizev1beta2.TrialPatched, corev1.ConditionFalse))
+	})
+}
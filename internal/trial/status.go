@@ -51,6 +51,7 @@ var (
 		optimizev1beta2.TrialSetupDeleted,
 		optimizev1beta2.TrialPatched,
 		optimizev1beta2.TrialReady,
+		optimizev1beta2.TrialAssignmentsApplied,
 		optimizev1beta2.TrialObserved,
 		optimizev1beta2.TrialComplete,
 		optimizev1beta2.TrialFailed,
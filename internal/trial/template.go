@@ -0,0 +1,198 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trial
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+
+	optimizev1beta2 "github.com/thestormforge/optimize-controller/v2/api/v1beta2"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ErrTemplateUnresolved is returned by RenderTemplates when a template function could not yet produce
+// a value (e.g. a LoadBalancer has not been assigned an external IP). Callers should requeue and try
+// again later rather than treat it as a hard failure.
+var ErrTemplateUnresolved = errors.New("trial job template value is not available yet")
+
+// RenderTemplates evaluates Go templates found in the trial job's container environment variable
+// values and arguments, resolving functions like `externalIP`, `clusterIP`, `serviceEndpoint`, and
+// `ingressURL` against the cluster using r, scoped to the job's namespace. This lets a trial job
+// reference runtime values that only exist after the job template was authored (e.g. the address a
+// Service's LoadBalancer was assigned) instead of the controller having to guess at them ahead of time.
+func RenderTemplates(ctx context.Context, r client.Reader, job *batchv1.Job) error {
+	tf := &templateFuncs{ctx: ctx, reader: r, namespace: job.Namespace}
+
+	for i := range job.Spec.Template.Spec.Containers {
+		c := &job.Spec.Template.Spec.Containers[i]
+
+		if err := tf.renderAll(c.Args); err != nil {
+			return err
+		}
+
+		if err := tf.renderEnv(c.Env); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// templateFuncs provides the trial-scoped functions exposed to job templates.
+type templateFuncs struct {
+	ctx       context.Context
+	reader    client.Reader
+	namespace string
+}
+
+func (tf *templateFuncs) funcMap() template.FuncMap {
+	return template.FuncMap{
+		"externalIP":      tf.externalIP,
+		"clusterIP":       tf.clusterIP,
+		"serviceEndpoint": tf.serviceEndpoint,
+		"ingressURL":      tf.ingressURL,
+	}
+}
+
+func (tf *templateFuncs) renderAll(values []string) error {
+	for i := range values {
+		v, err := tf.render(values[i])
+		if err != nil {
+			return err
+		}
+		values[i] = v
+	}
+
+	return nil
+}
+
+func (tf *templateFuncs) renderEnv(env []corev1.EnvVar) error {
+	for i := range env {
+		v, err := tf.render(env[i].Value)
+		if err != nil {
+			return err
+		}
+		env[i].Value = v
+	}
+
+	return nil
+}
+
+// render evaluates a single value, skipping the template engine entirely unless it looks like it
+// actually contains a template.
+func (tf *templateFuncs) render(s string) (string, error) {
+	if !strings.Contains(s, "{{") {
+		return s, nil
+	}
+
+	tmpl, err := template.New("trialJob").Funcs(tf.funcMap()).Parse(s)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, nil); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// externalIP returns the address the named Service's LoadBalancer was assigned.
+func (tf *templateFuncs) externalIP(name string) (string, error) {
+	svc := &corev1.Service{}
+	if err := tf.reader.Get(tf.ctx, client.ObjectKey{Namespace: tf.namespace, Name: name}, svc); err != nil {
+		return "", err
+	}
+
+	for _, ing := range svc.Status.LoadBalancer.Ingress {
+		if ing.IP != "" {
+			return ing.IP, nil
+		}
+		if ing.Hostname != "" {
+			return ing.Hostname, nil
+		}
+	}
+
+	return "", ErrTemplateUnresolved
+}
+
+// clusterIP returns the named Service's cluster IP.
+func (tf *templateFuncs) clusterIP(name string) (string, error) {
+	svc := &corev1.Service{}
+	if err := tf.reader.Get(tf.ctx, client.ObjectKey{Namespace: tf.namespace, Name: name}, svc); err != nil {
+		return "", err
+	}
+
+	if svc.Spec.ClusterIP == "" || svc.Spec.ClusterIP == corev1.ClusterIPNone {
+		return "", ErrTemplateUnresolved
+	}
+
+	return svc.Spec.ClusterIP, nil
+}
+
+// serviceEndpoint returns the "host:port" of the named port on the named Service's cluster IP.
+func (tf *templateFuncs) serviceEndpoint(name, portName string) (string, error) {
+	svc := &corev1.Service{}
+	if err := tf.reader.Get(tf.ctx, client.ObjectKey{Namespace: tf.namespace, Name: name}, svc); err != nil {
+		return "", err
+	}
+
+	if svc.Spec.ClusterIP == "" || svc.Spec.ClusterIP == corev1.ClusterIPNone {
+		return "", ErrTemplateUnresolved
+	}
+
+	for _, p := range svc.Spec.Ports {
+		if p.Name == portName {
+			return fmt.Sprintf("%s:%d", svc.Spec.ClusterIP, p.Port), nil
+		}
+	}
+
+	return "", fmt.Errorf("service %q has no port named %q", name, portName)
+}
+
+// ingressURL returns the URL of the named Ingress, derived from its LoadBalancer status and whether
+// it has TLS configured.
+func (tf *templateFuncs) ingressURL(name string) (string, error) {
+	ing := &networkingv1.Ingress{}
+	if err := tf.reader.Get(tf.ctx, client.ObjectKey{Namespace: tf.namespace, Name: name}, ing); err != nil {
+		return "", err
+	}
+
+	scheme := "http"
+	if len(ing.Spec.TLS) > 0 {
+		scheme = "https"
+	}
+
+	for _, lb := range ing.Status.LoadBalancer.Ingress {
+		if lb.IP != "" {
+			return fmt.Sprintf("%s://%s", scheme, lb.IP), nil
+		}
+		if lb.Hostname != "" {
+			return fmt.Sprintf("%s://%s", scheme, lb.Hostname), nil
+		}
+	}
+
+	return "", ErrTemplateUnresolved
+}
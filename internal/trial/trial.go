@@ -130,6 +130,19 @@ func NeedsCleanup(t *optimizev1beta2.Trial) bool {
 		return false
 	}
 
+	deadline, ok := NextCleanup(t)
+	if !ok {
+		return false
+	}
+
+	// Check to see if we are still in the TTL window
+	return deadline.Before(time.Now().UTC())
+}
+
+// NextCleanup returns the time at which a finished trial's TTL will expire and it becomes eligible for
+// cleanup. The second return value is false if the trial has not finished, or has no TTL configured, in
+// which case the time is meaningless.
+func NextCleanup(t *optimizev1beta2.Trial) (time.Time, bool) {
 	// Try to determine effective finish time and TTL
 	finishTime := metav1.Time{}
 	ttlSeconds := t.Spec.TTLSecondsAfterFinished
@@ -147,14 +160,13 @@ func NeedsCleanup(t *optimizev1beta2.Trial) bool {
 		}
 	}
 
-	// No finish time or TTL, no cleanup necessary
+	// No finish time or TTL, no deadline to report
 	if finishTime.IsZero() || ttlSeconds == nil || *ttlSeconds < 0 {
-		return false
+		return time.Time{}, false
 	}
 
-	// Check to see if we are still in the TTL window
 	ttl := time.Duration(*ttlSeconds) * time.Second
-	return finishTime.UTC().Add(ttl).Before(time.Now().UTC())
+	return finishTime.UTC().Add(ttl), true
 }
 
 // isFinishTimeCondition returns true if the condition is relevant to the "finish time"
@@ -0,0 +1,157 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package ttl schedules a reconcile request to fire exactly when a trial's cleanup TTL expires, instead of
+// relying on some unrelated reconcile happening to occur near the deadline. A Scheduler keeps its pending
+// deadlines in a min-heap and sleeps a single goroutine until the earliest one elapses, at which point it
+// emits an event that can be fed into a controller as a source.Channel.
+package ttl
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+// Scheduler tracks a single pending cleanup deadline per key and wakes a reconcile for it once the deadline
+// elapses.
+type Scheduler struct {
+	events chan event.GenericEvent
+
+	mu      sync.Mutex
+	entries map[types.NamespacedName]*entry
+	pending entryHeap
+	timer   *time.Timer
+}
+
+// entry is a single scheduled deadline within the heap.
+type entry struct {
+	key      types.NamespacedName
+	deadline time.Time
+	index    int
+}
+
+// NewScheduler creates a scheduler with no pending deadlines.
+func NewScheduler() *Scheduler {
+	return &Scheduler{
+		events:  make(chan event.GenericEvent),
+		entries: make(map[types.NamespacedName]*entry),
+	}
+}
+
+// Events returns the channel of generic events the scheduler publishes to as deadlines elapse; it is
+// intended to be used directly as the Source of a controller-runtime source.Channel.
+func (s *Scheduler) Events() <-chan event.GenericEvent {
+	return s.events
+}
+
+// Schedule arms (or re-arms) the cleanup deadline for key, replacing any previously scheduled deadline.
+func (s *Scheduler) Schedule(key types.NamespacedName, deadline time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e, ok := s.entries[key]; ok {
+		e.deadline = deadline
+		heap.Fix(&s.pending, e.index)
+	} else {
+		e := &entry{key: key, deadline: deadline}
+		s.entries[key] = e
+		heap.Push(&s.pending, e)
+	}
+
+	s.rearm()
+}
+
+// Cancel removes any pending cleanup deadline for key.
+func (s *Scheduler) Cancel(key types.NamespacedName) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok {
+		return
+	}
+	delete(s.entries, key)
+	heap.Remove(&s.pending, e.index)
+
+	s.rearm()
+}
+
+// rearm resets the wakeup timer to fire when the earliest pending deadline elapses; it must be called with
+// s.mu held.
+func (s *Scheduler) rearm() {
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	if len(s.pending) == 0 {
+		return
+	}
+
+	delay := time.Until(s.pending[0].deadline)
+	if delay < 0 {
+		delay = 0
+	}
+	s.timer = time.AfterFunc(delay, s.fire)
+}
+
+// fire publishes an event for every entry whose deadline has elapsed and rearms for whatever remains.
+func (s *Scheduler) fire() {
+	s.mu.Lock()
+	var due []types.NamespacedName
+	now := time.Now()
+	for len(s.pending) > 0 && !s.pending[0].deadline.After(now) {
+		e := heap.Pop(&s.pending).(*entry)
+		delete(s.entries, e.key)
+		due = append(due, e.key)
+	}
+	s.rearm()
+	s.mu.Unlock()
+
+	for _, key := range due {
+		s.events <- event.GenericEvent{Meta: &metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name}}
+	}
+}
+
+// entryHeap is a min-heap of entries ordered by deadline.
+type entryHeap []*entry
+
+func (h entryHeap) Len() int           { return len(h) }
+func (h entryHeap) Less(i, j int) bool { return h[i].deadline.Before(h[j].deadline) }
+func (h entryHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index, h[j].index = i, j
+}
+
+func (h *entryHeap) Push(x interface{}) {
+	e := x.(*entry)
+	e.index = len(*h)
+	*h = append(*h, e)
+}
+
+func (h *entryHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	e := old[n-1]
+	old[n-1] = nil
+	e.index = -1
+	*h = old[:n-1]
+	return e
+}
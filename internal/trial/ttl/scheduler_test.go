@@ -0,0 +1,86 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ttl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestScheduler_Schedule(t *testing.T) {
+	s := NewScheduler()
+	key := types.NamespacedName{Namespace: "default", Name: "exp-1"}
+
+	s.Schedule(key, time.Now().Add(10*time.Millisecond))
+
+	select {
+	case evt := <-s.Events():
+		assert.Equal(t, key.Namespace, evt.Meta.GetNamespace())
+		assert.Equal(t, key.Name, evt.Meta.GetName())
+	case <-time.After(time.Second):
+		t.Fatal("expected a scheduled event")
+	}
+}
+
+func TestScheduler_Cancel(t *testing.T) {
+	s := NewScheduler()
+	key := types.NamespacedName{Namespace: "default", Name: "exp-1"}
+
+	s.Schedule(key, time.Now().Add(10*time.Millisecond))
+	s.Cancel(key)
+
+	select {
+	case evt := <-s.Events():
+		t.Fatalf("did not expect an event, got %v", evt)
+	case <-time.After(50 * time.Millisecond):
+		// No event fired, as expected
+	}
+}
+
+func TestScheduler_Reschedule(t *testing.T) {
+	s := NewScheduler()
+	key := types.NamespacedName{Namespace: "default", Name: "exp-1"}
+
+	// Arm a far-off deadline, then immediately pull it in; only the latest deadline should apply
+	s.Schedule(key, time.Now().Add(time.Hour))
+	s.Schedule(key, time.Now().Add(10*time.Millisecond))
+
+	select {
+	case <-s.Events():
+	case <-time.After(time.Second):
+		t.Fatal("expected the rescheduled event to fire promptly")
+	}
+}
+
+func TestScheduler_EarliestFiresFirst(t *testing.T) {
+	s := NewScheduler()
+	later := types.NamespacedName{Namespace: "default", Name: "later"}
+	sooner := types.NamespacedName{Namespace: "default", Name: "sooner"}
+
+	s.Schedule(later, time.Now().Add(200*time.Millisecond))
+	s.Schedule(sooner, time.Now().Add(10*time.Millisecond))
+
+	select {
+	case evt := <-s.Events():
+		assert.Equal(t, sooner.Name, evt.Meta.GetName())
+	case <-time.After(time.Second):
+		t.Fatal("expected the sooner deadline to fire first")
+	}
+}
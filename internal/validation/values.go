@@ -24,27 +24,48 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 )
 
-// CheckMetricBounds ensures the specified
-func CheckMetricBounds(m *optimizev1beta2.Metric, v *optimizev1beta2.Value) error {
+// CheckMetricBounds ensures the value observed for a metric falls within its configured
+// Min/Max, converting the observed value into the metric's canonical Unit first. If
+// scenario is non-empty and the metric has a matching entry in BoundsOverrides, those
+// bounds are enforced instead of the metric's own Min/Max.
+func CheckMetricBounds(m *optimizev1beta2.Metric, scenario string, v *optimizev1beta2.Value) error {
 	// If the value isn't a valid number, ignore the bounds check
 	value, err := strconv.ParseFloat(v.Value, 64)
 	if err != nil {
 		return nil
 	}
 
-	if m.Min != nil {
-		min := float64(m.Min.ScaledValue(resource.Nano)) / 1000000000
-		if value < min {
-			return fmt.Errorf("metric value %f for %s is below the minimum of %s", value, m.Name, m.Min.String())
+	// Ratio metrics are bounded as a fraction, but the metric source may report them as a
+	// percentage (e.g. 42.5 instead of 0.425); anything outside [-1, 1] is assumed to be one.
+	if m.Unit == optimizev1beta2.UnitRatio && (value > 1 || value < -1) {
+		value /= 100
+	}
+
+	min, max := m.Min, m.Max
+	for _, o := range m.BoundsOverrides {
+		if o.Scenario == scenario {
+			min, max = o.Min, o.Max
+			break
 		}
 	}
 
-	if m.Max != nil {
-		max := float64(m.Max.ScaledValue(resource.Nano)) / 1000000000
-		if value > max {
-			return fmt.Errorf("metric value %f for %s is above the maximum of %s", value, m.Name, m.Max.String())
+	if min != nil {
+		if b := quantityToFloat(min); value < b {
+			return fmt.Errorf("metric value %f for %s is below the minimum of %s", value, m.Name, min.String())
+		}
+	}
+
+	if max != nil {
+		if b := quantityToFloat(max); value > b {
+			return fmt.Errorf("metric value %f for %s is above the maximum of %s", value, m.Name, max.String())
 		}
 	}
 
 	return nil
 }
+
+// quantityToFloat converts a resource.Quantity into a decimal float, honoring any suffix
+// (e.g. "500m" becomes 0.5) the way CheckMetricBounds has always scaled Min/Max.
+func quantityToFloat(q *resource.Quantity) float64 {
+	return float64(q.ScaledValue(resource.Nano)) / 1000000000
+}
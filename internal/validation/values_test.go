@@ -33,6 +33,7 @@ func TestCheckMetricBounds(t *testing.T) {
 	cases := []struct {
 		desc     string
 		metric   optimizev1beta2.Metric
+		scenario string
 		value    optimizev1beta2.Value
 		hasError bool
 	}{
@@ -80,10 +81,48 @@ func TestCheckMetricBounds(t *testing.T) {
 			metric: optimizev1beta2.Metric{Min: mustQuantity("100m")},
 			value:  optimizev1beta2.Value{Value: "0.2"},
 		},
+
+		{
+			desc:     "ratio value reported as a fraction",
+			metric:   optimizev1beta2.Metric{Unit: optimizev1beta2.UnitRatio, Max: mustQuantity("0.5")},
+			value:    optimizev1beta2.Value{Value: "0.4"},
+			hasError: false,
+		},
+		{
+			desc:     "ratio value reported as a percentage",
+			metric:   optimizev1beta2.Metric{Unit: optimizev1beta2.UnitRatio, Max: mustQuantity("0.5")},
+			value:    optimizev1beta2.Value{Value: "60"},
+			hasError: true,
+		},
+
+		{
+			desc: "scenario override applies",
+			metric: optimizev1beta2.Metric{
+				Max: mustQuantity("1.0"),
+				BoundsOverrides: []optimizev1beta2.MetricBoundsOverride{
+					{Scenario: "spike", Max: mustQuantity("5.0")},
+				},
+			},
+			scenario: "spike",
+			value:    optimizev1beta2.Value{Value: "2.0"},
+			hasError: false,
+		},
+		{
+			desc: "scenario override does not apply to other scenarios",
+			metric: optimizev1beta2.Metric{
+				Max: mustQuantity("1.0"),
+				BoundsOverrides: []optimizev1beta2.MetricBoundsOverride{
+					{Scenario: "spike", Max: mustQuantity("5.0")},
+				},
+			},
+			scenario: "steady",
+			value:    optimizev1beta2.Value{Value: "2.0"},
+			hasError: true,
+		},
 	}
 	for _, c := range cases {
 		t.Run(c.desc, func(t *testing.T) {
-			err := CheckMetricBounds(&c.metric, &c.value)
+			err := CheckMetricBounds(&c.metric, c.scenario, &c.value)
 			if c.hasError {
 				assert.Error(t, err)
 			} else {
@@ -0,0 +1,36 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package conversion registers the CRD conversion webhook route ("/convert") that replaces the client side
+// negotiated serializer hack previously installed by internal/controller.WithConversion. It relies entirely
+// on the existing ConvertTo/ConvertFrom methods on api/v1alpha1.Experiment (and the Hub marker on
+// api/v1beta1.Experiment) -- controller-runtime's generic conversion handler does the rest, so there is
+// nothing here specific to the redskyops.dev/stormforge.io group beyond registering the route.
+package conversion
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/conversion"
+)
+
+// Path is the route the CRD's conversion webhook client config must point at.
+const Path = "/convert"
+
+// AddToManager registers the conversion webhook with mgr's webhook server.
+func AddToManager(mgr manager.Manager) error {
+	mgr.GetWebhookServer().Register(Path, &conversion.Webhook{})
+	return nil
+}
@@ -21,7 +21,11 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io/ioutil"
+	"net/http"
 	"os"
+	"sync/atomic"
+	"time"
 
 	optimizev1beta2 "github.com/thestormforge/optimize-controller/v2/api/v1beta2"
 	"github.com/thestormforge/optimize-controller/v2/controllers"
@@ -30,8 +34,13 @@ import (
 	"github.com/thestormforge/optimize-go/pkg/config"
 	zap2 "go.uber.org/zap"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/client-go/kubernetes"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 )
@@ -53,10 +62,30 @@ func main() {
 	handleDebugArgs()
 
 	var metricsAddr string
-	var enableLeaderElection bool
+	var healthAddr string
+	var leaderElect bool
+	var leaderElectLeaseDuration time.Duration
+	var leaderElectRenewDeadline time.Duration
+	var leaderElectRetryPeriod time.Duration
+	var leaderElectResourceLock string
+	var leaderElectResourceName string
+	var leaderElectResourceNamespace string
 	flag.StringVar(&metricsAddr, "metrics-addr", ":8080", "The address the metric endpoint binds to.")
-	flag.BoolVar(&enableLeaderElection, "enable-leader-election", false,
+	flag.StringVar(&healthAddr, "health-addr", ":8081", "The address the health probe endpoint binds to.")
+	flag.BoolVar(&leaderElect, "leader-elect", false,
 		"Enable leader election for controller manager. Enabling this will ensure there is only one active controller manager.")
+	flag.DurationVar(&leaderElectLeaseDuration, "leader-elect-lease-duration", 15*time.Second,
+		"The duration that non-leader candidates will wait to force acquire leadership.")
+	flag.DurationVar(&leaderElectRenewDeadline, "leader-elect-renew-deadline", 10*time.Second,
+		"The duration that the acting leader will retry refreshing leadership before giving up.")
+	flag.DurationVar(&leaderElectRetryPeriod, "leader-elect-retry-period", 2*time.Second,
+		"The duration the LeaderElector clients should wait between tries of actions.")
+	flag.StringVar(&leaderElectResourceLock, "leader-elect-resource-lock", resourcelock.LeasesResourceLock,
+		"The resource lock to use for leader election: \"leases\", \"configmaps\", or \"endpoints\".")
+	flag.StringVar(&leaderElectResourceName, "leader-elect-resource-name", "optimize-controller-leader-election",
+		"The name of the resource that leader election will use for holding the leader lock.")
+	flag.StringVar(&leaderElectResourceNamespace, "leader-elect-resource-namespace", "",
+		"The namespace in which the leader election resource will be created. Defaults to the controller's own namespace.")
 	flag.Parse()
 
 	ctrl.SetLogger(zap.New(func(o *zap.Options) {
@@ -70,16 +99,40 @@ func main() {
 	v := version.GetInfo()
 	setupLog.Info("StormForge Optimize Controller", "version", v.String(), "gitCommit", v.GitCommit)
 
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
-		Scheme:             scheme,
-		MetricsBindAddress: metricsAddr,
-		LeaderElection:     enableLeaderElection,
+	cfg := ctrl.GetConfigOrDie()
+
+	// Leader election is run independently of the manager (rather than through the built-in
+	// ctrl.Options.LeaderElection) so a coordination.k8s.io Lease can be used as the resource lock;
+	// the controller-runtime version we're pinned to only ever creates ConfigMap locks internally
+	var le *leaderElector
+	if leaderElect {
+		var err error
+		le, err = newLeaderElector(cfg, leaderElectResourceLock, leaderElectResourceName, leaderElectResourceNamespace,
+			leaderElectLeaseDuration, leaderElectRenewDeadline, leaderElectRetryPeriod)
+		if err != nil {
+			setupLog.Error(err, "unable to configure leader election")
+			os.Exit(1)
+		}
+	}
+
+	mgr, err := ctrl.NewManager(cfg, ctrl.Options{
+		Scheme:                 scheme,
+		MetricsBindAddress:     metricsAddr,
+		HealthProbeBindAddress: healthAddr,
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
 
+	// Surface the current leader identity so operators can observe failover
+	if le != nil {
+		if err = mgr.AddHealthzCheck("leader-election", le.healthzCheck); err != nil {
+			setupLog.Error(err, "unable to set up leader election health check")
+			os.Exit(1)
+		}
+	}
+
 	if err = (&controllers.ExperimentReconciler{
 		Client: mgr.GetClient(),
 		Log:    ctrl.Log.WithName("controllers").WithName("Experiment"),
@@ -96,9 +149,10 @@ func main() {
 		os.Exit(1)
 	}
 	if err = (&controllers.SetupReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("Setup"),
-		Scheme: mgr.GetScheme(),
+		Client:     mgr.GetClient(),
+		Log:        ctrl.Log.WithName("controllers").WithName("Setup"),
+		Scheme:     mgr.GetScheme(),
+		RESTConfig: mgr.GetConfig(),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Setup")
 		os.Exit(1)
@@ -135,6 +189,14 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "Metric")
 		os.Exit(1)
 	}
+	if err = (&controllers.DriftReconciler{
+		Client: mgr.GetClient(),
+		Log:    ctrl.Log.WithName("controllers").WithName("Drift"),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Drift")
+		os.Exit(1)
+	}
 	// +kubebuilder:scaffold:builder
 
 	runner := experiment.New(mgr.GetClient(), ctrl.Log.WithName("generation").WithName("experiment"))
@@ -142,13 +204,156 @@ func main() {
 	ctx := context.Background()
 	go runner.Run(ctx)
 
-	setupLog.Info("starting manager")
-	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+	runManager := func(ctx context.Context) error {
+		setupLog.Info("starting manager")
+		return mgr.Start(ctx.Done())
+	}
+
+	stopCh := ctrl.SetupSignalHandler()
+	runCtx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-stopCh
+		cancel()
+	}()
+
+	if leaderElect {
+		if err := le.Run(runCtx, runManager); err != nil {
+			setupLog.Error(err, "problem running manager")
+			os.Exit(1)
+		}
+		return
+	}
+
+	if err := runManager(runCtx); err != nil {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
 }
 
+// +kubebuilder:rbac:groups=coordination.k8s.io,resources=leases,verbs=get;list;watch;create;update;patch
+
+// leaderElector runs client-go leader election directly (rather than through ctrl.Options.LeaderElection)
+// so a coordination.k8s.io Lease can be used as the resource lock; the pinned controller-runtime version
+// only ever constructs ConfigMap locks internally.
+type leaderElector struct {
+	lock     resourcelock.Interface
+	identity string
+
+	leaseDuration time.Duration
+	renewDeadline time.Duration
+	retryPeriod   time.Duration
+
+	leading atomic.Value // bool
+}
+
+// newLeaderElector builds a leaderElector using the requested resource lock ("leases", "configmaps", or
+// "endpoints"), defaulting the resource namespace to the namespace the process is running in.
+func newLeaderElector(cfg *rest.Config, resourceLock, resourceName, resourceNamespace string, leaseDuration, renewDeadline, retryPeriod time.Duration) (*leaderElector, error) {
+	if resourceNamespace == "" {
+		ns, err := inClusterNamespace()
+		if err != nil {
+			return nil, fmt.Errorf("unable to determine leader election namespace, please specify --leader-elect-resource-namespace: %w", err)
+		}
+		resourceNamespace = ns
+	}
+
+	id, err := os.Hostname()
+	if err != nil {
+		return nil, err
+	}
+	id = id + "_" + string(uuid.NewUUID())
+
+	client, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	lock, err := resourcelock.New(resourceLock, resourceNamespace, resourceName, client.CoreV1(), client.CoordinationV1(), resourcelock.ResourceLockConfig{
+		Identity: id,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	le := &leaderElector{
+		lock:          lock,
+		identity:      id,
+		leaseDuration: leaseDuration,
+		renewDeadline: renewDeadline,
+		retryPeriod:   retryPeriod,
+	}
+	le.leading.Store(false)
+	return le, nil
+}
+
+// Run blocks running leader election, invoking runManager once this process becomes the leader; the
+// manager is stopped (by cancelling the context passed to it) if leadership is lost or ctx is cancelled.
+func (le *leaderElector) Run(ctx context.Context, runManager func(context.Context) error) error {
+	managerErr := make(chan error, 1)
+
+	elector, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock:          le.lock,
+		LeaseDuration: le.leaseDuration,
+		RenewDeadline: le.renewDeadline,
+		RetryPeriod:   le.retryPeriod,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(leaderCtx context.Context) {
+				le.leading.Store(true)
+				setupLog.Info("acquired leadership", "identity", le.identity)
+				managerErr <- runManager(leaderCtx)
+			},
+			OnStoppedLeading: func() {
+				le.leading.Store(false)
+				setupLog.Info("lost leadership", "identity", le.identity)
+			},
+			OnNewLeader: func(identity string) {
+				if identity != le.identity {
+					setupLog.Info("observed new leader", "leader", identity)
+				}
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Run only returns once the renew loop exits, and it launches OnStartedLeading (in its own
+	// goroutine, never awaited) strictly before that: so IsLeader, read here, is never racing a
+	// not-yet-scheduled OnStartedLeading goroutine the way checking a channel closed by
+	// OnStartedLeading itself would be. That lets us tell reliably whether runManager was ever
+	// started, even if ctx was cancelled in the same instant leadership was acquired.
+	elector.Run(ctx)
+
+	if elector.IsLeader() {
+		// This process was (or still is) the leader: wait for runManager to actually finish tearing
+		// down before returning, rather than racing it.
+		return <-managerErr
+	}
+	// Leadership was never acquired (e.g. ctx was cancelled while still a candidate), so
+	// OnStartedLeading never ran and managerErr will never receive a value.
+	return nil
+}
+
+// healthzCheck reports this replica's leader election status; the manager (and therefore its healthz
+// server) only runs while this replica is leading, so a reachable /healthz response combined with the
+// "acquired leadership"/"lost leadership" log lines above is how operators observe failover.
+func (le *leaderElector) healthzCheck(_ *http.Request) error {
+	if leading, _ := le.leading.Load().(bool); !leading {
+		return fmt.Errorf("not currently the leader (identity %s)", le.identity)
+	}
+	return nil
+}
+
+// inClusterNamespace returns the namespace the process is running in, as reported by the service account
+// volume mounted into the pod.
+func inClusterNamespace() (string, error) {
+	data, err := ioutil.ReadFile("/var/run/secrets/kubernetes.io/serviceaccount/namespace")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
 // handleDebugArgs will make the process dump and exit if the first arg is either "version" or "config"
 func handleDebugArgs() {
 	if len(os.Args) > 1 {
@@ -16,6 +16,8 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"strconv"
+
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -33,16 +35,66 @@ const (
 	LabelExperiment = "redskyops.dev/experiment"
 )
 
+// ParameterType represents the allowable types of a parameter's search space
+type ParameterType string
+
+const (
+	// ParameterTypeInt is a parameter whose feasible space is an integer range
+	ParameterTypeInt ParameterType = "int"
+	// ParameterTypeDouble is a parameter whose feasible space is a floating point range
+	ParameterTypeDouble ParameterType = "double"
+	// ParameterTypeDiscrete is a parameter whose feasible space is an enumerated list of numeric values
+	ParameterTypeDiscrete ParameterType = "discrete"
+	// ParameterTypeCategorical is a parameter whose feasible space is an enumerated list of string labels
+	ParameterTypeCategorical ParameterType = "categorical"
+)
+
+// FeasibleSpace describes the domain of a parameter. Min, Max, and Step are carried as strings so the
+// space can express both integer and floating point bounds without a second set of fields; List is only
+// populated for the discrete and categorical parameter types.
+type FeasibleSpace struct {
+	// The inclusive minimum value of the parameter, used for int and double parameters
+	Min string `json:"min,omitempty"`
+	// The inclusive maximum value of the parameter, used for int and double parameters
+	Max string `json:"max,omitempty"`
+	// The step size between feasible values, used for int and double parameters
+	Step string `json:"step,omitempty"`
+	// The enumerated feasible values, used for discrete and categorical parameters
+	List []string `json:"list,omitempty"`
+}
+
 // Parameter represents the domain of a single component of the experiment search space
 type Parameter struct {
 	// The name of the parameter
 	Name string `json:"name"`
+	// The type of the parameter, defaults to "int"
+	Type ParameterType `json:"type,omitempty"`
+	// The feasible space for the parameter
+	FeasibleSpace FeasibleSpace `json:"feasibleSpace,omitempty"`
+
 	// The inclusive minimum value of the parameter
+	//
+	// Deprecated: use FeasibleSpace.Min instead
 	Min int64 `json:"min,omitempty"`
 	// The inclusive maximum value of the parameter
+	//
+	// Deprecated: use FeasibleSpace.Max instead
 	Max int64 `json:"max,omitempty"`
 }
 
+// UpgradeFeasibleSpace backfills FeasibleSpace (and Type, if unset) from the deprecated Min/Max fields so
+// older experiment manifests that only set Min/Max keep working.
+func (p *Parameter) UpgradeFeasibleSpace() {
+	if p.FeasibleSpace.Min == "" && p.FeasibleSpace.Max == "" && p.FeasibleSpace.List == nil && (p.Min != 0 || p.Max != 0) {
+		p.FeasibleSpace.Min = strconv.FormatInt(p.Min, 10)
+		p.FeasibleSpace.Max = strconv.FormatInt(p.Max, 10)
+	}
+
+	if p.Type == "" {
+		p.Type = ParameterTypeInt
+	}
+}
+
 // PatchType represents the allowable types of patches
 type PatchType string
 
@@ -0,0 +1,46 @@
+/*
+Copyright 2019 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1alpha1
+
+import "fmt"
+
+// ValidateParameters checks that each parameter's feasible space is consistent with its type, upgrading
+// deprecated Min/Max only parameters before the check is performed. This is exposed as a plain function
+// (rather than a controller-runtime admission.Validator) because this package predates the project's
+// current webhook plumbing; a validating webhook would call this from its Handle method.
+func (in *ExperimentSpec) ValidateParameters() error {
+	for i := range in.Parameters {
+		p := &in.Parameters[i]
+		p.UpgradeFeasibleSpace()
+
+		switch p.Type {
+		case ParameterTypeDiscrete, ParameterTypeCategorical:
+			if len(p.FeasibleSpace.List) == 0 {
+				return fmt.Errorf("parameter %q is %s and requires a non-empty list", p.Name, p.Type)
+			}
+
+		case ParameterTypeInt, ParameterTypeDouble:
+			if p.FeasibleSpace.Min == "" || p.FeasibleSpace.Max == "" {
+				return fmt.Errorf("parameter %q is %s and requires both min and max", p.Name, p.Type)
+			}
+
+		default:
+			return fmt.Errorf("parameter %q has unknown type %q", p.Name, p.Type)
+		}
+	}
+
+	return nil
+}
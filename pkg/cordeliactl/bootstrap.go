@@ -15,9 +15,17 @@ import (
 	"sigs.k8s.io/yaml"
 )
 
+const (
+	// RBACProfileStrict grants only the permissions the bootstrap job actually needs to install the product
+	RBACProfileStrict = "strict"
+	// RBACProfilePermissive grants the previous wildcard rules, for clusters where the strict profile is missing something
+	RBACProfilePermissive = "permissive"
+)
+
 // The bootstrap configuration
 type bootstrapConfig struct {
 	Namespace          corev1.Namespace
+	ServiceAccount     corev1.ServiceAccount
 	ClusterRole        rbacv1.ClusterRole
 	ClusterRoleBinding rbacv1.ClusterRoleBinding
 	Role               rbacv1.Role
@@ -35,6 +43,7 @@ func (b *bootstrapConfig) Marshal(w io.Writer) error {
 	var objs []runtime.Object
 	objs = append(objs,
 		&b.Namespace,
+		&b.ServiceAccount,
 		&b.ClusterRole,
 		&b.ClusterRoleBinding,
 		&b.Role,
@@ -65,13 +74,57 @@ func (b *bootstrapConfig) Marshal(w io.Writer) error {
 	return nil
 }
 
-func newBootstrapConfig(namespace, name string, cfg *api.Config) (*bootstrapConfig, error) {
+// bootstrapRules returns the PolicyRules granted to the bootstrap service account for the given RBAC
+// profile. The strict profile only covers the resources the install job actually creates or updates;
+// the permissive profile preserves the original wildcard behavior for clusters where strict turns out
+// to be missing something.
+func bootstrapRules(rbacProfile string) []rbacv1.PolicyRule {
+	if rbacProfile == RBACProfilePermissive {
+		return []rbacv1.PolicyRule{
+			{
+				Verbs:     []string{rbacv1.VerbAll},
+				APIGroups: []string{rbacv1.APIGroupAll},
+				Resources: []string{rbacv1.ResourceAll},
+			},
+		}
+	}
+
+	return []rbacv1.PolicyRule{
+		{
+			Verbs:     []string{rbacv1.VerbAll},
+			APIGroups: []string{""},
+			Resources: []string{"namespaces", "serviceaccounts", "services", "secrets", "configmaps"},
+		},
+		{
+			Verbs:     []string{rbacv1.VerbAll},
+			APIGroups: []string{"apps"},
+			Resources: []string{"deployments"},
+		},
+		{
+			Verbs:     []string{rbacv1.VerbAll},
+			APIGroups: []string{"rbac.authorization.k8s.io"},
+			Resources: []string{"roles", "rolebindings", "clusterroles", "clusterrolebindings"},
+		},
+		{
+			Verbs:     []string{rbacv1.VerbAll},
+			APIGroups: []string{"apiextensions.k8s.io"},
+			Resources: []string{"customresourcedefinitions"},
+		},
+		{
+			Verbs:     []string{rbacv1.VerbAll},
+			APIGroups: []string{"redskyops.dev"},
+			Resources: []string{rbacv1.ResourceAll},
+		},
+	}
+}
+
+func newBootstrapConfig(namespace, name string, cfg *api.Config, rbacProfile string) (*bootstrapConfig, error) {
 	clientConfig, err := yaml.Marshal(cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	// Note that we cannot scope "create" to a particular resource name
+	rules := bootstrapRules(rbacProfile)
 
 	b := &bootstrapConfig{
 		// This is the namespace ultimately used by the product
@@ -79,16 +132,16 @@ func newBootstrapConfig(namespace, name string, cfg *api.Config) (*bootstrapConf
 			ObjectMeta: metav1.ObjectMeta{Name: namespace},
 		},
 
-		// Bootstrap cluster role bound to the default service account of the namespace
+		// Dedicated service account for the bootstrap job, so uninstall can cleanly remove the role
+		// bindings without stripping permissions from the namespace's default service account
+		ServiceAccount: corev1.ServiceAccount{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+		},
+
+		// Bootstrap cluster role bound to the dedicated bootstrap service account
 		ClusterRole: rbacv1.ClusterRole{
 			ObjectMeta: metav1.ObjectMeta{Name: name},
-			Rules: []rbacv1.PolicyRule{
-				{
-					Verbs:     []string{rbacv1.VerbAll},
-					APIGroups: []string{rbacv1.APIGroupAll},
-					Resources: []string{rbacv1.ResourceAll},
-				},
-			},
+			Rules:      rules,
 		},
 		ClusterRoleBinding: rbacv1.ClusterRoleBinding{
 			ObjectMeta: metav1.ObjectMeta{Name: name},
@@ -96,7 +149,7 @@ func newBootstrapConfig(namespace, name string, cfg *api.Config) (*bootstrapConf
 				{
 					Kind:      rbacv1.ServiceAccountKind,
 					Namespace: namespace,
-					Name:      "default",
+					Name:      name,
 				},
 			},
 			RoleRef: rbacv1.RoleRef{
@@ -106,16 +159,10 @@ func newBootstrapConfig(namespace, name string, cfg *api.Config) (*bootstrapConf
 			},
 		},
 
-		// Bootstrap role bound to the default service account of the namespace
+		// Bootstrap role bound to the dedicated bootstrap service account
 		Role: rbacv1.Role{
 			ObjectMeta: metav1.ObjectMeta{Name: name},
-			Rules: []rbacv1.PolicyRule{
-				{
-					Verbs:     []string{rbacv1.VerbAll},
-					APIGroups: []string{rbacv1.APIGroupAll},
-					Resources: []string{rbacv1.ResourceAll},
-				},
-			},
+			Rules:      rules,
 		},
 		RoleBinding: rbacv1.RoleBinding{
 			ObjectMeta: metav1.ObjectMeta{Name: name},
@@ -123,7 +170,7 @@ func newBootstrapConfig(namespace, name string, cfg *api.Config) (*bootstrapConf
 				{
 					Kind:      rbacv1.ServiceAccountKind,
 					Namespace: namespace,
-					Name:      "default",
+					Name:      name,
 				},
 			},
 			RoleRef: rbacv1.RoleRef{
@@ -150,7 +197,8 @@ func newBootstrapConfig(namespace, name string, cfg *api.Config) (*bootstrapConf
 				TTLSecondsAfterFinished: new(int32),
 				Template: corev1.PodTemplateSpec{
 					Spec: corev1.PodSpec{
-						RestartPolicy: corev1.RestartPolicyNever,
+						RestartPolicy:      corev1.RestartPolicyNever,
+						ServiceAccountName: name,
 						Containers: []corev1.Container{
 							{
 								Name:            "setuptools-install",
@@ -20,6 +20,7 @@ type initOptions struct {
 	kubeconfig       string
 	installNamespace string
 	installName      string
+	rbac             string
 	bootstrap        bool
 	dryRun           bool
 	uninstall        bool
@@ -29,6 +30,7 @@ func newInitOptions() *initOptions {
 	return &initOptions{
 		installNamespace: "cordelia-system",
 		installName:      "cordelia-bootstrap",
+		rbac:             RBACProfileStrict,
 	}
 }
 
@@ -49,6 +51,7 @@ func newInitCommand() *cobra.Command {
 
 	cmd.Flags().BoolVar(&o.bootstrap, "bootstrap", false, "stop after creating the bootstrap configuration")
 	cmd.Flags().BoolVar(&o.dryRun, "dry-run", false, "generate the manifests instead of applying them")
+	cmd.Flags().StringVar(&o.rbac, "rbac", o.rbac, "RBAC profile for the bootstrap job, one of: strict, permissive")
 
 	// TODO How do we get the server address?
 	// TODO How do we collect client_id/secret? Only from a file?
@@ -63,7 +66,7 @@ func (o *initOptions) run() error {
 		return err
 	}
 
-	bootstrapConfig, err := newBootstrapConfig(o.installNamespace, o.installName, clientConfig)
+	bootstrapConfig, err := newBootstrapConfig(o.installNamespace, o.installName, clientConfig, o.rbac)
 	if err != nil {
 		return err
 	}
@@ -99,6 +102,7 @@ func (o *initOptions) run() error {
 		return err
 	}
 	namespacesClient := clientset.CoreV1().Namespaces()
+	serviceAccountsClient := clientset.CoreV1().ServiceAccounts(o.installNamespace)
 	clusterRolesClient := clientset.RbacV1().ClusterRoles()
 	clusterRoleBindingsClient := clientset.RbacV1().ClusterRoleBindings()
 	rolesClient := clientset.RbacV1().Roles(o.installNamespace)
@@ -114,6 +118,13 @@ func (o *initOptions) run() error {
 		return err
 	}
 
+	if _, err = serviceAccountsClient.Create(&bootstrapConfig.ServiceAccount); err != nil {
+		return err
+	}
+	defer func() {
+		_ = serviceAccountsClient.Delete(o.installName, nil)
+	}()
+
 	if _, err = clusterRolesClient.Create(&bootstrapConfig.ClusterRole); err != nil {
 		return err
 	}
@@ -0,0 +1,294 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/thestormforge/optimize-controller/v2/internal/sfio"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+)
+
+// Client is a client-go backed implementation of Interface.
+type Client struct {
+	// Dynamic is used to create, fetch, and delete arbitrary objects once their REST mapping is known.
+	Dynamic dynamic.Interface
+	// Clientset is used for the handful of operations (namespace listing, pod log/watch) that do not
+	// benefit from going through the dynamic client.
+	Clientset kubernetes.Interface
+	// Mapper resolves a manifest's GroupVersionKind to the REST resource used to act on it.
+	Mapper meta.RESTMapper
+	// Namespace is used for any object read from a manifest that does not specify its own namespace.
+	Namespace string
+}
+
+var _ Interface = &Client{}
+
+// NewForConfig creates a client that defaults unqualified manifests to namespace.
+func NewForConfig(cfg *rest.Config, namespace string) (*Client, error) {
+	dc, err := discovery.NewDiscoveryClientForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	dyn, err := dynamic.NewForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		Dynamic:   dyn,
+		Clientset: clientset,
+		Mapper:    restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(dc)),
+		Namespace: namespace,
+	}, nil
+}
+
+// Create submits every object read from r to the cluster and returns the resulting resources.
+func (c *Client) Create(r io.Reader) (ResourceList, error) {
+	resources, err := c.read(r)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, res := range resources {
+		created, err := c.resourceInterface(res).Create(res.Object, metav1.CreateOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("could not create %s %q: %w", res.Object.GetKind(), res.Object.GetName(), err)
+		}
+		res.Object = created
+	}
+
+	return resources, nil
+}
+
+// Get fetches the current state of every object read from r, grouped by kind.
+func (c *Client) Get(namespace string, r io.Reader) (map[string][]runtime.Object, error) {
+	resources, err := c.read(r)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string][]runtime.Object)
+	for _, res := range resources {
+		ns := res.Object.GetNamespace()
+		if ns == "" {
+			ns = namespace
+		}
+
+		u, err := c.namespacedResourceInterface(res, ns).Get(res.Object.GetName(), metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not get %s %q: %w", res.Object.GetKind(), res.Object.GetName(), err)
+		}
+
+		kind := res.Object.GetKind()
+		result[kind] = append(result[kind], u)
+	}
+
+	return result, nil
+}
+
+// WatchUntilReady blocks until every Job or Pod read from r reaches a terminal state, or timeout elapses;
+// objects of any other kind are assumed to be ready as soon as they exist and are not watched.
+func (c *Client) WatchUntilReady(timeout time.Duration, r io.Reader) error {
+	resources, err := c.read(r)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	for _, res := range resources {
+		switch res.Object.GetKind() {
+		case "Job", "Pod":
+			if err := c.watchUntilReady(ctx, res); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// watchUntilReady waits for a single Job or Pod to complete.
+func (c *Client) watchUntilReady(ctx context.Context, res *Resource) error {
+	ns := res.Object.GetNamespace()
+	if ns == "" {
+		ns = c.Namespace
+	}
+
+	w, err := c.namespacedResourceInterface(res, ns).Watch(metav1.ListOptions{
+		FieldSelector:   "metadata.name=" + res.Object.GetName(),
+		ResourceVersion: res.Object.GetResourceVersion(),
+	})
+	if err != nil {
+		return err
+	}
+	defer w.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for %s %q to be ready", res.Object.GetKind(), res.Object.GetName())
+		case evt, ok := <-w.ResultChan():
+			if !ok {
+				return fmt.Errorf("watch closed before %s %q became ready", res.Object.GetKind(), res.Object.GetName())
+			}
+
+			u, ok := evt.Object.(*unstructured.Unstructured)
+			if !ok {
+				continue
+			}
+
+			ready, err := isReady(u)
+			if err != nil {
+				return err
+			}
+			if ready {
+				return nil
+			}
+		}
+	}
+}
+
+// isReady reports whether a Job has completed or a Pod has succeeded.
+func isReady(u *unstructured.Unstructured) (bool, error) {
+	switch u.GetKind() {
+	case "Job":
+		conditions, _, err := unstructured.NestedSlice(u.Object, "status", "conditions")
+		if err != nil {
+			return false, err
+		}
+		for _, c := range conditions {
+			m, ok := c.(map[string]interface{})
+			if ok && m["type"] == "Complete" && m["status"] == string(corev1.ConditionTrue) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case "Pod":
+		phase, _, err := unstructured.NestedString(u.Object, "status", "phase")
+		if err != nil {
+			return false, err
+		}
+		return phase == string(corev1.PodSucceeded), nil
+	default:
+		return true, nil
+	}
+}
+
+// Delete removes the supplied resources, ignoring any that are already gone.
+func (c *Client) Delete(resources ResourceList) error {
+	for _, res := range resources {
+		ns := res.Object.GetNamespace()
+		if ns == "" {
+			ns = c.Namespace
+		}
+
+		err := c.namespacedResourceInterface(res, ns).Delete(res.Object.GetName(), nil)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("could not delete %s %q: %w", res.Object.GetKind(), res.Object.GetName(), err)
+		}
+	}
+	return nil
+}
+
+// Namespaces lists the names of the namespaces visible in the cluster.
+func (c *Client) Namespaces() ([]string, error) {
+	list, err := c.Clientset.CoreV1().Namespaces().List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(list.Items))
+	for i := range list.Items {
+		names = append(names, list.Items[i].Name)
+	}
+	return names, nil
+}
+
+// read decodes every YAML document in r into a Resource with its REST mapping resolved.
+func (c *Client) read(r io.Reader) (ResourceList, error) {
+	nodes, err := kio.ByteReader{Reader: r}.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make(ResourceList, 0, len(nodes))
+	for _, node := range nodes {
+		u := &unstructured.Unstructured{}
+		if err := sfio.DecodeYAMLToJSON(node, &u.Object); err != nil {
+			return nil, err
+		}
+		if u.Object == nil {
+			continue
+		}
+
+		gvk := u.GroupVersionKind()
+		mapping, err := c.Mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err != nil {
+			return nil, fmt.Errorf("could not map %s %q: %w", gvk.Kind, u.GetName(), err)
+		}
+
+		resources = append(resources, &Resource{Object: u, Mapping: mapping})
+	}
+
+	return resources, nil
+}
+
+// resourceInterface returns a dynamic resource client scoped to the namespace on the object itself (or the
+// client's default namespace, for namespaced kinds that do not specify one).
+func (c *Client) resourceInterface(res *Resource) dynamic.ResourceInterface {
+	ns := res.Object.GetNamespace()
+	if ns == "" {
+		ns = c.Namespace
+	}
+	return c.namespacedResourceInterface(res, ns)
+}
+
+// namespacedResourceInterface returns a dynamic resource client scoped to namespace, or the cluster-scoped
+// client if the kind is not namespaced.
+func (c *Client) namespacedResourceInterface(res *Resource, namespace string) dynamic.ResourceInterface {
+	if res.Mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		return c.Dynamic.Resource(res.Mapping.Resource).Namespace(namespace)
+	}
+	return c.Dynamic.Resource(res.Mapping.Resource)
+}
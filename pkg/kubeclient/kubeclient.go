@@ -0,0 +1,71 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kubeclient provides a typed interface for the handful of cluster operations the CLI needs
+// (create, fetch, wait for readiness, delete, list namespaces) over a stream of YAML manifests, modeled on
+// the interface Helm exposes from its pkg/kube package after its client-go refactor. It exists so those
+// operations can be driven through client-go instead of shelling out to a `kubectl` subprocess per
+// invocation, which precludes structured error handling and makes the call sites impossible to unit test.
+package kubeclient
+
+import (
+	"io"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Resource is a single manifest read in from a stream along with the REST mapping needed to act on it
+// again without re-resolving its GroupVersionKind.
+type Resource struct {
+	Object  *unstructured.Unstructured
+	Mapping *meta.RESTMapping
+}
+
+// ResourceList is the result of reading one or more manifests from a stream.
+type ResourceList []*Resource
+
+// GroupVersionKinds returns the distinct kinds present in the list, in the order they first appear.
+func (l ResourceList) GroupVersionKinds() []schema.GroupVersionKind {
+	var gvks []schema.GroupVersionKind
+	seen := make(map[schema.GroupVersionKind]bool)
+	for _, r := range l {
+		gvk := r.Object.GroupVersionKind()
+		if !seen[gvk] {
+			seen[gvk] = true
+			gvks = append(gvks, gvk)
+		}
+	}
+	return gvks
+}
+
+// Interface is the set of cluster operations required to create an experiment, inspect its trials, and
+// clean up afterwards.
+type Interface interface {
+	// Create submits every object read from r to the cluster and returns the resulting resources.
+	Create(r io.Reader) (ResourceList, error)
+	// Get fetches the current state of every object read from r, grouped by kind.
+	Get(namespace string, r io.Reader) (map[string][]runtime.Object, error)
+	// WatchUntilReady blocks until every Job or Pod read from r reaches a terminal state, or timeout elapses.
+	WatchUntilReady(timeout time.Duration, r io.Reader) error
+	// Delete removes the supplied resources, ignoring any that are already gone.
+	Delete(resources ResourceList) error
+	// Namespaces lists the names of the namespaces visible in the cluster.
+	Namespaces() ([]string, error)
+}
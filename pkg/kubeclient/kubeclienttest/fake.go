@@ -0,0 +1,134 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kubeclienttest provides an in-memory fake of kubeclient.Interface for unit tests that would
+// otherwise need a real cluster (or a `kubectl` binary) to exercise.
+package kubeclienttest
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/thestormforge/optimize-controller/v2/internal/sfio"
+	"github.com/thestormforge/optimize-controller/v2/pkg/kubeclient"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+)
+
+type objectKey struct {
+	namespace, name, kind string
+}
+
+// Client is an in-memory fake of kubeclient.Interface. The zero value is ready to use.
+type Client struct {
+	// Namespaces is the list returned by the Namespaces method.
+	NamespaceNames []string
+
+	objects map[objectKey]*unstructured.Unstructured
+}
+
+var _ kubeclient.Interface = &Client{}
+
+// Create records every object read from r as if it were created in the cluster.
+func (c *Client) Create(r io.Reader) (kubeclient.ResourceList, error) {
+	objs, err := read(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.objects == nil {
+		c.objects = make(map[objectKey]*unstructured.Unstructured)
+	}
+
+	resources := make(kubeclient.ResourceList, 0, len(objs))
+	for _, obj := range objs {
+		c.objects[keyOf(obj)] = obj
+		resources = append(resources, &kubeclient.Resource{Object: obj})
+	}
+	return resources, nil
+}
+
+// Get returns the recorded state of every object read from r, grouped by kind.
+func (c *Client) Get(namespace string, r io.Reader) (map[string][]runtime.Object, error) {
+	objs, err := read(r)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string][]runtime.Object)
+	for _, obj := range objs {
+		ns := obj.GetNamespace()
+		if ns == "" {
+			ns = namespace
+		}
+
+		stored, ok := c.objects[objectKey{namespace: ns, name: obj.GetName(), kind: obj.GetKind()}]
+		if !ok {
+			continue
+		}
+		result[obj.GetKind()] = append(result[obj.GetKind()], stored)
+	}
+	return result, nil
+}
+
+// WatchUntilReady always returns immediately since the fake has no asynchronous state to wait on.
+func (c *Client) WatchUntilReady(timeout time.Duration, r io.Reader) error {
+	_, err := read(r)
+	return err
+}
+
+// Delete removes the supplied resources from the recorded state.
+func (c *Client) Delete(resources kubeclient.ResourceList) error {
+	for _, res := range resources {
+		delete(c.objects, keyOf(res.Object))
+	}
+	return nil
+}
+
+// Namespaces returns NamespaceNames.
+func (c *Client) Namespaces() ([]string, error) {
+	return c.NamespaceNames, nil
+}
+
+func keyOf(obj *unstructured.Unstructured) objectKey {
+	return objectKey{namespace: obj.GetNamespace(), name: obj.GetName(), kind: obj.GetKind()}
+}
+
+// read decodes every YAML document in r into an unstructured object.
+func read(r io.Reader) ([]*unstructured.Unstructured, error) {
+	nodes, err := kio.ByteReader{Reader: r}.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	objs := make([]*unstructured.Unstructured, 0, len(nodes))
+	for _, node := range nodes {
+		u := &unstructured.Unstructured{}
+		if err := sfio.DecodeYAMLToJSON(node, &u.Object); err != nil {
+			return nil, err
+		}
+		if u.Object == nil {
+			continue
+		}
+		if u.GetKind() == "" {
+			return nil, fmt.Errorf("kubeclienttest: manifest is missing kind")
+		}
+		objs = append(objs, u)
+	}
+	return objs, nil
+}
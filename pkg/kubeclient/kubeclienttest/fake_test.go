@@ -0,0 +1,76 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeclienttest
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const configMapManifest = `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: test-config
+  namespace: default
+data:
+  foo: bar
+`
+
+func TestClient_CreateAndGet(t *testing.T) {
+	c := &Client{}
+
+	created, err := c.Create(strings.NewReader(configMapManifest))
+	require.NoError(t, err)
+	assert.Len(t, created, 1)
+
+	fetched, err := c.Get("default", strings.NewReader(configMapManifest))
+	require.NoError(t, err)
+	require.Len(t, fetched["ConfigMap"], 1)
+	assert.Equal(t, "test-config", fetched["ConfigMap"][0].(*unstructured.Unstructured).GetName())
+}
+
+func TestClient_Delete(t *testing.T) {
+	c := &Client{}
+
+	created, err := c.Create(strings.NewReader(configMapManifest))
+	require.NoError(t, err)
+
+	require.NoError(t, c.Delete(created))
+
+	fetched, err := c.Get("default", strings.NewReader(configMapManifest))
+	require.NoError(t, err)
+	assert.Empty(t, fetched["ConfigMap"])
+}
+
+func TestClient_Namespaces(t *testing.T) {
+	c := &Client{NamespaceNames: []string{"default", "optimize-system"}}
+
+	ns, err := c.Namespaces()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"default", "optimize-system"}, ns)
+}
+
+func TestClient_WatchUntilReady(t *testing.T) {
+	c := &Client{}
+	assert.NoError(t, c.WatchUntilReady(time.Second, strings.NewReader(configMapManifest)))
+}
@@ -96,8 +96,9 @@ type Constraint struct {
 type ParameterType string
 
 const (
-	ParameterTypeInteger ParameterType = "int"
-	ParameterTypeDouble  ParameterType = "double"
+	ParameterTypeInteger     ParameterType = "int"
+	ParameterTypeDouble      ParameterType = "double"
+	ParameterTypeCategorical ParameterType = "categorical"
 )
 
 type Bounds struct {
@@ -113,8 +114,10 @@ type Parameter struct {
 	Name string `json:"name"`
 	// The type of the parameter.
 	Type ParameterType `json:"type"`
-	// The domain of the parameter.
-	Bounds Bounds `json:"bounds"`
+	// The domain of a numeric parameter, unused for categorical parameters.
+	Bounds *Bounds `json:"bounds,omitempty"`
+	// The allowed values of a categorical parameter, unused for numeric parameters.
+	Values []string `json:"values,omitempty"`
 }
 
 type ExperimentMeta struct {
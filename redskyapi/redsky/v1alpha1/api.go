@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -46,6 +47,13 @@ type Meta interface {
 	SetLocation(string)
 	SetLastModified(time.Time)
 	SetLink(rel, link string)
+	AddWarning(code int, agent, text string)
+}
+
+// warningAdder is implemented by every Meta in addition to AddWarning, so that metaUnmarshal can
+// record the date parsed from the Warning header without stretching the public AddWarning signature
+type warningAdder interface {
+	addWarning(Warning)
 }
 
 // Metadata is used to hold single or multi-value metadata from list responses
@@ -102,11 +110,13 @@ func (e *Error) Error() string {
 }
 
 type ServerMeta struct {
-	Server string `json:"-"`
+	Server   string    `json:"-"`
+	Warnings []Warning `json:"-"`
 }
 
 func (m *ServerMeta) Unmarshal(header http.Header) {
 	m.Server = header.Get("Server")
+	m.Warnings = parseWarnings(header)
 }
 
 // ExperimentName exists to clearly separate cases where an actual name can be used
@@ -152,6 +162,7 @@ type ParameterType string
 const (
 	ParameterTypeInteger ParameterType = "int"
 	ParameterTypeDouble                = "double"
+	ParameterTypeString                = "string"
 )
 
 type Bounds struct {
@@ -167,8 +178,14 @@ type Parameter struct {
 	Name string `json:"name"`
 	// The type of the parameter.
 	Type ParameterType `json:"type"`
-	// The domain of the parameter.
+	// The domain of the parameter, used for ParameterTypeInteger and ParameterTypeDouble.
 	Bounds Bounds `json:"bounds"`
+	// The allowed values of the parameter, used for ParameterTypeString.
+	Values []string `json:"values,omitempty"`
+	// Constraints are additional linear/ordering expressions an assignment for this parameter
+	// must satisfy together with the rest of the experiment's assignments (e.g. "memory >= 2 *
+	// cpu"). They are only checked by ValidateAssignments, which has access to every assignment.
+	Constraints []string `json:"constraints,omitempty"`
 }
 
 type ExperimentMeta struct {
@@ -176,6 +193,7 @@ type ExperimentMeta struct {
 	Self         string    `json:"-"`
 	Trials       string    `json:"-"`
 	NextTrial    string    `json:"-"`
+	Warnings     []Warning `json:"-"`
 }
 
 func (m *ExperimentMeta) SetLocation(string) {}
@@ -192,6 +210,10 @@ func (m *ExperimentMeta) SetLink(rel, link string) {
 		m.NextTrial = link
 	}
 }
+func (m *ExperimentMeta) AddWarning(code int, agent, text string) {
+	m.addWarning(Warning{Code: code, Agent: agent, Text: text, Date: time.Now()})
+}
+func (m *ExperimentMeta) addWarning(w Warning) { m.Warnings = append(m.Warnings, w) }
 
 // Experiment combines the search space, outcomes and optimization configuration
 type Experiment struct {
@@ -215,8 +237,9 @@ type ExperimentItem struct {
 }
 
 type ExperimentListMeta struct {
-	Next string `json:"-"`
-	Prev string `json:"-"`
+	Next     string    `json:"-"`
+	Prev     string    `json:"-"`
+	Warnings []Warning `json:"-"`
 }
 
 func (m *ExperimentListMeta) SetLocation(string)        {}
@@ -229,6 +252,10 @@ func (m *ExperimentListMeta) SetLink(rel, link string) {
 		m.Prev = link
 	}
 }
+func (m *ExperimentListMeta) AddWarning(code int, agent, text string) {
+	m.addWarning(Warning{Code: code, Agent: agent, Text: text, Date: time.Now()})
+}
+func (m *ExperimentListMeta) addWarning(w Warning) { m.Warnings = append(m.Warnings, w) }
 
 type ExperimentListQuery struct {
 	Offset int
@@ -254,12 +281,17 @@ type ExperimentList struct {
 }
 
 type TrialMeta struct {
-	ReportTrial string `json:"-"`
+	ReportTrial string    `json:"-"`
+	Warnings    []Warning `json:"-"`
 }
 
 func (m *TrialMeta) SetLocation(location string) { m.ReportTrial = location }
 func (m *TrialMeta) SetLastModified(time.Time)   {}
 func (m *TrialMeta) SetLink(string, string)      {}
+func (m *TrialMeta) AddWarning(code int, agent, text string) {
+	m.addWarning(Warning{Code: code, Agent: agent, Text: text, Date: time.Now()})
+}
+func (m *TrialMeta) addWarning(w Warning) { m.Warnings = append(m.Warnings, w) }
 
 type Assignment struct {
 	// The name of the parameter in the experiment the assignment corresponds to.
@@ -344,15 +376,25 @@ type API interface {
 	CreateExperiment(context.Context, ExperimentName, Experiment) (Experiment, error)
 	DeleteExperiment(context.Context, string) error
 	GetAllTrials(context.Context, string, *TrialListQuery) (TrialList, error)
+	WatchTrials(context.Context, string, WatchOptions) (<-chan TrialEvent, error)
 	CreateTrial(context.Context, string, TrialAssignments) (string, error) // TODO Should this return TrialAssignments?
 	NextTrial(context.Context, string) (TrialAssignments, error)
+	NextTrialWithDeadline(context.Context, string, time.Time) (TrialAssignments, error)
 	ReportTrial(context.Context, string, TrialValues) error
 	AbandonRunningTrial(context.Context, string) error
 }
 
 // NewForConfig returns a new API instance for the specified configuration
 func NewForConfig(cfg redskyclient.Config, transport http.RoundTripper) (API, error) {
-	// TODO We should be wrapping transport, e.g. for our retry-after logic
+	transport = &RetryTransport{
+		Base: transport,
+		ShouldRetry: func(req *http.Request) bool {
+			// NextTrial is the only POST sent without a body; it long-polls for an assignment and is
+			// safe (and expected) to retry
+			return req.Method == http.MethodPost && req.Body == nil
+		},
+	}
+
 	c, err := redskyclient.NewClient(cfg, context.Background(), transport)
 	if err != nil {
 		return nil, err
@@ -577,13 +619,32 @@ func (h *httpAPI) CreateTrial(ctx context.Context, u string, asm TrialAssignment
 }
 
 func (h *httpAPI) NextTrial(ctx context.Context, u string) (TrialAssignments, error) {
-	asm := TrialAssignments{}
-
 	req, err := http.NewRequest(http.MethodPost, u, nil)
 	if err != nil {
-		return asm, err
+		return TrialAssignments{}, err
 	}
 
+	return h.doNextTrial(ctx, req)
+}
+
+// NextTrialWithDeadline behaves like NextTrial, but bounds the long-poll by deadline independent of
+// ctx's own cancellation: the request is built against a context derived from ctx so the in-flight
+// HTTP request is canceled the moment the deadline fires, even if ctx itself is never canceled.
+func (h *httpAPI) NextTrialWithDeadline(ctx context.Context, u string, deadline time.Time) (TrialAssignments, error) {
+	dctx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(dctx, http.MethodPost, u, nil)
+	if err != nil {
+		return TrialAssignments{}, err
+	}
+
+	return h.doNextTrial(dctx, req)
+}
+
+func (h *httpAPI) doNextTrial(ctx context.Context, req *http.Request) (TrialAssignments, error) {
+	asm := TrialAssignments{}
+
 	resp, body, err := h.client.Do(ctx, req)
 	if err != nil {
 		return asm, err
@@ -668,13 +729,29 @@ func (h *httpAPI) AbandonRunningTrial(ctx context.Context, u string) error {
 // TODO Unmarshal _expected_ errors to get better messages as well
 // TODO Just return nil for any 2xx status codes?
 func unexpected(resp *http.Response, body []byte) error {
+	mediaType := resp.Header.Get("Content-Type")
+	if i := strings.IndexByte(mediaType, ';'); i >= 0 {
+		mediaType = mediaType[:i]
+	}
+	mediaType = strings.TrimSpace(mediaType)
+
+	if mediaType == mediaTypeProblemJSON {
+		if p := unmarshalProblem(resp, body); p != nil {
+			return p
+		}
+	}
+
 	err := &Error{Type: ErrUnexpected}
 
-	if resp.Header.Get("Content-Type") == "application/json" {
+	if mediaType == "application/json" {
 		// Unmarshal body into the error to get the error message
 		_ = json.Unmarshal(body, err)
 	}
 
+	if resp.StatusCode >= 400 {
+		err.RetryAfter = retryAfter(resp.Header.Get("Retry-After"))
+	}
+
 	switch resp.StatusCode {
 	case http.StatusUnauthorized:
 		if err.Message == "" {
@@ -693,6 +770,46 @@ func unexpected(resp *http.Response, body []byte) error {
 	return err
 }
 
+// Warning is a non-fatal, RFC 7234 style warning reported by the server alongside an otherwise
+// successful response (e.g. a deprecation notice or a budget-approaching-exhaustion signal).
+type Warning struct {
+	// Code is the three digit warn-code (e.g. 199 for a miscellaneous warning).
+	Code int
+	// Agent identifies the server that produced the warning, or "-" if it is not known.
+	Agent string
+	// Text is the human readable warning message.
+	Text string
+	// Date is the time the warning was generated, parsed from the optional warn-date.
+	Date time.Time
+}
+
+// warningPattern matches the RFC 7234 `Warning` header production: warn-code SP warn-agent SP
+// warn-text [SP warn-date], repeated as a comma separated list.
+var warningPattern = regexp.MustCompile(`(\d{3})\s+(\S+)\s+"((?:[^"\\]|\\.)*)"(?:\s+"([^"]*)")?`)
+
+// parseWarnings extracts the warnings from the `Warning` response headers, failures to parse an
+// individual entry are silently ignored.
+func parseWarnings(header http.Header) []Warning {
+	var warnings []Warning
+	for _, raw := range header[http.CanonicalHeaderKey("Warning")] {
+		for _, m := range warningPattern.FindAllStringSubmatch(raw, -1) {
+			code, err := strconv.Atoi(m[1])
+			if err != nil {
+				continue
+			}
+
+			w := Warning{Code: code, Agent: m[2], Text: strings.ReplaceAll(m[3], `\"`, `"`)}
+			if m[4] != "" {
+				if date, err := http.ParseTime(m[4]); err == nil {
+					w.Date = date
+				}
+			}
+			warnings = append(warnings, w)
+		}
+	}
+	return warnings
+}
+
 // Extract metadata from the response headers, failures are silently ignored, always call before extracting entity body
 func metaUnmarshal(header http.Header, meta Meta) {
 	if location := header.Get("Location"); location != "" {
@@ -705,6 +822,12 @@ func metaUnmarshal(header http.Header, meta Meta) {
 		}
 	}
 
+	if wa, ok := meta.(warningAdder); ok {
+		for _, w := range parseWarnings(header) {
+			wa.addWarning(w)
+		}
+	}
+
 	for _, rh := range header[http.CanonicalHeaderKey("Link")] {
 		for _, h := range strings.Split(rh, ",") {
 			var link, rel string
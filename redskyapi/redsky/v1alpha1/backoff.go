@@ -0,0 +1,128 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ClientRateLimiter lets a caller plug in its own concurrency control (e.g. a token bucket) in
+// front of the API client, independent of the per-URL failure backoff RetryTransport already
+// applies. This is the hook for bounding concurrency when many trials report observations at once.
+type ClientRateLimiter interface {
+	// Wait blocks until a request may proceed, or ctx is done.
+	Wait(ctx context.Context) error
+}
+
+// URLBackoff tracks, per URL, how many consecutive failures (429/5xx) a request has returned and
+// delays the next request to that URL accordingly. This is proactive: unlike the in-request retry
+// loop in RetryTransport, it applies *before* a request is even sent, so a URL that is currently
+// failing doesn't get hammered by unrelated callers while it recovers. Modeled on client-go's
+// rest.URLBackoff: the delay doubles on each consecutive failure up to Cap, and resets to zero the
+// moment a request to that URL succeeds.
+type URLBackoff struct {
+	// Base is the delay applied after the first consecutive failure. Defaults to 500ms.
+	Base time.Duration
+	// Cap bounds how large the delay is allowed to grow. Defaults to 30s.
+	Cap time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*backoffEntry
+}
+
+type backoffEntry struct {
+	failures int
+	until    time.Time
+}
+
+// Wait blocks until the backoff window previously recorded for req's URL has elapsed, or ctx is
+// done. It returns immediately if the URL has no recorded failures.
+func (b *URLBackoff) Wait(ctx context.Context, req *http.Request) error {
+	b.mu.Lock()
+	var wait time.Duration
+	if e, ok := b.entries[urlKey(req)]; ok {
+		wait = time.Until(e.until)
+	}
+	b.mu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Observe records the outcome of a request to req's URL. A failure doubles the delay applied to
+// the next request for that URL (capped at Cap); a success resets the delay to zero.
+func (b *URLBackoff) Observe(req *http.Request, failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.entries == nil {
+		b.entries = make(map[string]*backoffEntry)
+	}
+
+	k := urlKey(req)
+	e, ok := b.entries[k]
+	if !ok {
+		e = &backoffEntry{}
+		b.entries[k] = e
+	}
+
+	if !failed {
+		e.failures = 0
+		e.until = time.Time{}
+		return
+	}
+
+	e.failures++
+	d := b.base() << uint(e.failures-1)
+	if d <= 0 || d > b.cap() {
+		d = b.cap()
+	}
+	e.until = time.Now().Add(d)
+}
+
+func (b *URLBackoff) base() time.Duration {
+	if b.Base > 0 {
+		return b.Base
+	}
+	return 500 * time.Millisecond
+}
+
+func (b *URLBackoff) cap() time.Duration {
+	if b.Cap > 0 {
+		return b.Cap
+	}
+	return 30 * time.Second
+}
+
+// urlKey identifies a URL for backoff bookkeeping purposes: host and path, ignoring the query
+// string so e.g. offset/limit pagination over the same collection shares one backoff entry.
+func urlKey(req *http.Request) string {
+	return req.URL.Host + req.URL.Path
+}
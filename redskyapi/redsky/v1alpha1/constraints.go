@@ -0,0 +1,180 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Validate checks a's value against the domain of p (Bounds for numeric types, Values for
+// ParameterTypeString). It does not evaluate p.Constraints, which may reference other parameters'
+// assignments; use ValidateAssignments for that.
+func (p *Parameter) Validate(a Assignment) error {
+	if a.ParameterName != p.Name {
+		return fmt.Errorf("assignment %q does not match parameter %q", a.ParameterName, p.Name)
+	}
+
+	switch p.Type {
+	case ParameterTypeInteger:
+		v, err := a.Value.Int64()
+		if err != nil {
+			return fmt.Errorf("parameter %q: %w", p.Name, err)
+		}
+		min, err := p.Bounds.Min.Int64()
+		if err != nil {
+			return fmt.Errorf("parameter %q: %w", p.Name, err)
+		}
+		max, err := p.Bounds.Max.Int64()
+		if err != nil {
+			return fmt.Errorf("parameter %q: %w", p.Name, err)
+		}
+		if v < min || v > max {
+			return fmt.Errorf("parameter %q: value %d out of bounds [%d,%d]", p.Name, v, min, max)
+		}
+
+	case ParameterTypeDouble:
+		v, err := a.Value.Float64()
+		if err != nil {
+			return fmt.Errorf("parameter %q: %w", p.Name, err)
+		}
+		min, err := p.Bounds.Min.Float64()
+		if err != nil {
+			return fmt.Errorf("parameter %q: %w", p.Name, err)
+		}
+		max, err := p.Bounds.Max.Float64()
+		if err != nil {
+			return fmt.Errorf("parameter %q: %w", p.Name, err)
+		}
+		if v < min || v > max {
+			return fmt.Errorf("parameter %q: value %g out of bounds [%g,%g]", p.Name, v, min, max)
+		}
+
+	case ParameterTypeString:
+		for _, allowed := range p.Values {
+			if a.Value.String() == allowed {
+				return nil
+			}
+		}
+		return fmt.Errorf("parameter %q: value %q is not one of %v", p.Name, a.Value.String(), p.Values)
+	}
+
+	return nil
+}
+
+// ValidateAssignments checks each assignment against its parameter's domain and then evaluates
+// every parameter's Constraints expressions against the full assignment set. Unlike Parameter.Validate,
+// this can see across parameters, which constraint expressions are allowed to reference.
+func ValidateAssignments(params []Parameter, assignments []Assignment) error {
+	values := make(map[string]float64, len(assignments))
+	byName := make(map[string]*Assignment, len(assignments))
+	for i := range assignments {
+		byName[assignments[i].ParameterName] = &assignments[i]
+		if f, err := assignments[i].Value.Float64(); err == nil {
+			values[assignments[i].ParameterName] = f
+		}
+	}
+
+	for i := range params {
+		p := &params[i]
+		a, ok := byName[p.Name]
+		if !ok {
+			continue
+		}
+		if err := p.Validate(*a); err != nil {
+			return err
+		}
+
+		for _, expr := range p.Constraints {
+			ok, err := evaluateConstraint(expr, values)
+			if err != nil {
+				return fmt.Errorf("parameter %q: constraint %q: %w", p.Name, expr, err)
+			}
+			if !ok {
+				return fmt.Errorf("parameter %q: constraint %q is not satisfied", p.Name, expr)
+			}
+		}
+	}
+
+	return nil
+}
+
+// evaluateConstraint evaluates a small expression grammar of the form "<term> <op> <term>", where
+// <op> is one of >=, <=, ==, >, < and <term> is a bare number, a bare parameter name, or a
+// "<coefficient> * <parameter name>" product. This intentionally does not support multi-term sums;
+// it covers simple ordering constraints like "memory >= 2 * cpu" without growing into a general
+// expression parser.
+func evaluateConstraint(expr string, values map[string]float64) (bool, error) {
+	for _, op := range []string{">=", "<=", "==", ">", "<"} {
+		idx := strings.Index(expr, op)
+		if idx < 0 {
+			continue
+		}
+
+		lhs, err := evaluateTerm(strings.TrimSpace(expr[:idx]), values)
+		if err != nil {
+			return false, err
+		}
+		rhs, err := evaluateTerm(strings.TrimSpace(expr[idx+len(op):]), values)
+		if err != nil {
+			return false, err
+		}
+
+		switch op {
+		case ">=":
+			return lhs >= rhs, nil
+		case "<=":
+			return lhs <= rhs, nil
+		case "==":
+			return lhs == rhs, nil
+		case ">":
+			return lhs > rhs, nil
+		case "<":
+			return lhs < rhs, nil
+		}
+	}
+
+	return false, fmt.Errorf("no comparison operator found in %q", expr)
+}
+
+// evaluateTerm evaluates one side of a constraint expression: a bare number, a bare parameter name,
+// or a "<coefficient> * <parameter name>" product.
+func evaluateTerm(term string, values map[string]float64) (float64, error) {
+	if v, err := strconv.ParseFloat(term, 64); err == nil {
+		return v, nil
+	}
+
+	if i := strings.Index(term, "*"); i >= 0 {
+		coef, err := strconv.ParseFloat(strings.TrimSpace(term[:i]), 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid coefficient in term %q: %w", term, err)
+		}
+		name := strings.TrimSpace(term[i+1:])
+		v, ok := values[name]
+		if !ok {
+			return 0, fmt.Errorf("unknown parameter %q in term %q", name, term)
+		}
+		return coef * v, nil
+	}
+
+	v, ok := values[term]
+	if !ok {
+		return 0, fmt.Errorf("unknown parameter %q in term %q", term, term)
+	}
+	return v, nil
+}
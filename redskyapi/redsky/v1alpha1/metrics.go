@@ -0,0 +1,182 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Metrics is the set of Prometheus collectors used to instrument API usage. Create one with
+// NewMetrics and pass it to NewForConfigWithRegisterer to have every API call observed.
+type Metrics struct {
+	httpInFlight prometheus.Gauge
+	httpDuration *prometheus.HistogramVec
+
+	requestsTotal *prometheus.CounterVec
+	errorsTotal   *prometheus.CounterVec
+	retryAfter    prometheus.Histogram
+}
+
+// NewMetrics creates and registers the collectors used to instrument the API client against reg.
+func NewMetrics(reg prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		httpInFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "redsky_api_client_http_in_flight_requests",
+			Help: "Number of in-flight HTTP requests to the Red Sky API server",
+		}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name: "redsky_api_client_http_request_duration_seconds",
+			Help: "Latency of HTTP requests to the Red Sky API server",
+		}, []string{"method", "code"}),
+		requestsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "redsky_api_client_requests_total",
+			Help: "Total number of API calls made to the Red Sky API server, partitioned by endpoint and method",
+		}, []string{"endpoint", "method"}),
+		errorsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "redsky_api_client_errors_total",
+			Help: "Total number of typed API errors returned by the Red Sky API server, partitioned by error type",
+		}, []string{"type"}),
+		retryAfter: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "redsky_api_client_next_trial_retry_after_seconds",
+			Help:    "Retry-After durations returned by NextTrial while waiting for a trial assignment",
+			Buckets: []float64{1, 2, 5, 10, 15, 30, 60, 120},
+		}),
+	}
+
+	reg.MustRegister(m.httpInFlight, m.httpDuration, m.requestsTotal, m.errorsTotal, m.retryAfter)
+
+	return m
+}
+
+// instrumentTransport wraps transport with the standard promhttp in-flight and duration collectors.
+func (m *Metrics) instrumentTransport(transport http.RoundTripper) http.RoundTripper {
+	return promhttp.InstrumentRoundTripperDuration(m.httpDuration,
+		promhttp.InstrumentRoundTripperInFlight(m.httpInFlight, transport))
+}
+
+// observe records the outcome of a single named API call (e.g. "NextTrial"), counting it against
+// requestsTotal and, if it failed with a typed *Error, against errorsTotal.
+func (m *Metrics) observe(endpoint, method string, err error) {
+	m.requestsTotal.WithLabelValues(endpoint, method).Inc()
+
+	if rerr, ok := err.(*Error); ok {
+		m.errorsTotal.WithLabelValues(string(rerr.Type)).Inc()
+		if rerr.Type == ErrTrialUnavailable {
+			m.retryAfter.Observe(rerr.RetryAfter.Seconds())
+		}
+	}
+}
+
+// NewForConfigWithRegisterer is like NewForConfig, but additionally instruments the returned API
+// (and the transport underneath it) with Prometheus metrics registered against reg.
+func NewForConfigWithRegisterer(cfg redskyclient.Config, transport http.RoundTripper, reg prometheus.Registerer) (API, error) {
+	m := NewMetrics(reg)
+
+	api, err := NewForConfig(cfg, m.instrumentTransport(transport))
+	if err != nil {
+		return nil, err
+	}
+
+	return &instrumentedAPI{API: api, metrics: m}, nil
+}
+
+// instrumentedAPI decorates an API, recording the outcome of each call via metrics. Per-request
+// latency is already covered by the underlying HTTP transport's histogram.
+type instrumentedAPI struct {
+	API
+	metrics *Metrics
+}
+
+func (a *instrumentedAPI) observe(endpoint, method string, err error) {
+	a.metrics.observe(endpoint, method, err)
+}
+
+func (a *instrumentedAPI) Options(ctx context.Context) (ServerMeta, error) {
+	r, err := a.API.Options(ctx)
+	a.observe("options", http.MethodOptions, err)
+	return r, err
+}
+
+func (a *instrumentedAPI) GetAllExperiments(ctx context.Context, q *ExperimentListQuery) (ExperimentList, error) {
+	r, err := a.API.GetAllExperiments(ctx, q)
+	a.observe("experiments", http.MethodGet, err)
+	return r, err
+}
+
+func (a *instrumentedAPI) GetAllExperimentsByPage(ctx context.Context, u string) (ExperimentList, error) {
+	r, err := a.API.GetAllExperimentsByPage(ctx, u)
+	a.observe("experiments", http.MethodGet, err)
+	return r, err
+}
+
+func (a *instrumentedAPI) GetExperimentByName(ctx context.Context, n ExperimentName) (Experiment, error) {
+	r, err := a.API.GetExperimentByName(ctx, n)
+	a.observe("experiments", http.MethodGet, err)
+	return r, err
+}
+
+func (a *instrumentedAPI) GetExperiment(ctx context.Context, u string) (Experiment, error) {
+	r, err := a.API.GetExperiment(ctx, u)
+	a.observe("experiments", http.MethodGet, err)
+	return r, err
+}
+
+func (a *instrumentedAPI) CreateExperiment(ctx context.Context, n ExperimentName, exp Experiment) (Experiment, error) {
+	r, err := a.API.CreateExperiment(ctx, n, exp)
+	a.observe("experiments", http.MethodPut, err)
+	return r, err
+}
+
+func (a *instrumentedAPI) DeleteExperiment(ctx context.Context, u string) error {
+	err := a.API.DeleteExperiment(ctx, u)
+	a.observe("experiments", http.MethodDelete, err)
+	return err
+}
+
+func (a *instrumentedAPI) GetAllTrials(ctx context.Context, u string, q *TrialListQuery) (TrialList, error) {
+	r, err := a.API.GetAllTrials(ctx, u, q)
+	a.observe("trials", http.MethodGet, err)
+	return r, err
+}
+
+func (a *instrumentedAPI) CreateTrial(ctx context.Context, u string, asm TrialAssignments) (string, error) {
+	r, err := a.API.CreateTrial(ctx, u, asm)
+	a.observe("trials", http.MethodPost, err)
+	return r, err
+}
+
+func (a *instrumentedAPI) NextTrial(ctx context.Context, u string) (TrialAssignments, error) {
+	r, err := a.API.NextTrial(ctx, u)
+	a.observe("nextTrial", http.MethodPost, err)
+	return r, err
+}
+
+func (a *instrumentedAPI) ReportTrial(ctx context.Context, u string, vls TrialValues) error {
+	err := a.API.ReportTrial(ctx, u, vls)
+	a.observe("report", http.MethodPost, err)
+	return err
+}
+
+func (a *instrumentedAPI) AbandonRunningTrial(ctx context.Context, u string) error {
+	err := a.API.AbandonRunningTrial(ctx, u)
+	a.observe("trials", http.MethodDelete, err)
+	return err
+}
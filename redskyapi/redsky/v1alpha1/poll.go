@@ -0,0 +1,117 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+)
+
+// PollOptions controls the backoff used by PollNextTrial between empty (trial-unavailable)
+// responses, and the per-attempt deadline passed to NextTrialWithDeadline.
+type PollOptions struct {
+	// MinPoll is the initial (and minimum) delay between polls. Defaults to 1 second.
+	MinPoll time.Duration
+	// MaxPoll caps both the delay between polls and the deadline given to a single long-poll
+	// attempt. Defaults to 30 seconds.
+	MaxPoll time.Duration
+	// Jitter is the fraction (0-1) of the computed delay to randomize away. Defaults to 0.5.
+	Jitter float64
+}
+
+func (o *PollOptions) minPoll() time.Duration {
+	if o.MinPoll > 0 {
+		return o.MinPoll
+	}
+	return time.Second
+}
+
+func (o *PollOptions) maxPoll() time.Duration {
+	if o.MaxPoll > 0 {
+		return o.MaxPoll
+	}
+	return 30 * time.Second
+}
+
+func (o *PollOptions) jitter() float64 {
+	if o.Jitter > 0 {
+		return o.Jitter
+	}
+	return 0.5
+}
+
+// jitteredBackoff randomizes away up to o.jitter's fraction of backoff, so concurrent pollers don't
+// all wake up at once.
+func (o *PollOptions) jitteredBackoff(backoff time.Duration) time.Duration {
+	j := o.jitter()
+	if j <= 0 {
+		return backoff
+	}
+	return backoff - time.Duration(float64(backoff)*j*rand.Float64())
+}
+
+// PollNextTrial calls NextTrialWithDeadline in a loop, invoking fn with every trial assignment it
+// receives, until fn returns, the experiment is stopped, or ctx is done; that terminal error (or
+// ctx.Err()) is returned. Each attempt is bounded by opts.MaxPoll so a stalled long-poll is canceled
+// and retried rather than left hanging; between attempts that come back empty it waits for the
+// server's Retry-After when given, otherwise applies a capped exponential backoff with jitter
+// starting at opts.MinPoll. This gives controllers a single, well-tested loop instead of open-coding
+// retry logic around NextTrial at every call site.
+func PollNextTrial(ctx context.Context, api API, u string, opts PollOptions, fn func(TrialAssignments) error) error {
+	backoff := opts.minPoll()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		asm, err := api.NextTrialWithDeadline(ctx, u, time.Now().Add(opts.maxPoll()))
+		if err == nil {
+			return fn(asm)
+		}
+
+		wait := opts.jitteredBackoff(backoff)
+		if backoff *= 2; backoff > opts.maxPoll() {
+			backoff = opts.maxPoll()
+		}
+
+		var rerr *Error
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			// The attempt's own deadline fired with no response, retry with the usual backoff.
+		case errors.As(err, &rerr) && rerr.Type == ErrTrialUnavailable:
+			if rerr.RetryAfter > 0 {
+				wait = rerr.RetryAfter
+				backoff = opts.minPoll()
+			}
+		default:
+			return err
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const mediaTypeProblemJSON = "application/problem+json"
+
+// problemTypePrefix is prepended to an ErrorType to form the `type` member of an RFC 7807 problem,
+// e.g. ErrExperimentNameConflict becomes "https://carbonrelay.com/problems/experiment-name-conflict".
+const problemTypePrefix = "https://carbonrelay.com/problems/"
+
+// problemErrorTypes is the set of ErrorType constants that a problem's `type` member can be mapped
+// back to; anything else is reported as ErrUnexpected so existing errors.As(err, &Error{}) callers
+// keep working even against problem types this client doesn't know about yet.
+var problemErrorTypes = map[ErrorType]bool{
+	ErrExperimentNameInvalid:  true,
+	ErrExperimentNameConflict: true,
+	ErrExperimentInvalid:      true,
+	ErrExperimentNotFound:     true,
+	ErrExperimentStopped:      true,
+	ErrTrialInvalid:           true,
+	ErrTrialUnavailable:       true,
+	ErrTrialNotFound:          true,
+	ErrTrialAlreadyReported:   true,
+}
+
+// ProblemError is an RFC 7807 "application/problem+json" error response. It wraps an Error (mapped
+// from the problem's `type` URI) so existing errors.As(err, &Error{}) callers continue to work,
+// while new callers can inspect the structured fields directly, including any problem-specific
+// members (e.g. `extensions.invalid_parameters`) via Extensions.
+type ProblemError struct {
+	// Type is the problem type URI, e.g. "https://carbonrelay.com/problems/experiment-name-conflict".
+	Type string
+	// Title is a short, human-readable summary of the problem type.
+	Title string
+	// Detail is a human-readable explanation specific to this occurrence of the problem.
+	Detail string
+	// Instance identifies the specific occurrence of the problem.
+	Instance string
+	// Status repeats the HTTP status code for convenience.
+	Status int
+	// Extensions holds any additional, problem-type-specific members.
+	Extensions map[string]json.RawMessage
+	// RetryAfter is parsed from the response's Retry-After header, if present.
+	RetryAfter time.Duration
+
+	err *Error
+}
+
+func (p *ProblemError) Error() string {
+	if p.Detail != "" {
+		return p.Detail
+	}
+	if p.Title != "" {
+		return p.Title
+	}
+	return p.err.Error()
+}
+
+// Unwrap exposes the mapped Error so existing errors.As(err, &Error{}) callers still work.
+func (p *ProblemError) Unwrap() error { return p.err }
+
+// UnmarshalJSON populates the well-known RFC 7807 members and collects everything else into
+// Extensions.
+func (p *ProblemError) UnmarshalJSON(b []byte) error {
+	var known struct {
+		Type     string `json:"type"`
+		Title    string `json:"title"`
+		Detail   string `json:"detail"`
+		Instance string `json:"instance"`
+		Status   int    `json:"status"`
+	}
+	if err := json.Unmarshal(b, &known); err != nil {
+		return err
+	}
+
+	extensions := map[string]json.RawMessage{}
+	if err := json.Unmarshal(b, &extensions); err != nil {
+		return err
+	}
+	for _, name := range []string{"type", "title", "detail", "instance", "status"} {
+		delete(extensions, name)
+	}
+
+	p.Type = known.Type
+	p.Title = known.Title
+	p.Detail = known.Detail
+	p.Instance = known.Instance
+	p.Status = known.Status
+	p.Extensions = extensions
+	return nil
+}
+
+// errorTypeFromProblem maps a problem's `type` URI back to the existing ErrorType constants,
+// falling back to ErrUnexpected for problem types this client doesn't recognize.
+func errorTypeFromProblem(typeURI string) ErrorType {
+	t := ErrorType(strings.TrimPrefix(typeURI, problemTypePrefix))
+	if problemErrorTypes[t] {
+		return t
+	}
+	return ErrUnexpected
+}
+
+// unmarshalProblem decodes body as an RFC 7807 problem, returning nil if it cannot be parsed so the
+// caller can fall back to the classic {"error": "..."} handling.
+func unmarshalProblem(resp *http.Response, body []byte) *ProblemError {
+	p := &ProblemError{}
+	if err := json.Unmarshal(body, p); err != nil {
+		return nil
+	}
+
+	p.RetryAfter = retryAfter(resp.Header.Get("Retry-After"))
+	p.err = &Error{
+		Type:       errorTypeFromProblem(p.Type),
+		Message:    p.Detail,
+		RetryAfter: p.RetryAfter,
+	}
+	if p.err.Message == "" {
+		p.err.Message = p.Title
+	}
+
+	return p
+}
@@ -0,0 +1,216 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DeltaType describes how an experiment's cached state changed.
+type DeltaType string
+
+const (
+	Added   DeltaType = "Added"
+	Updated DeltaType = "Updated"
+	Deleted DeltaType = "Deleted"
+	Synced  DeltaType = "Synced"
+)
+
+// ExperimentDelta is a single change to be applied to an ExperimentStore, keyed by the experiment's
+// self link.
+type ExperimentDelta struct {
+	Type       DeltaType
+	Experiment ExperimentItem
+}
+
+// ExperimentEventHandler is notified by an ExperimentStore every time a delta is applied to it.
+type ExperimentEventHandler interface {
+	OnExperimentChanged(delta ExperimentDelta)
+}
+
+// ExperimentStore is a thread-safe, indexed cache of experiments, keyed by their self link, kept up
+// to date by a Reflector. Sharing one ExperimentStore across reconcile loops means only the
+// Reflector's own list calls hit the API server; reconcilers read the cache instead of each issuing
+// their own GetAllExperiments.
+type ExperimentStore struct {
+	mu       sync.RWMutex
+	items    map[string]ExperimentItem
+	handlers []ExperimentEventHandler
+}
+
+// NewExperimentStore returns an empty ExperimentStore.
+func NewExperimentStore() *ExperimentStore {
+	return &ExperimentStore{items: make(map[string]ExperimentItem)}
+}
+
+// AddEventHandler registers h to be called, in order of registration, for every delta applied to
+// the store from this point on.
+func (s *ExperimentStore) AddEventHandler(h ExperimentEventHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers = append(s.handlers, h)
+}
+
+// List returns a snapshot of every experiment currently in the store.
+func (s *ExperimentStore) List() []ExperimentItem {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	items := make([]ExperimentItem, 0, len(s.items))
+	for _, item := range s.items {
+		items = append(items, item)
+	}
+	return items
+}
+
+// Get returns the cached state of the experiment with the given self link.
+func (s *ExperimentStore) Get(self string) (ExperimentItem, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	item, ok := s.items[self]
+	return item, ok
+}
+
+// apply updates the indexed state and fans the delta out to every registered handler. Handlers are
+// invoked while holding the write lock, matching the single-goroutine-applies-deltas model used by
+// the Reflector: handlers always see a consistent store and never race a concurrent list.
+func (s *ExperimentStore) apply(d ExperimentDelta) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch d.Type {
+	case Deleted:
+		delete(s.items, d.Experiment.Self)
+	default:
+		s.items[d.Experiment.Self] = d.Experiment
+	}
+
+	for _, h := range s.handlers {
+		h.OnExperimentChanged(d)
+	}
+}
+
+// Reflector periodically lists every experiment and replays the difference from the last list into
+// an ExperimentStore as a queue of deltas, modeled on client-go's ListWatch+DeltaFIFO+Store: a
+// single goroutine pops queued deltas and applies them, so the store and its handlers never race the
+// Reflector's own list calls.
+type Reflector struct {
+	api    API
+	store  *ExperimentStore
+	resync time.Duration
+
+	queue chan ExperimentDelta
+}
+
+// NewReflector returns a Reflector that keeps store in sync with the experiments visible to api,
+// re-listing every resync (defaulting to 30 seconds if resync is zero or negative).
+func NewReflector(api API, store *ExperimentStore, resync time.Duration) *Reflector {
+	if resync <= 0 {
+		resync = 30 * time.Second
+	}
+
+	return &Reflector{
+		api:    api,
+		store:  store,
+		resync: resync,
+		queue:  make(chan ExperimentDelta, 64),
+	}
+}
+
+// Run lists experiments every resync period, applying the deltas to the store, until ctx is done.
+func (r *Reflector) Run(ctx context.Context) error {
+	go r.pump(ctx)
+
+	if err := r.list(ctx); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(r.resync)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			// A failed list just leaves the store as-is until the next tick; the API client's own
+			// retry/backoff transport handles transient failures.
+			_ = r.list(ctx)
+		}
+	}
+}
+
+// pump is the single goroutine that drains the DeltaFIFO and applies each delta to the store.
+func (r *Reflector) pump(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case d := <-r.queue:
+			r.store.apply(d)
+		}
+	}
+}
+
+// list walks every page of GetAllExperiments, enqueuing an Added/Updated delta for each experiment
+// seen and a Deleted delta for anything previously in the store that the list no longer contains.
+func (r *Reflector) list(ctx context.Context) error {
+	seen := make(map[string]bool)
+
+	lst, err := r.api.GetAllExperiments(ctx, nil)
+	for {
+		if err != nil {
+			return err
+		}
+
+		for _, item := range lst.Experiments {
+			seen[item.Self] = true
+			r.enqueue(ctx, item)
+		}
+
+		if lst.Next == "" {
+			break
+		}
+		lst, err = r.api.GetAllExperimentsByPage(ctx, lst.Next)
+	}
+
+	for _, existing := range r.store.List() {
+		if !seen[existing.Self] {
+			r.enqueueDelta(ctx, ExperimentDelta{Type: Deleted, Experiment: existing})
+		}
+	}
+
+	return nil
+}
+
+// enqueue determines whether item is new or already cached and enqueues the corresponding delta.
+func (r *Reflector) enqueue(ctx context.Context, item ExperimentItem) {
+	t := Added
+	if _, ok := r.store.Get(item.Self); ok {
+		t = Updated
+	}
+	r.enqueueDelta(ctx, ExperimentDelta{Type: t, Experiment: item})
+}
+
+func (r *Reflector) enqueueDelta(ctx context.Context, d ExperimentDelta) {
+	select {
+	case r.queue <- d:
+	case <-ctx.Done():
+	}
+}
@@ -0,0 +1,230 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryTransport retries requests that fail with a retryable status code or a network error,
+// honoring the `Retry-After` header on 429/503 responses and applying a capped exponential backoff
+// with jitter for everything else. The base transport is only ever consulted for idempotent
+// methods, unless the request is explicitly allowed via ShouldRetry (e.g. the `NextTrial` POST,
+// which is the primary long-poll case for this client). Optionally, a URLBackoff proactively delays
+// requests to URLs with recent failures and a ClientRateLimiter bounds overall concurrency, both
+// applied before every attempt (including the first).
+type RetryTransport struct {
+	// Base transport to use, uses the system default if nil
+	Base http.RoundTripper
+	// ShouldRetry overrides the default idempotent-methods-only policy, return true to allow a
+	// non-idempotent request (e.g. POST) to be retried
+	ShouldRetry func(req *http.Request) bool
+	// MaxAttempts caps the number of times a request will be attempted, defaults to 5
+	MaxAttempts int
+	// MaxElapsedTime caps the total time spent retrying a single request, defaults to 2 minutes
+	MaxElapsedTime time.Duration
+	// URLBackoff, if set, is consulted before every attempt and updated with the outcome of every
+	// response, so a URL with a history of 429/5xx responses is proactively slowed down even for
+	// requests made by other callers.
+	URLBackoff *URLBackoff
+	// RateLimiter, if set, is given a chance to block before every attempt, independent of
+	// URLBackoff, so callers can bound overall request concurrency (e.g. with a token bucket).
+	RateLimiter ClientRateLimiter
+}
+
+// RoundTrip attempts the request, retrying on a 429/503 (honoring `Retry-After`) or a 502/504/
+// network error using a capped exponential backoff with jitter, up to the configured attempt and
+// elapsed time budgets. It gives up early if the request's context is done.
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	var lastResp *http.Response
+	var lastErr error
+
+	for attempt := 0; attempt < t.maxAttempts(); attempt++ {
+		if attempt > 0 {
+			// The previous attempt's RoundTrip already consumed req.Body; a body-carrying
+			// method (e.g. PUT) must get a fresh, unread copy or the retry goes out empty.
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return lastResp, err
+				}
+				req.Body = body
+			}
+
+			wait := t.backoff(lastResp, attempt)
+
+			timer := time.NewTimer(wait)
+			select {
+			case <-req.Context().Done():
+				timer.Stop()
+				return nil, req.Context().Err()
+			case <-timer.C:
+			}
+		}
+
+		if time.Since(start) > t.maxElapsedTime() {
+			break
+		}
+
+		if err := t.throttle(req); err != nil {
+			return lastResp, err
+		}
+
+		resp, err := t.base().RoundTrip(req)
+		lastResp, lastErr = resp, err
+
+		retry := t.retryable(req, resp, err)
+		if t.URLBackoff != nil {
+			t.URLBackoff.Observe(req, failed(resp, err))
+		}
+		if !retry {
+			return resp, err
+		}
+
+		// Drain and close the response body so the connection can be reused before retrying
+		if resp != nil {
+			_, _ = io.Copy(ioutil.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+	}
+
+	return lastResp, lastErr
+}
+
+// throttle blocks, before an attempt is made, on the configured RateLimiter and URLBackoff (in that
+// order), returning early if req's context is done first.
+func (t *RetryTransport) throttle(req *http.Request) error {
+	if t.RateLimiter != nil {
+		if err := t.RateLimiter.Wait(req.Context()); err != nil {
+			return err
+		}
+	}
+	if t.URLBackoff != nil {
+		if err := t.URLBackoff.Wait(req.Context(), req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// retryable determines if the supplied response or error warrants another attempt.
+func (t *RetryTransport) retryable(req *http.Request, resp *http.Response, err error) bool {
+	if !t.allowedMethod(req) {
+		return false
+	}
+
+	if err != nil {
+		return true
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// failed reports whether resp/err indicates the URL itself is unhealthy, independent of whether
+// this particular request is even eligible for RetryTransport's own retry. retryable (and the
+// allowedMethod check it applies) decides what this client is willing to retry; URLBackoff tracks
+// what actually went wrong, so a real 5xx response or failed non-idempotent request still counts
+// against the URL instead of being recorded as a success.
+func failed(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	if resp == nil {
+		return false
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+}
+
+// allowedMethod reports whether the request's method may be retried: idempotent methods are
+// always allowed, everything else requires an explicit ShouldRetry override.
+func (t *RetryTransport) allowedMethod(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return t.ShouldRetry != nil && t.ShouldRetry(req)
+	}
+}
+
+// backoff determines how long to wait before the next attempt: the `Retry-After` header is honored
+// verbatim when present on the previous response, otherwise a capped exponential backoff with full
+// jitter is used.
+func (t *RetryTransport) backoff(resp *http.Response, attempt int) time.Duration {
+	if resp != nil && (resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable) {
+		if ra := retryAfter(resp.Header.Get("Retry-After")); ra > 0 {
+			return ra
+		}
+	}
+
+	const (
+		base = 500 * time.Millisecond
+		cap  = 30 * time.Second
+	)
+
+	d := base << uint(attempt-1)
+	if d <= 0 || d > cap {
+		d = cap
+	}
+	return time.Duration(rand.Int63n(int64(d)))
+}
+
+// retryAfter parses the `Retry-After` header in either its delta-seconds or HTTP-date form,
+// returning zero if the header is absent or unparseable.
+func retryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(value); err == nil {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
+
+func (t *RetryTransport) maxAttempts() int {
+	if t.MaxAttempts > 0 {
+		return t.MaxAttempts
+	}
+	return 5
+}
+
+func (t *RetryTransport) maxElapsedTime() time.Duration {
+	if t.MaxElapsedTime > 0 {
+		return t.MaxElapsedTime
+	}
+	return 2 * time.Minute
+}
+
+func (t *RetryTransport) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+	return http.DefaultTransport
+}
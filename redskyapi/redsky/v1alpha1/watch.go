@@ -0,0 +1,308 @@
+/*
+Copyright 2021 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// TrialEventType enumerates the kinds of changes WatchTrials can observe for a single trial.
+type TrialEventType string
+
+const (
+	TrialEventAdded     TrialEventType = "Added"
+	TrialEventModified  TrialEventType = "Modified"
+	TrialEventCompleted TrialEventType = "Completed"
+	TrialEventFailed    TrialEventType = "Failed"
+	TrialEventError     TrialEventType = "Error"
+)
+
+// TrialEvent is a single observation emitted by WatchTrials.
+type TrialEvent struct {
+	// Type is the kind of change being reported.
+	Type TrialEventType
+	// Trial is the trial the event pertains to, unset for a TrialEventError event.
+	Trial TrialItem
+	// Err explains why the watch ended, only set when Type is TrialEventError.
+	Err error
+}
+
+// WatchOptions controls the behavior of WatchTrials.
+type WatchOptions struct {
+	// PollInterval is the delay between polls when the server does not support streaming watches.
+	// Defaults to 5 seconds.
+	PollInterval time.Duration
+	// MaxMessageSize bounds how large a single line of the streamed response is allowed to be.
+	// Defaults to bufio.MaxScanTokenSize (64KB); raise this if the server (or a proxy in front of
+	// it) batches more than that into one event.
+	MaxMessageSize int
+}
+
+func (o *WatchOptions) pollInterval() time.Duration {
+	if o.PollInterval > 0 {
+		return o.PollInterval
+	}
+	return 5 * time.Second
+}
+
+func (o *WatchOptions) maxMessageSize() int {
+	if o.MaxMessageSize > 0 {
+		return o.MaxMessageSize
+	}
+	return bufio.MaxScanTokenSize
+}
+
+// WatchTrials returns a channel streaming the trials of an experiment as they are added, updated,
+// and completed, so callers no longer need to poll GetAllTrials on a timer. A snapshot is fetched
+// up front (emitted as a burst of events before anything else arrives), after which the stream is
+// served by a long-held request against the trials endpoint, falling back to polling GetAllTrials
+// if the server does not support streaming. The channel is closed once ctx is done; if the watch
+// ends because the experiment was stopped or a hard server error was encountered, a final
+// TrialEventError is sent first.
+func (h *httpAPI) WatchTrials(ctx context.Context, u string, opts WatchOptions) (<-chan TrialEvent, error) {
+	lst, err := h.GetAllTrials(ctx, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &trialWatcher{
+		api:  h,
+		url:  u,
+		opts: opts,
+		ch:   make(chan TrialEvent),
+		seen: make(map[int64]TrialStatus, len(lst.Trials)),
+	}
+
+	go w.run(ctx, lst)
+
+	return w.ch, nil
+}
+
+// watchAction tells trialWatcher.run how to proceed after a single attempt to hold (or poll) the
+// trials stream.
+type watchAction int
+
+const (
+	watchContinue watchAction = iota // reconnect (or poll again) immediately
+	watchFallback                    // the server doesn't support streaming, switch to polling
+	watchStop                        // the channel has been closed/sent a final event, stop entirely
+)
+
+// trialWatcher drives a single WatchTrials stream. since is the resume cursor (the highest trial
+// number observed so far, sent back to the server as `since=`) and seen records the last reported
+// status of every trial, so both the streaming and polling paths can tell an Added from a Modified
+// from a terminal Completed/Failed without re-reporting a trial that hasn't changed.
+type trialWatcher struct {
+	api  *httpAPI
+	url  string
+	opts WatchOptions
+
+	ch    chan TrialEvent
+	since int64
+	seen  map[int64]TrialStatus
+}
+
+func (w *trialWatcher) run(ctx context.Context, initial TrialList) {
+	defer close(w.ch)
+
+	for _, t := range initial.Trials {
+		if !w.emit(ctx, t) {
+			return
+		}
+	}
+
+	streaming := true
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		if streaming {
+			switch w.watchOnce(ctx) {
+			case watchStop:
+				return
+			case watchFallback:
+				streaming = false
+			case watchContinue:
+			}
+			continue
+		}
+
+		if !w.pollOnce(ctx) {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(w.opts.pollInterval()):
+		}
+	}
+}
+
+// watchOnce holds a single long-lived request against the trials endpoint with watch=1 and the
+// current resume cursor, consuming newline-delimited TrialItem events as they arrive.
+func (w *trialWatcher) watchOnce(ctx context.Context) watchAction {
+	uu, err := url.Parse(w.url)
+	if err != nil {
+		w.ch <- TrialEvent{Type: TrialEventError, Err: err}
+		return watchStop
+	}
+
+	q := uu.Query()
+	q.Set("watch", "1")
+	if w.since > 0 {
+		q.Set("since", strconv.FormatInt(w.since, 10))
+	}
+	uu.RawQuery = q.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, uu.String(), nil)
+	if err != nil {
+		w.ch <- TrialEvent{Type: TrialEventError, Err: err}
+		return watchStop
+	}
+
+	resp, body, err := w.api.client.Do(ctx, req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return watchStop
+		}
+		// Transient network error: back off briefly and reconnect with the last-seen number.
+		if !sleep(ctx, time.Second) {
+			return watchStop
+		}
+		return watchContinue
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return w.consume(ctx, body)
+	case http.StatusNotImplemented, http.StatusMethodNotAllowed, http.StatusNotFound:
+		return watchFallback
+	case http.StatusServiceUnavailable:
+		ra := retryAfter(resp.Header.Get("Retry-After"))
+		if ra <= 0 {
+			ra = 5 * time.Second
+		}
+		if !sleep(ctx, ra) {
+			return watchStop
+		}
+		return watchContinue
+	case http.StatusGone:
+		w.ch <- TrialEvent{Type: TrialEventError, Err: &Error{Type: ErrExperimentStopped}}
+		return watchStop
+	default:
+		w.ch <- TrialEvent{Type: TrialEventError, Err: unexpected(resp, body)}
+		return watchStop
+	}
+}
+
+// consume parses body as newline-delimited TrialItem events, emitting one TrialEvent per line.
+// Malformed lines are skipped rather than aborting an otherwise healthy stream. The scan buffer is
+// sized from WatchOptions.MaxMessageSize so a server (or proxy) that batches more than the default
+// 64KB into one line doesn't just silently truncate the watch.
+func (w *trialWatcher) consume(ctx context.Context, body []byte) watchAction {
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	scanner.Buffer(make([]byte, 0, 4096), w.opts.maxMessageSize())
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var item TrialItem
+		if err := json.Unmarshal(line, &item); err != nil {
+			continue
+		}
+
+		if !w.emit(ctx, item) {
+			return watchStop
+		}
+	}
+	return watchContinue
+}
+
+// pollOnce fetches the current trial list and emits an event for anything new or changed since the
+// last poll, used when the server doesn't support streaming watches.
+func (w *trialWatcher) pollOnce(ctx context.Context) bool {
+	lst, err := w.api.GetAllTrials(ctx, w.url, nil)
+	if err != nil {
+		// Transient polling error, try again on the next tick.
+		return true
+	}
+
+	for _, t := range lst.Trials {
+		if status, ok := w.seen[t.Number]; ok && status == t.Status {
+			continue
+		}
+		if !w.emit(ctx, t) {
+			return false
+		}
+	}
+	return true
+}
+
+// emit records t as seen and sends the corresponding event, returning false if ctx is done first.
+func (w *trialWatcher) emit(ctx context.Context, t TrialItem) bool {
+	evt := TrialEvent{Trial: t}
+	switch {
+	case t.Status == TrialCompleted:
+		evt.Type = TrialEventCompleted
+	case t.Status == TrialFailed:
+		evt.Type = TrialEventFailed
+	default:
+		if _, ok := w.seen[t.Number]; ok {
+			evt.Type = TrialEventModified
+		} else {
+			evt.Type = TrialEventAdded
+		}
+	}
+
+	w.seen[t.Number] = t.Status
+	if t.Number > w.since {
+		w.since = t.Number
+	}
+
+	select {
+	case w.ch <- evt:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// sleep waits for d, returning false if ctx is done first.
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -124,6 +124,7 @@ func ConfigGlobals(cfg *internalconfig.RedSkyConfig, cmd *cobra.Command) {
 	root.PersistentFlags().StringVar(&cfg.Overrides.Context, "context", "", "the `name` of the redskyconfig context to use, NOT THE KUBE CONTEXT")
 	root.PersistentFlags().StringVar(&cfg.Overrides.KubeConfig, "kubeconfig", "", "path to the kubeconfig `file` to use for CLI requests")
 	root.PersistentFlags().StringVarP(&cfg.Overrides.Namespace, "namespace", "n", "", "if present, the namespace scope for this CLI request")
+	root.PersistentFlags().BoolVar(&cfg.Overrides.NoDiscovery, "no-discovery", cfg.Overrides.NoDiscovery, "disable RFC 8414 authorization server metadata discovery")
 
 	_ = root.MarkFlagFilename("redskyconfig")
 	_ = root.MarkFlagFilename("kubeconfig")
@@ -38,6 +38,7 @@ func NewCommand(o *Options) *cobra.Command {
 	cmd.AddCommand(NewConfigCommand(&ConfigOptions{Config: o.Config}))
 	cmd.AddCommand(NewExperimentCommand(&ExperimentOptions{}))
 	cmd.AddCommand(NewServerCommand(&ServerOptions{Config: o.Config}))
+	cmd.AddCommand(NewTrialCommand(&TrialOptions{}))
 	cmd.AddCommand(NewVersionCommand(&VersionOptions{}))
 
 	// TODO Add a controller check?
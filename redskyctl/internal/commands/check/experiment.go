@@ -26,6 +26,8 @@ import (
 	"github.com/thestormforge/optimize-controller/redskyctl/internal/commander"
 	batchv1 "k8s.io/api/batch/v1"
 	"k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -38,6 +40,7 @@ type ExperimentOptions struct {
 	commander.IOStreams
 
 	Filename string
+	Output   string
 }
 
 // NewExperimentCommand creates a new command for checking an experiment manifest
@@ -52,10 +55,12 @@ func NewExperimentCommand(o *ExperimentOptions) *cobra.Command {
 	}
 
 	cmd.Flags().StringVarP(&o.Filename, "filename", "f", "", "file that contains the experiment to check")
+	cmd.Flags().StringVar(&o.Output, "output", "text", "output format: one of text|json|sarif")
 
 	_ = cmd.MarkFlagFilename("filename", "yml", "yaml")
 	_ = cmd.MarkFlagRequired("filename")
 
+	commander.ExitOnError(cmd)
 	return cmd
 }
 
@@ -77,12 +82,11 @@ func (o *ExperimentOptions) checkExperiment() error {
 	checkExperiment(linter.For("experiment"), experiment)
 
 	// Share the results
-	// TODO Filter/sort?
-	for _, p := range linter.Problems {
-		_, _ = fmt.Fprintln(o.Out, p.Message)
+	if err := WriteReport(o.Out, ReportFormat(o.Output), o.Filename, linter.Problems); err != nil {
+		return err
 	}
 
-	return nil
+	return errorOnLintFailures(linter.Problems)
 }
 
 func checkExperiment(lint Linter, experiment *redskyv1beta1.Experiment) {
@@ -123,12 +127,21 @@ func checkParameters(lint Linter, parameters []redskyv1beta1.Parameter) {
 		lint.Error().Missing("parameters")
 	}
 
+	names := make(map[string]int, len(parameters))
+
 	var baseline int
 	for i := range parameters {
 		checkParameter(lint.For(i), &parameters[i])
 		if parameters[i].Baseline != nil {
 			baseline++
 		}
+		names[parameters[i].Name]++
+	}
+
+	for name, count := range names {
+		if name != "" && count > 1 {
+			lint.Error().Invalid("name", name, "<unique parameter name>")
+		}
 	}
 
 	if baseline > 0 && baseline != len(parameters) {
@@ -139,6 +152,28 @@ func checkParameters(lint Linter, parameters []redskyv1beta1.Parameter) {
 
 func checkParameter(lint Linter, parameter *redskyv1beta1.Parameter) {
 
+	if parameter.Name == "" {
+		lint.Error().Missing("name")
+	}
+
+	if len(parameter.Values) > 0 {
+		if parameter.Min != 0 || parameter.Max != 0 {
+			lint.Error().Invalid("min/max", fmt.Sprintf("%d/%d", parameter.Min, parameter.Max), "<unset for a categorical parameter>")
+		}
+
+		seen := make(map[string]bool, len(parameter.Values))
+		for _, v := range parameter.Values {
+			if seen[v] {
+				lint.Warning().Invalid("values", v, "<unique value>")
+			}
+			seen[v] = true
+		}
+	} else if parameter.Min > parameter.Max {
+		lint.Error().Invalid("min/max", fmt.Sprintf("%d/%d", parameter.Min, parameter.Max), "min <= max")
+	} else if parameter.Min == parameter.Max {
+		lint.Warning().Invalid("min/max", fmt.Sprintf("%d/%d", parameter.Min, parameter.Max), "<a range, not a fixed value>")
+	}
+
 	if parameter.Baseline != nil {
 		if parameter.Baseline.Type == intstr.String {
 			if parameter.Min > 0 || parameter.Max > 0 {
@@ -199,16 +234,70 @@ func checkMetric(lint Linter, metric *redskyv1beta1.Metric) {
 		}
 	}
 
+	if metric.Type == redskyv1beta1.MetricPrometheus && !bracketsBalanced(metric.Query) {
+		lint.Error().Invalid("query", metric.Query, "<balanced PromQL expression>")
+	}
+
+	if metric.Type == redskyv1beta1.MetricDatadog && metric.Query != "" && !strings.Contains(metric.Query, ":") {
+		lint.Error().Invalid("query", metric.Query, "<aggregator>:<metric name>{<scope>}")
+	}
+
 	if metric.Scheme != "" && strings.ToLower(metric.Scheme) == "http" && strings.ToLower(metric.Scheme) != "https" {
 		lint.Error().Invalid("scheme", metric.Scheme, "http", "https")
 	}
 
-	if _, _, err := template.New().RenderMetricQueries(metric, &redskyv1beta1.Trial{}, nil); err != nil {
+	if _, _, err := template.New().RenderMetricQueries(metric, &redskyv1beta1.Trial{}, syntheticPods()); err != nil {
 		lint.Error().Failed("query", err)
 	}
 
 }
 
+// bracketsBalanced does a lightweight syntax check on a PromQL expression, without pulling in a
+// full PromQL parser as a dependency: it just confirms parens, brackets, and braces are balanced.
+func bracketsBalanced(query string) bool {
+	pairs := map[rune]rune{')': '(', ']': '[', '}': '{'}
+
+	var stack []rune
+	for _, r := range query {
+		switch r {
+		case '(', '[', '{':
+			stack = append(stack, r)
+		case ')', ']', '}':
+			if len(stack) == 0 || stack[len(stack)-1] != pairs[r] {
+				return false
+			}
+			stack = stack[:len(stack)-1]
+		}
+	}
+	return len(stack) == 0
+}
+
+// syntheticPods is a minimal pod list used to render metric and patch templates so that
+// pod-dependent template functions (e.g. resourceRequests) are exercised during a check instead
+// of being skipped because no real pod is available.
+func syntheticPods() *corev1.PodList {
+	return &corev1.PodList{
+		Items: []corev1.Pod{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "synthetic", Namespace: "default"},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name: "synthetic",
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{
+									corev1.ResourceCPU:    resource.MustParse("100m"),
+									corev1.ResourceMemory: resource.MustParse("100Mi"),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
 func checkPatches(lint Linter, patches []redskyv1beta1.PatchTemplate) {
 
 	if len(patches) == 0 {
@@ -0,0 +1,170 @@
+/*
+Copyright 2020 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package check
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// ReportFormat selects how a linter's problems are rendered to the user.
+type ReportFormat string
+
+const (
+	// ReportText renders one line per problem, suitable for a terminal.
+	ReportText ReportFormat = "text"
+	// ReportJSON renders the raw list of LintError as JSON.
+	ReportJSON ReportFormat = "json"
+	// ReportSARIF renders a minimal SARIF 2.1.0 log, for tools (e.g. CI annotations) that
+	// understand the format.
+	ReportSARIF ReportFormat = "sarif"
+)
+
+// WriteReport renders problems to out in the requested format. source identifies the file the
+// problems were found in (e.g. the "-f" flag value); it is only used by the SARIF report.
+func WriteReport(out io.Writer, format ReportFormat, source string, problems []LintError) error {
+	switch format {
+	case ReportJSON:
+		return writeJSONReport(out, problems)
+	case ReportSARIF:
+		return writeSARIFReport(out, source, problems)
+	default:
+		return writeTextReport(out, problems)
+	}
+}
+
+func writeTextReport(out io.Writer, problems []LintError) error {
+	for _, p := range problems {
+		if _, err := fmt.Fprintf(out, "%s: %s: %s\n", severityName(p.Severity), p.Path, p.Message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeJSONReport(out io.Writer, problems []LintError) error {
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(problems)
+}
+
+func severityName(s int) string {
+	switch s {
+	case 0:
+		return "error"
+	case 1:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// errorOnLintFailures returns a non-nil error if problems contains an error severity (0) entry,
+// so a check command's exit code reflects whether it actually found a problem.
+func errorOnLintFailures(problems []LintError) error {
+	var errCount int
+	for _, p := range problems {
+		if p.Severity == 0 {
+			errCount++
+		}
+	}
+	if errCount > 0 {
+		return fmt.Errorf("%d error(s) found", errCount)
+	}
+	return nil
+}
+
+// sarifSchema is the published schema URI for the SARIF version we emit.
+const sarifSchema = "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/master/Schemata/sarif-schema-2.1.0.json"
+
+// sarifLog is a minimal SARIF 2.1.0 log: one run, our problems as results. We only know the
+// dotted path to a problem (not a line/column in the source file), so results use a logical
+// location rather than a physical one.
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string `json:"name"`
+	InformationURI string `json:"informationUri,omitempty"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+}
+
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+}
+
+func writeSARIFReport(out io.Writer, source string, problems []LintError) error {
+	log := sarifLog{
+		Schema:  sarifSchema,
+		Version: "2.1.0",
+		Runs: []sarifRun{
+			{
+				Tool: sarifTool{Driver: sarifDriver{
+					Name:           "redskyctl-check",
+					InformationURI: "https://stormforge.io",
+				}},
+				Results: make([]sarifResult, 0, len(problems)),
+			},
+		},
+	}
+
+	for _, p := range problems {
+		path := p.Path
+		if source != "" {
+			path = source + ":" + path
+		}
+		log.Runs[0].Results = append(log.Runs[0].Results, sarifResult{
+			RuleID:  severityName(p.Severity),
+			Level:   severityName(p.Severity),
+			Message: sarifMessage{Text: p.Message},
+			Locations: []sarifLocation{
+				{LogicalLocations: []sarifLogicalLocation{{FullyQualifiedName: path}}},
+			},
+		})
+	}
+
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(log)
+}
@@ -23,6 +23,7 @@ import (
 	"io"
 	"math/rand"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/redskyops/redskyops-controller/internal/config"
@@ -40,12 +41,16 @@ type ServerOptions struct {
 	// IOStreams are used to access the standard process streams
 	commander.IOStreams
 
-	Name           string
-	ParameterCount int
-	MetricCount    int
-	AllowInvalid   bool
-	ReportFailure  bool
-	DryRun         bool
+	Name            string
+	ParameterCount  int
+	MetricCount     int
+	ParameterTypes  []string
+	ConstraintCount int
+	AllowInvalid    bool
+	ReportFailure   bool
+	DryRun          bool
+
+	parameterTypes []experimentsv1alpha1.ParameterType
 }
 
 // NewServerCommand creates a new command for checking the Red Sky API server
@@ -75,6 +80,8 @@ func NewServerCommand(o *ServerOptions) *cobra.Command {
 
 	cmd.Flags().IntVar(&o.ParameterCount, "parameters", o.ParameterCount, "Specify the number of experiment parameters to generate (1 - 20).")
 	cmd.Flags().IntVar(&o.MetricCount, "metrics", o.MetricCount, "Specify the number of experiment metrics to generate (1 or 2).")
+	cmd.Flags().StringSliceVar(&o.ParameterTypes, "parameter-types", []string{"int"}, "Specify the parameter types to generate from: int, float, categorical.")
+	cmd.Flags().IntVar(&o.ConstraintCount, "constraints", o.ConstraintCount, "Specify the number of order/sum constraints to generate.")
 	cmd.Flags().BoolVar(&o.AllowInvalid, "invalid", o.AllowInvalid, "Skip client side validity checks (server enforcement).")
 	cmd.Flags().BoolVar(&o.ReportFailure, "fail", o.ReportFailure, "Report an experiment failure instead of generated values.")
 	cmd.Flags().BoolVar(&o.DryRun, "dry-run", o.DryRun, "Generate experiment JSON to stdout.")
@@ -91,6 +98,15 @@ func (o *ServerOptions) Complete() error {
 	if o.MetricCount == 0 {
 		o.MetricCount = 1
 	}
+	if len(o.ParameterTypes) == 0 {
+		o.ParameterTypes = []string{"int"}
+	}
+
+	parameterTypes, err := parseParameterTypes(o.ParameterTypes)
+	if err != nil {
+		return err
+	}
+	o.parameterTypes = parameterTypes
 
 	if !o.AllowInvalid {
 		if o.ParameterCount < 1 || o.ParameterCount > 20 {
@@ -99,10 +115,32 @@ func (o *ServerOptions) Complete() error {
 		if o.MetricCount < 1 || o.MetricCount > 2 {
 			return fmt.Errorf("invalid metric count: %d (should be [1,2]", o.MetricCount)
 		}
+		if o.ConstraintCount < 0 {
+			return fmt.Errorf("invalid constraint count: %d (should be >= 0)", o.ConstraintCount)
+		}
 	}
 	return nil
 }
 
+// parseParameterTypes converts the "--parameter-types" flag values into the corresponding
+// experiment API parameter types.
+func parseParameterTypes(types []string) ([]experimentsv1alpha1.ParameterType, error) {
+	parsed := make([]experimentsv1alpha1.ParameterType, 0, len(types))
+	for _, t := range types {
+		switch strings.ToLower(strings.TrimSpace(t)) {
+		case "int", "integer":
+			parsed = append(parsed, experimentsv1alpha1.ParameterTypeInteger)
+		case "float", "double":
+			parsed = append(parsed, experimentsv1alpha1.ParameterTypeDouble)
+		case "categorical":
+			parsed = append(parsed, experimentsv1alpha1.ParameterTypeCategorical)
+		default:
+			return nil, fmt.Errorf("unknown parameter type: %s (should be one of int, float, categorical)", t)
+		}
+	}
+	return parsed, nil
+}
+
 func (o *ServerOptions) checkServer() error {
 	var err error
 
@@ -205,11 +243,21 @@ func generateExperiment(o *ServerOptions) *experimentsv1alpha1.Experiment {
 	used := make(map[string]bool, o.ParameterCount+o.MetricCount)
 
 	for i := 0; i < o.ParameterCount; i++ {
-		e.Parameters = append(e.Parameters, experimentsv1alpha1.Parameter{
-			Name:   getUnique(used, getRandomParameter),
-			Type:   experimentsv1alpha1.ParameterTypeInteger,
-			Bounds: *generateBounds(),
-		})
+		p := experimentsv1alpha1.Parameter{
+			Name: getUnique(used, getRandomParameter),
+			Type: o.parameterTypes[rand.Intn(len(o.parameterTypes))],
+		}
+
+		switch p.Type {
+		case experimentsv1alpha1.ParameterTypeDouble:
+			p.Bounds = generateFloatBounds()
+		case experimentsv1alpha1.ParameterTypeCategorical:
+			p.Values = generateValues()
+		default:
+			p.Bounds = generateBounds()
+		}
+
+		e.Parameters = append(e.Parameters, p)
 	}
 
 	for i := 0; i < o.MetricCount; i++ {
@@ -219,6 +267,8 @@ func generateExperiment(o *ServerOptions) *experimentsv1alpha1.Experiment {
 		})
 	}
 
+	e.Constraints = generateConstraints(o.ConstraintCount, e.Parameters)
+
 	return e
 }
 
@@ -250,6 +300,94 @@ func generateBounds() *experimentsv1alpha1.Bounds {
 	}
 }
 
+// generateFloatBounds is like generateBounds, but produces fractional bounds for a
+// ParameterTypeDouble parameter.
+func generateFloatBounds() *experimentsv1alpha1.Bounds {
+	var min, max float64
+	for min == max {
+		min, max = rand.Float64()*100, rand.Float64()*4000
+	}
+	if min > max {
+		min, max = max, min
+	}
+	return &experimentsv1alpha1.Bounds{
+		Min: json.Number(strconv.FormatFloat(min, 'f', -1, 64)),
+		Max: json.Number(strconv.FormatFloat(max, 'f', -1, 64)),
+	}
+}
+
+// generateValues produces a random 2-8 element list of unique string values for a
+// ParameterTypeCategorical parameter.
+func generateValues() []string {
+	n := rand.Intn(7) + 2
+	used := make(map[string]bool, n)
+	values := make([]string, 0, n)
+	for len(values) < n {
+		values = append(values, getUnique(used, getRandomParameter))
+	}
+	return values
+}
+
+// generateConstraints produces a random mix of order and sum constraints over the numeric
+// (non-categorical) parameters, for exercising the server's constraint handling.
+func generateConstraints(count int, parameters []experimentsv1alpha1.Parameter) []experimentsv1alpha1.Constraint {
+	var numeric []string
+	for _, p := range parameters {
+		if p.Type != experimentsv1alpha1.ParameterTypeCategorical {
+			numeric = append(numeric, p.Name)
+		}
+	}
+	if len(numeric) < 2 {
+		return nil
+	}
+
+	constraints := make([]experimentsv1alpha1.Constraint, 0, count)
+	for i := 0; i < count; i++ {
+		if rand.Intn(2) == 0 {
+			constraints = append(constraints, generateOrderConstraint(numeric))
+		} else {
+			constraints = append(constraints, generateSumConstraint(numeric))
+		}
+	}
+	return constraints
+}
+
+func generateOrderConstraint(numeric []string) experimentsv1alpha1.Constraint {
+	lower, upper := numeric[rand.Intn(len(numeric))], numeric[rand.Intn(len(numeric))]
+	for upper == lower {
+		upper = numeric[rand.Intn(len(numeric))]
+	}
+	return experimentsv1alpha1.Constraint{
+		ConstraintType: experimentsv1alpha1.ConstraintOrder,
+		OrderConstraint: experimentsv1alpha1.OrderConstraint{
+			LowerParameter: lower,
+			UpperParameter: upper,
+		},
+	}
+}
+
+func generateSumConstraint(numeric []string) experimentsv1alpha1.Constraint {
+	n := rand.Intn(len(numeric)-1) + 2
+	if n > len(numeric) {
+		n = len(numeric)
+	}
+	params := make([]experimentsv1alpha1.SumConstraintParameter, 0, n)
+	for _, name := range numeric[:n] {
+		params = append(params, experimentsv1alpha1.SumConstraintParameter{
+			Name:   name,
+			Weight: 1,
+		})
+	}
+	return experimentsv1alpha1.Constraint{
+		ConstraintType: experimentsv1alpha1.ConstraintSum,
+		SumConstraint: experimentsv1alpha1.SumConstraint{
+			IsUpperBound: true,
+			Bound:        float64(4000 * n),
+			Parameters:   params,
+		},
+	}
+}
+
 func generateMinimize() bool {
 	return rand.Intn(2) != 0
 }
@@ -281,13 +419,21 @@ func checkServerExperiment(name string, original, created *experimentsv1alpha1.E
 		params[original.Parameters[i].Name] = &original.Parameters[i]
 	}
 	for _, p := range created.Parameters {
-		if op, ok := params[p.Name]; ok {
-			if p.Bounds.Min != op.Bounds.Min || p.Bounds.Max != op.Bounds.Max {
-				return fmt.Errorf("server returned parameter with incorrect bounds: %s [%s,%s] (expected [%s,%s])", p.Name, p.Bounds.Min, p.Bounds.Min, op.Bounds.Min, op.Bounds.Max)
-			}
-		} else {
+		op, ok := params[p.Name]
+		if !ok {
 			return fmt.Errorf("server returned unexpected parameter: %s", p.Name)
 		}
+
+		if op.Type == experimentsv1alpha1.ParameterTypeCategorical {
+			if !stringSliceEqual(p.Values, op.Values) {
+				return fmt.Errorf("server returned parameter with incorrect values: %s %v (expected %v)", p.Name, p.Values, op.Values)
+			}
+			continue
+		}
+
+		if p.Bounds == nil || op.Bounds == nil || p.Bounds.Min != op.Bounds.Min || p.Bounds.Max != op.Bounds.Max {
+			return fmt.Errorf("server returned parameter with incorrect bounds: %s %s (expected %s)", p.Name, formatBounds(p.Bounds), formatBounds(op.Bounds))
+		}
 	}
 
 	if len(created.Metrics) != len(original.Metrics) {
@@ -322,28 +468,111 @@ func checkTrialAssignments(exp *experimentsv1alpha1.Experiment, t *experimentsv1
 	for i := range exp.Parameters {
 		params[exp.Parameters[i].Name] = &exp.Parameters[i]
 	}
+	assignments := make(map[string]experimentsv1alpha1.Assignment, len(t.Assignments))
 	for _, a := range t.Assignments {
-		if p, ok := params[a.ParameterName]; ok {
-			// Check bounds using floating point arithmetic
-			v, err := a.Value.Float64()
-			if err != nil {
-				return err
+		p, ok := params[a.ParameterName]
+		if !ok {
+			return fmt.Errorf("server returned unexpected assignment: %s", a.ParameterName)
+		}
+		assignments[a.ParameterName] = a
+
+		if p.Type == experimentsv1alpha1.ParameterTypeCategorical {
+			var allowed bool
+			for _, v := range p.Values {
+				if string(a.Value) == v {
+					allowed = true
+					break
+				}
+			}
+			if !allowed {
+				return fmt.Errorf("server returned out of range assignment: %s = %s (expected one of %v)", a.ParameterName, a.Value, p.Values)
 			}
-			min, err := p.Bounds.Min.Float64()
+			continue
+		}
+
+		// Check bounds using floating point arithmetic
+		v, err := a.Value.Float64()
+		if err != nil {
+			return err
+		}
+		min, err := p.Bounds.Min.Float64()
+		if err != nil {
+			return err
+		}
+		max, err := p.Bounds.Max.Float64()
+		if err != nil {
+			return err
+		}
+		if v < min || v > max {
+			return fmt.Errorf("server return out of bounds assignment: %s = %s (expected [%s,%s])", a.ParameterName, a.Value, p.Bounds.Min, p.Bounds.Max)
+		}
+	}
+
+	return checkConstraintAssignments(exp.Constraints, assignments)
+}
+
+// checkConstraintAssignments verifies that a set of assignments satisfies every declared order
+// and sum constraint.
+func checkConstraintAssignments(constraints []experimentsv1alpha1.Constraint, assignments map[string]experimentsv1alpha1.Assignment) error {
+	for _, c := range constraints {
+		switch c.ConstraintType {
+		case experimentsv1alpha1.ConstraintOrder:
+			lower, err := assignments[c.LowerParameter].Value.Float64()
 			if err != nil {
 				return err
 			}
-			max, err := p.Bounds.Max.Float64()
+			upper, err := assignments[c.UpperParameter].Value.Float64()
 			if err != nil {
 				return err
 			}
-			if v < min || v > max {
-				return fmt.Errorf("server return out of bounds assignment: %s = %s (expected [%s,%s])", a.ParameterName, a.Value, p.Bounds.Min, p.Bounds.Max)
+			if lower > upper {
+				return fmt.Errorf("server returned assignments violating order constraint: %s (%f) > %s (%f)", c.LowerParameter, lower, c.UpperParameter, upper)
+			}
+
+		case experimentsv1alpha1.ConstraintSum:
+			var sum float64
+			for _, p := range c.Parameters {
+				v, err := assignments[p.Name].Value.Float64()
+				if err != nil {
+					return err
+				}
+				sum += p.Weight * v
+			}
+			if c.IsUpperBound && sum > c.Bound {
+				return fmt.Errorf("server returned assignments violating sum constraint: %f > %f", sum, c.Bound)
+			}
+			if !c.IsUpperBound && sum < c.Bound {
+				return fmt.Errorf("server returned assignments violating sum constraint: %f < %f", sum, c.Bound)
 			}
-		} else {
-			return fmt.Errorf("server returned unexpected assignment: %s", a.ParameterName)
 		}
 	}
-
 	return nil
 }
+
+// stringSliceEqual compares two categorical value lists for equality, ignoring order.
+func stringSliceEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	counts := make(map[string]int, len(a))
+	for _, v := range a {
+		counts[v]++
+	}
+	for _, v := range b {
+		counts[v]--
+	}
+	for _, c := range counts {
+		if c != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// formatBounds renders a parameter's numeric bounds, or "<none>" for a categorical parameter.
+func formatBounds(b *experimentsv1alpha1.Bounds) string {
+	if b == nil {
+		return "<none>"
+	}
+	return fmt.Sprintf("[%s,%s]", b.Min, b.Max)
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2020 GramLabs, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package check
+
+import (
+	"github.com/spf13/cobra"
+	redskyv1beta1 "github.com/thestormforge/optimize-controller/api/v1beta1"
+	"github.com/thestormforge/optimize-controller/redskyctl/internal/commander"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TrialOptions are the options for checking a trial manifest
+type TrialOptions struct {
+	// IOStreams are used to access the standard process streams
+	commander.IOStreams
+
+	Filename string
+	Output   string
+}
+
+// NewTrialCommand creates a new command for checking a trial manifest
+func NewTrialCommand(o *TrialOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "trial",
+		Short: "Check a trial",
+		Long:  "Check a trial manifest",
+
+		PreRun: commander.StreamsPreRun(&o.IOStreams),
+		RunE:   commander.WithoutArgsE(o.checkTrial),
+	}
+
+	cmd.Flags().StringVarP(&o.Filename, "filename", "f", "", "file that contains the trial to check")
+	cmd.Flags().StringVar(&o.Output, "output", "text", "output format: one of text|json|sarif")
+
+	_ = cmd.MarkFlagFilename("filename", "yml", "yaml")
+	_ = cmd.MarkFlagRequired("filename")
+
+	commander.ExitOnError(cmd)
+	return cmd
+}
+
+func (o *TrialOptions) checkTrial() error {
+	r, err := o.IOStreams.OpenFile(o.Filename)
+	if err != nil {
+		return err
+	}
+
+	// Unmarshal the trial
+	trial := &redskyv1beta1.Trial{}
+	rr := commander.NewResourceReader()
+	if err := rr.ReadInto(r, trial); err != nil {
+		return err
+	}
+
+	// Check that everything looks right
+	linter := &AllTheLint{}
+	checkTrialResource(linter.For("trial"), trial)
+
+	// Share the results
+	if err := WriteReport(o.Out, ReportFormat(o.Output), o.Filename, linter.Problems); err != nil {
+		return err
+	}
+
+	return errorOnLintFailures(linter.Problems)
+}
+
+func checkTrialResource(lint Linter, trial *redskyv1beta1.Trial) {
+	if !checkTrialTypeMeta(lint.For("metadata"), &trial.TypeMeta) {
+		return
+	}
+
+	checkTrial(lint.For("spec"), &trial.Spec)
+}
+
+func checkTrialTypeMeta(lint Linter, typeMeta *metav1.TypeMeta) bool {
+	ok := true
+
+	if typeMeta.Kind != "Trial" {
+		lint.For("metadata").Error().Invalid("kind", typeMeta.Kind, "Trial")
+		ok = false
+	}
+
+	if typeMeta.APIVersion != redskyv1beta1.GroupVersion.String() {
+		lint.For("metadata").Error().Invalid("apiVersion", typeMeta.APIVersion, redskyv1beta1.GroupVersion.String())
+		ok = false
+	}
+
+	return ok
+}
@@ -17,6 +17,9 @@ limitations under the License.
 package pager
 
 import (
+	"fmt"
+	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/viewport"
@@ -25,19 +28,66 @@ import (
 
 type ExitMsg struct{}
 
+// KeyMap configures the key bindings used to drive the in-pager search.
+type KeyMap struct {
+	// Search starts an incremental forward search (default "/").
+	Search string
+	// SearchBack starts an incremental reverse search (default "?").
+	SearchBack string
+	// Next jumps to the next match (default "n").
+	Next string
+	// Prev jumps to the previous match (default "N").
+	Prev string
+}
+
+// DefaultKeyMap returns the `less(1)`-style key bindings used by the pager.
+func DefaultKeyMap() KeyMap {
+	return KeyMap{
+		Search:     "/",
+		SearchBack: "?",
+		Next:       "n",
+		Prev:       "N",
+	}
+}
+
+// match is the position of a single search match within the content.
+type match struct {
+	line     int
+	startCol int
+	endCol   int
+}
+
 type Model struct {
 	viewport.Model
 	// Return the instructions footer for a given width.
 	Instructions func(width int) string
-
-	// TODO `Silent bool` and add bell when we hit the top or bottom
+	// KeyMap configures the search key bindings.
+	KeyMap KeyMap
+	// Silent suppresses the terminal bell on no-match or top/bottom edges.
+	Silent bool
 
 	focus        bool
 	instructions string
+
+	content string
+
+	// searching is non-zero ('/' or '?') while a search pattern is being entered.
+	searching rune
+	input     string
+
+	pattern    *regexp.Regexp
+	matches    []match
+	matchIndex int
 }
 
 func NewModel() Model {
-	return Model{}
+	return Model{KeyMap: DefaultKeyMap()}
+}
+
+// SetContent stores the raw content and re-applies the current search highlighting.
+func (m *Model) SetContent(s string) {
+	m.content = s
+	m.refresh()
 }
 
 func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
@@ -62,9 +112,24 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 
 	case tea.KeyMsg:
 		if m.Focused() {
+			if m.searching != 0 {
+				cmds = append(cmds, m.updateSearchInput(msg))
+				return m, tea.Batch(cmds...)
+			}
+
 			switch msg.String() {
 			case "q", "Q", "ctrl+x":
 				cmds = append(cmds, func() tea.Msg { return ExitMsg{} })
+			case m.KeyMap.Search:
+				m.searching = '/'
+				m.input = ""
+			case m.KeyMap.SearchBack:
+				m.searching = '?'
+				m.input = ""
+			case m.KeyMap.Next:
+				m.nextMatch(1)
+			case m.KeyMap.Prev:
+				m.nextMatch(-1)
 			default:
 				m.Model, cmd = m.Model.Update(msg)
 				cmds = append(cmds, cmd)
@@ -76,6 +141,158 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 	return m, tea.Batch(cmds...)
 }
 
+// updateSearchInput handles key strokes while a search pattern is being entered.
+func (m *Model) updateSearchInput(msg tea.KeyMsg) tea.Cmd {
+	switch msg.Type {
+	case tea.KeyEnter:
+		dir := m.searching
+		m.searching = 0
+		return m.search(m.input, dir)
+	case tea.KeyEsc, tea.KeyCtrlC:
+		m.searching = 0
+		m.input = ""
+	case tea.KeyBackspace:
+		if m.input != "" {
+			m.input = m.input[:len(m.input)-1]
+		}
+	case tea.KeyRunes:
+		m.input += string(msg.Runes)
+	}
+	return nil
+}
+
+// search compiles the supplied pattern, recomputes the match positions, and jumps to the
+// first match in the requested direction.
+func (m *Model) search(pattern string, dir rune) tea.Cmd {
+	if pattern == "" {
+		return nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return m.bell()
+	}
+
+	m.pattern = re
+	m.matchIndex = -1
+	m.refresh()
+
+	if len(m.matches) == 0 {
+		m.pattern = nil
+		return m.bell()
+	}
+
+	if dir == '?' {
+		return m.nextMatch(-1)
+	}
+	return m.nextMatch(1)
+}
+
+// nextMatch advances the current match index by the supplied direction (1 or -1), wrapping
+// around the ends of the match list, and scrolls the active match into view.
+func (m *Model) nextMatch(dir int) tea.Cmd {
+	if len(m.matches) == 0 {
+		return m.bell()
+	}
+
+	m.matchIndex += dir
+	if m.matchIndex < 0 {
+		m.matchIndex = len(m.matches) - 1
+	} else if m.matchIndex >= len(m.matches) {
+		m.matchIndex = 0
+	}
+
+	mm := m.matches[m.matchIndex]
+	if y := mm.line - m.Model.Height/2; y > 0 {
+		m.Model.SetYOffset(y)
+	} else {
+		m.Model.SetYOffset(0)
+	}
+
+	m.Model.SetContent(m.highlight())
+
+	return nil
+}
+
+// refresh recomputes the match positions for the current pattern and re-renders the content.
+func (m *Model) refresh() {
+	m.matches = nil
+	if m.pattern != nil {
+		for i, line := range strings.Split(m.content, "\n") {
+			for _, loc := range m.pattern.FindAllStringIndex(line, -1) {
+				m.matches = append(m.matches, match{line: i, startCol: loc[0], endCol: loc[1]})
+			}
+		}
+		sort.Slice(m.matches, func(i, j int) bool {
+			if m.matches[i].line != m.matches[j].line {
+				return m.matches[i].line < m.matches[j].line
+			}
+			return m.matches[i].startCol < m.matches[j].startCol
+		})
+	}
+
+	m.Model.SetContent(m.highlight())
+}
+
+// ANSI reverse-video escapes used to mark search matches in the viewport; the active match is
+// additionally bolded so it stands out among the other matches on screen.
+const (
+	reverseVideo    = "\x1b[7m"
+	reverseVideoOff = "\x1b[27m"
+	boldOn          = "\x1b[1m"
+	boldOff         = "\x1b[22m"
+)
+
+// highlight renders the content with the current search matches highlighted.
+func (m *Model) highlight() string {
+	if len(m.matches) == 0 {
+		return m.content
+	}
+
+	lines := strings.Split(m.content, "\n")
+	for i, mm := range m.matches {
+		if mm.line < 0 || mm.line >= len(lines) {
+			continue
+		}
+
+		line := lines[mm.line]
+		if mm.endCol > len(line) {
+			continue
+		}
+
+		on, off := reverseVideo, reverseVideoOff
+		if i == m.matchIndex {
+			on, off = boldOn+reverseVideo, reverseVideoOff+boldOff
+		}
+
+		lines[mm.line] = line[:mm.startCol] + on + line[mm.startCol:mm.endCol] + off + line[mm.endCol:]
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// bell emits the terminal bell unless the pager is silent.
+func (m Model) bell() tea.Cmd {
+	if m.Silent {
+		return nil
+	}
+	return func() tea.Msg {
+		fmt.Print("\a")
+		return nil
+	}
+}
+
+// statusLine returns the `match i/N` status for the current search, if any.
+func (m Model) statusLine() string {
+	if m.searching != 0 {
+		return string(m.searching) + m.input
+	}
+	if m.pattern == nil || len(m.matches) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("match %d/%d", m.matchIndex+1, len(m.matches))
+}
+
 func (m Model) View() string {
 	// There is no show/hide for the pager because it is full screen: either we
 	// take over the whole screen and also accept key strokes, or we render
@@ -83,7 +300,12 @@ func (m Model) View() string {
 	if !m.Focused() {
 		return ""
 	}
-	return m.Model.View() + m.instructions
+
+	view := m.Model.View()
+	if status := m.statusLine(); status != "" {
+		view += "\n" + status
+	}
+	return view + m.instructions
 }
 
 func (m *Model) Focus() {